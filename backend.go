@@ -0,0 +1,69 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+import "time"
+
+// Cell is a single character cell of a terminal, as drawn by a Backend
+type Cell struct {
+	// Ch is the character shown in the cell. Characters from the alternate
+	// character set, such as ACS_HLINE, are given as their Unicode
+	// equivalents. A wide character, such as a CJK ideograph, takes two
+	// cells and the second has a Ch of 0
+	Ch rune
+	// Comb holds any combining characters, such as accents, drawn over Ch
+	Comb string
+	// Attr holds the attributes of the cell, such as A_BOLD, without a
+	// character or color pair
+	Attr Char
+	// Fg and Bg are the foreground and background colors of the cell; -1
+	// is the terminal's default color
+	Fg, Bg int16
+}
+
+// Backend is the terminal beneath the windows, pads and panels of a pure
+// Go build, one built with the purego tag. Init runs the package on a
+// TerminfoBackend for the program's own terminal and InitBackend on any
+// other.
+//
+// The package draws windows into a virtual screen of its own and, on each
+// Update, passes the cells which have changed to SetCell before calling
+// Flush. A backend is only used from the goroutine calling the package, as
+// with ncurses.
+type Backend interface {
+	// Init prepares the terminal for use
+	Init() error
+	// End returns the terminal to normal operation. Init is called again
+	// if the program carries on after End
+	End() error
+	// Size returns the number of rows and columns of the terminal
+	Size() (rows, cols int)
+	// Colors returns the number of colors the terminal can display, or
+	// zero if it can only display its default colors
+	Colors() int
+	// SetCell sets the cell at y, x to c
+	SetCell(y, x int, c Cell)
+	// SetCursor moves the cursor to y, x and sets its visibility, which
+	// is 0 (hidden), 1 (normal) or 2 (extra-visible)
+	SetCursor(y, x, visibility int)
+	// Clear clears the terminal, after which every cell is set again
+	Clear()
+	// Flush brings the terminal up to date with the cells set since the
+	// last Flush
+	Flush() error
+	// ReadKey waits up to timeout, or indefinitely if timeout is
+	// negative, for a key to be pressed. It returns false if none was.
+	// Function keys are returned as the matching Key, such as KEY_UP, and
+	// KEY_RESIZE is returned once the terminal has changed size
+	ReadKey(timeout time.Duration) (Key, bool)
+	// SetRaw turns raw input on or off. In raw mode keys such as Ctrl-C
+	// are passed to the program instead of generating signals
+	SetRaw(on bool)
+	// Beep sounds the terminal's bell and Flash flashes its screen
+	Beep()
+	Flash()
+}
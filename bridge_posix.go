@@ -0,0 +1,182 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+// #include <curses.h>
+// #include "goncurses.h"
+import "C"
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/rthornton128/goncurses/internal/pty"
+)
+
+// ptyBridge connects a pseudo-terminal to a reader and writer. ncurses runs
+// on the slave end, which behaves as a real terminal would, while whatever
+// is written to the slave is copied to the writer and whatever is read
+// from the reader is passed to the slave as input
+type ptyBridge struct {
+	master, slave *os.File
+	done          chan struct{}
+	// inDone is closed once input is no longer being copied
+	inDone chan struct{}
+	// stopInput, if not nil, interrupts the copying of input, returning a
+	// function to call once it has stopped
+	stopInput func() func()
+}
+
+// deadliner is implemented by readers, such as network connections, whose
+// reads may be interrupted by setting a deadline
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// newPtyBridge opens a pseudo-terminal of the given size and starts
+// copying to out and from in. If not nil, onOutput and onInput are passed
+// each chunk of data copied. Copying from in stops when the bridge is
+// closed if in is a file or has a read deadline; other readers are read
+// until they return an error
+func newPtyBridge(out io.Writer, in io.Reader, rows, cols int,
+	onOutput, onInput func([]byte)) (*ptyBridge, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	if err := pty.SetSize(master, rows, cols); err != nil {
+		master.Close()
+		slave.Close()
+		return nil, err
+	}
+	b := &ptyBridge{master: master, slave: slave, done: make(chan struct{}),
+		inDone: make(chan struct{})}
+
+	switch r := in.(type) {
+	case *os.File:
+		pr, err := pty.NewReader(r)
+		if err != nil {
+			master.Close()
+			slave.Close()
+			return nil, err
+		}
+		in = pr
+		b.stopInput = func() func() {
+			pr.Close()
+			return func() {}
+		}
+	case deadliner:
+		b.stopInput = func() func() {
+			if r.SetReadDeadline(time.Now()) != nil {
+				return nil
+			}
+			// the reader is left as it was found
+			return func() { r.SetReadDeadline(time.Time{}) }
+		}
+	}
+
+	// Output is copied until the slave is closed, after ncurses has been
+	// ended, so the last of it is not lost. Input is copied until the
+	// master is closed or in fails
+	go func() {
+		defer close(b.done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := master.Read(buf)
+			if n > 0 {
+				out.Write(buf[:n])
+				if onOutput != nil {
+					onOutput(buf[:n])
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(b.inDone)
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				if onInput != nil {
+					onInput(buf[:n])
+				}
+				if _, err := master.Write(buf[:n]); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return b, nil
+}
+
+// resize changes the size of the pseudo-terminal
+func (b *ptyBridge) resize(rows, cols int) error {
+	return pty.SetSize(b.master, rows, cols)
+}
+
+// close stops copying input and closes the pseudo-terminal once all
+// output has been copied
+func (b *ptyBridge) close() {
+	var stopped func()
+	if b.stopInput != nil {
+		stopped = b.stopInput()
+	}
+	b.slave.Close()
+	<-b.done
+	b.master.Close()
+	if stopped != nil {
+		<-b.inDone
+		stopped()
+	}
+}
+
+// NewTermIO is like NewTerm but runs the screen over any reader and
+// writer, such as a network connection or an SSH channel, rather than a
+// terminal. The screen is given a pseudo-terminal of rows by cols and
+// everything ncurses writes to it is copied to out while everything read
+// from in is passed to it as input, so modes such as Raw and Echo apply as
+// they would on a real terminal. termType names the terminal at the other
+// end of out and in; it should not be left empty unless that terminal is
+// the same type as the one the program is running on.
+//
+// Use Screen.Resize when the terminal at the other end changes size. When
+// the screen is deleted the last of the output is copied to out and, if in
+// is an *os.File or has a SetReadDeadline method, as network connections
+// do, reading from in stops without losing any input. Other readers are
+// read until they return an error, so anything read from them after the
+// screen is deleted is lost. Neither out nor in is closed.
+//
+// As with NewTerm, the memory of the screen is not freed until every
+// screen made by NewTerm or NewTermIO has been deleted.
+func NewTermIO(termType string, out io.Writer, in io.Reader, rows,
+	cols int) (*Screen, error) {
+	b, err := newPtyBridge(out, in, rows, cols, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	screen, err := NewTerm(termType, b.slave, b.slave)
+	if err != nil {
+		b.close()
+		return nil, err
+	}
+	screen.release = b.close
+	screen.setSize = b.resize
+
+	// ncurses prefers the LINES and COLUMNS environment variables, which
+	// describe the program's own terminal, to the size of the pty
+	if r, c := screen.Size(); r != rows || c != cols {
+		C.ncurses_resize_term_sp(screen.scrPtr, C.int(rows), C.int(cols))
+	}
+	return screen, nil
+}
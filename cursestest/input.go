@@ -0,0 +1,98 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package cursestest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/internal/pty"
+)
+
+// TypeString sends s to the terminal as if it had been typed. The text is
+// sent as UTF-8 and control characters, including escape sequences, are
+// passed on unchanged, so s may also be used to send raw input. It is read
+// the next time the program asks for input
+func (t *Terminal) TypeString(s string) error {
+	_, err := t.master.Write([]byte(s))
+	return err
+}
+
+// PressKey sends what the terminal sends when key k is pressed. k is
+// either a character, such as 'a' or KEY_ESC, or one of the function keys
+// an xterm has, such as KEY_DOWN, KEY_F1 or KEY_PAGEUP. The sequence
+// depends on the keypad mode the program has set, just as it would on a
+// real terminal, so keys meant for a window with its keypad turned on
+// should be pressed after the window has been refreshed and Sync called
+func (t *Terminal) PressKey(k goncurses.Key) error {
+	seq, err := keySequence(t.vt, k)
+	if err != nil {
+		return err
+	}
+	return t.TypeString(seq)
+}
+
+// Click clicks mouse button 1 at row y, column x of the screen. The
+// program must have asked for mouse events with MouseMask
+func (t *Terminal) Click(y, x int) error {
+	if err := t.mouseButton(y, x, 0, true); err != nil {
+		return err
+	}
+	return t.mouseButton(y, x, 0, false)
+}
+
+// DoubleClick clicks mouse button 1 twice in quick succession at row y,
+// column x of the screen
+func (t *Terminal) DoubleClick(y, x int) error {
+	if err := t.Click(y, x); err != nil {
+		return err
+	}
+	return t.Click(y, x)
+}
+
+// mouseButton sends a report of button being pressed or released in the
+// form the terminal was asked to use
+func (t *Terminal) mouseButton(y, x, button int, press bool) error {
+	rows, cols := t.vt.Size()
+	if y < 0 || y >= rows || x < 0 || x >= cols {
+		return errors.New("cursestest: mouse position is off the screen")
+	}
+	tracking, sgr := t.vt.mouseModes()
+	if !tracking {
+		return errors.New("cursestest: terminal is not reporting mouse events")
+	}
+	if sgr {
+		final := 'M'
+		if !press {
+			final = 'm'
+		}
+		return t.TypeString(fmt.Sprintf("\x1b[<%d;%d;%d%c", button, x+1,
+			y+1, final))
+	}
+	if y > 222 || x > 222 {
+		return errors.New("cursestest: mouse position too large to report")
+	}
+	if !press {
+		button = 3
+	}
+	return t.TypeString(string([]byte{'\x1b', '[', 'M', byte(32 + button),
+		byte(33 + x), byte(33 + y)}))
+}
+
+// Resize changes the size of the terminal, as when the window of a
+// terminal emulator is resized, and resizes the terminal's screen to fit
+// with Screen.Resize. The current screen is left as it was. As on a real
+// terminal, the program reads KEY_RESIZE next and the contents of the
+// screen are only redrawn to fit when it next refreshes
+func (t *Terminal) Resize(rows, cols int) error {
+	if err := pty.SetSize(t.master, rows, cols); err != nil {
+		return err
+	}
+	t.vt.Resize(rows, cols)
+	return t.screen.Resize(rows, cols)
+}
@@ -0,0 +1,176 @@
+// +build !windows,!purego
+
+package cursestest_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+// keypad turns on the keypad of the standard screen so that keys are sent
+// as they are once a window with its keypad on has been refreshed
+func keypad(t *testing.T, term *cursestest.Terminal) {
+	stdscr := term.StdScr()
+	stdscr.Keypad(true)
+	stdscr.Timeout(1000)
+	stdscr.Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPressKey(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+	keypad(t, term)
+	stdscr := term.StdScr()
+
+	keys := []goncurses.Key{goncurses.KEY_DOWN, goncurses.KEY_HOME,
+		goncurses.KEY_F5, goncurses.KEY_ENTER, goncurses.KEY_BTAB, 'x',
+		goncurses.KEY_BACKSPACE}
+	for _, k := range keys {
+		if err := term.PressKey(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := term.TypeString("ok"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range append(keys, 'o', 'k') {
+		if got := stdscr.GetChar(); got != want {
+			t.Errorf("expected %s, got %s", goncurses.KeyString(want),
+				goncurses.KeyString(got))
+		}
+	}
+	if err := term.PressKey(goncurses.KEY_MOUSE); err == nil {
+		t.Error("expected an error pressing KEY_MOUSE")
+	}
+}
+
+func TestClick(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+	stdscr := term.StdScr()
+	stdscr.Keypad(true)
+	stdscr.Timeout(1000)
+
+	items := newItems(t, "one", "two", "three")
+	defer func() {
+		for _, item := range items {
+			item.Free()
+		}
+	}()
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+	stdscr.Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := term.Click(2, 1); err == nil {
+		t.Error("expected an error clicking without mouse reporting")
+	}
+	goncurses.MouseMask(goncurses.M_ALL, nil)
+	stdscr.Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := term.Click(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if k := stdscr.GetChar(); k != goncurses.KEY_MOUSE {
+		t.Fatalf("expected a mouse event, got %s", goncurses.KeyString(k))
+	}
+	if err := menu.HandleKey(goncurses.KEY_MOUSE); err != nil {
+		t.Fatal(err)
+	}
+	if cur := menu.Current(nil); cur != items[2] {
+		t.Errorf("expected %q to be current, got %q", "three", cur.Name())
+	}
+}
+
+func TestChooseScripted(t *testing.T) {
+	term := newTerminal(t, 12, 40)
+	defer term.Close()
+	keypad(t, term)
+
+	for _, k := range []goncurses.Key{goncurses.KEY_DOWN,
+		goncurses.KEY_DOWN, goncurses.KEY_UP, goncurses.KEY_RETURN} {
+		if err := term.PressKey(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := goncurses.Choose("Fruit", []string{"apple", "banana", "cherry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected option 1 to be chosen, got %d", n)
+	}
+}
+
+func TestPromptScripted(t *testing.T) {
+	term := newTerminal(t, 12, 40)
+	defer term.Close()
+	keypad(t, term)
+
+	term.TypeString("goncurses")
+	term.PressKey(goncurses.KEY_BACKSPACE)
+	term.PressKey(goncurses.KEY_RETURN)
+	s, err := goncurses.Prompt("Name", "Who are you?", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "goncurse" {
+		t.Errorf("expected %q, got %q", "goncurse", s)
+	}
+}
+
+func TestResize(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+	stdscr := term.StdScr()
+	stdscr.Keypad(true)
+	stdscr.Timeout(1000)
+
+	if err := term.Resize(6, 20); err != nil {
+		t.Fatal(err)
+	}
+	if k := stdscr.GetChar(); k != goncurses.KEY_RESIZE {
+		t.Fatalf("expected KEY_RESIZE, got %s", goncurses.KeyString(k))
+	}
+	if rows, cols := stdscr.MaxYX(); rows != 6 || cols != 20 {
+		t.Fatalf("expected a 6x20 screen, got %dx%d", rows, cols)
+	}
+	stdscr.Box(0, 0)
+	stdscr.Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "└──────────────────┘"; term.Line(5) != want {
+		t.Errorf("expected %q, got %q", want, term.Line(5))
+	}
+
+	// resizing a terminal does not make its screen current
+	other := newTerminal(t, 4, 10)
+	defer other.Close()
+	if err := term.Resize(8, 30); err != nil {
+		t.Fatal(err)
+	}
+	if rows, cols := goncurses.StdScr().MaxYX(); rows != 4 || cols != 10 {
+		t.Errorf("expected the other terminal's 4x10 screen to stay "+
+			"current, got %dx%d", rows, cols)
+	}
+	if rows, cols := stdscr.MaxYX(); rows != 8 || cols != 30 {
+		t.Errorf("expected an 8x30 screen, got %dx%d", rows, cols)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cursestest
+
+import (
+	"fmt"
+
+	"github.com/rthornton128/goncurses"
+)
+
+// keySequences holds what an xterm sends for each function key. Cursor
+// keys, Home and End send ESC O rather than ESC [ sequences in application
+// cursor key mode, which ncurses turns on along with the keypad
+var keySequences = map[goncurses.Key]string{
+	goncurses.KEY_UP:        "\x1b[A",
+	goncurses.KEY_DOWN:      "\x1b[B",
+	goncurses.KEY_RIGHT:     "\x1b[C",
+	goncurses.KEY_LEFT:      "\x1b[D",
+	goncurses.KEY_HOME:      "\x1b[H",
+	goncurses.KEY_END:       "\x1b[F",
+	goncurses.KEY_IC:        "\x1b[2~",
+	goncurses.KEY_DC:        "\x1b[3~",
+	goncurses.KEY_SDC:       "\x1b[3;2~",
+	goncurses.KEY_PAGEUP:    "\x1b[5~",
+	goncurses.KEY_PAGEDOWN:  "\x1b[6~",
+	goncurses.KEY_BTAB:      "\x1b[Z",
+	goncurses.KEY_BACKSPACE: "\x7f",
+	goncurses.KEY_RETURN:    "\r",
+	goncurses.KEY_F1:        "\x1bOP",
+	goncurses.KEY_F2:        "\x1bOQ",
+	goncurses.KEY_F3:        "\x1bOR",
+	goncurses.KEY_F4:        "\x1bOS",
+	goncurses.KEY_F5:        "\x1b[15~",
+	goncurses.KEY_F6:        "\x1b[17~",
+	goncurses.KEY_F7:        "\x1b[18~",
+	goncurses.KEY_F8:        "\x1b[19~",
+	goncurses.KEY_F9:        "\x1b[20~",
+	goncurses.KEY_F10:       "\x1b[21~",
+	goncurses.KEY_F11:       "\x1b[23~",
+	goncurses.KEY_F12:       "\x1b[24~",
+}
+
+// keySequence returns what an xterm in the modes of vt sends for key k
+func keySequence(vt *VT, k goncurses.Key) (string, error) {
+	seq, ok := keySequences[k]
+	switch {
+	case k == goncurses.KEY_ENTER:
+		// the keypad Enter key is only distinct in keypad mode
+		seq = "\r"
+		if vt.keypadMode() {
+			seq = "\x1bOM"
+		}
+	case ok && len(seq) == 3 && vt.AppCursorKeys():
+		switch seq[2] {
+		case 'A', 'B', 'C', 'D', 'H', 'F':
+			seq = "\x1bO" + seq[2:]
+		}
+	case !ok && k >= 0 && k < 0x80:
+		seq = string(rune(k))
+	case !ok:
+		return "", fmt.Errorf("cursestest: no key sequence for key %d", k)
+	}
+	return seq, nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package cursestest
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/rthornton128/goncurses"
+)
+
+// maxQueued is the number of keys Memory queues at most. ncurses holds
+// queued input in a small ring which misbehaves once it is full
+const maxQueued = 128
+
+// Memory is an ncurses screen which draws into a VT held in memory rather
+// than on a terminal. It lets code built on windows and panels, as well as
+// forms, menus and dialogs, be tested without a terminal or a child
+// process:
+//
+//	mem := cursestest.NewMemory(24, 80)
+//	stdscr, err := mem.Start()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer mem.Close()
+//
+// What has been drawn is inspected with Cell, Line, Cursor, String and
+// Snapshot once the program has refreshed. Input is queued by
+// TypeString and PressKey and read in order; reading from an empty queue
+// returns no key at once, whatever the window's delay, so a test never
+// waits for input. Keys are delivered as they would be in cbreak mode,
+// whether or not CBreak or Raw has been called, since there is no terminal
+// to set up.
+//
+// The screen is made by NewTerm and written to a temporary file, which
+// the VT reads back whenever it is inspected. The methods of Memory act on
+// the current screen, so it must be left current while it is in use
+type Memory struct {
+	vt     *VT
+	colors int
+	keys   map[goncurses.Key]bool
+	screen *goncurses.Screen
+	pad    *goncurses.Pad
+	in     *os.File
+	out    *os.File
+	read   int64
+}
+
+// NewMemory returns a screen of rows by cols which can display 256 colors
+// and has every function key
+func NewMemory(rows, cols int) *Memory {
+	return &Memory{vt: NewVT(rows, cols), colors: 256}
+}
+
+// SetColors sets the number of colors the screen can display. Zero leaves
+// it with only the default colors, as a monochrome terminal, and makes
+// StartColor fail. It must be called before Start
+func (m *Memory) SetColors(n int) {
+	m.colors = n
+}
+
+// SetKeys limits the function keys the screen has to keys, so HasKey
+// reports that it lacks any others. Pressing a key the screen lacks sends
+// its escape sequence a character at a time, as a real terminal would. It
+// must be called before Start
+func (m *Memory) SetKeys(keys ...goncurses.Key) {
+	m.keys = make(map[goncurses.Key]bool)
+	for _, k := range keys {
+		m.keys[k] = true
+	}
+}
+
+// term returns the terminal type with as many colors as have been set
+func (m *Memory) term() string {
+	switch {
+	case m.colors <= 0:
+		return "xterm-mono"
+	case m.colors <= 8:
+		return "xterm"
+	case m.colors <= 16:
+		return "xterm-16color"
+	case m.colors <= 88:
+		return "xterm-88color"
+	}
+	return "xterm-256color"
+}
+
+// Start makes a new screen with NewTerm, makes it current and returns its
+// standard screen window. Init must not be called
+func (m *Memory) Start() (*goncurses.Window, error) {
+	var err error
+	if m.in, err = tempFile(); err != nil {
+		return nil, err
+	}
+	if m.out, err = tempFile(); err != nil {
+		m.in.Close()
+		return nil, err
+	}
+	m.screen, err = goncurses.NewTerm(m.term(), m.out, m.in)
+	if err != nil {
+		m.in.Close()
+		m.out.Close()
+		return nil, err
+	}
+	if _, err := m.screen.Set(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	// ncurses takes the size of the screen from LINES and COLUMNS, when
+	// set, or from the terminal's description, so it is resized to fit.
+	// The KEY_RESIZE queued by doing so is dropped
+	rows, cols := m.vt.Size()
+	if r, c := m.screen.Size(); r != rows || c != cols {
+		if err := m.screen.Resize(rows, cols); err != nil {
+			m.Close()
+			return nil, err
+		}
+		goncurses.FlushInput()
+	}
+	if m.pad, err = goncurses.NewPad(1, 1); err != nil {
+		m.Close()
+		return nil, err
+	}
+	m.pad.Timeout(0)
+	if m.keys != nil {
+		// ncurses only learns the terminal's keys once the keypad is
+		// first turned on
+		m.pad.Keypad(true)
+		m.pad.Keypad(false)
+		first := goncurses.Key(goncurses.KEY_DOWN)
+		for k := first; k < goncurses.KEY_MAX; k++ {
+			if !m.keys[k] && goncurses.HasKey(k) {
+				goncurses.KeyOk(k, false)
+			}
+		}
+	}
+	return goncurses.StdScr(), nil
+}
+
+// tempFile opens a new temporary file which is removed once closed
+func tempFile() (*os.File, error) {
+	f, err := ioutil.TempFile("", "cursestest")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	return f, nil
+}
+
+// Close ends and frees the screen. What was drawn on it may still be
+// inspected
+func (m *Memory) Close() error {
+	m.update()
+	if m.pad != nil {
+		m.pad.Delete()
+		m.pad = nil
+	}
+	err := m.screen.Close()
+	m.in.Close()
+	m.out.Close()
+	return err
+}
+
+// update passes what ncurses has written since it was last called to the
+// VT
+func (m *Memory) update() {
+	if m.out == nil {
+		return
+	}
+	buf := make([]byte, 4096)
+	for {
+		n, err := m.out.ReadAt(buf, m.read)
+		m.read += int64(n)
+		m.vt.Write(buf[:n])
+		if err == io.EOF || n == 0 {
+			return
+		}
+	}
+}
+
+// pending removes the keys queued but not yet read and returns them
+func (m *Memory) pending() []goncurses.Key {
+	var keys []goncurses.Key
+	for k := m.pad.GetChar(); k != 0; k = m.pad.GetChar() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// queue adds keys to the end of the input queue. ncurses only lets keys
+// be pushed back onto the front of its queue, so those waiting are taken
+// off and pushed back after the new ones
+func (m *Memory) queue(keys ...goncurses.Key) error {
+	var err error
+	all := append(m.pending(), keys...)
+	if len(all) > maxQueued {
+		all = all[:len(all)-len(keys)]
+		err = errors.New("cursestest: too many keys queued")
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		goncurses.UnGetChar(goncurses.Char(all[i]))
+	}
+	return err
+}
+
+// TypeString queues s as if it had been typed, a byte at a time. At most
+// 128 keys may be waiting to be read
+func (m *Memory) TypeString(s string) error {
+	keys := make([]goncurses.Key, len(s))
+	for i := 0; i < len(s); i++ {
+		keys[i] = goncurses.Key(s[i])
+	}
+	return m.queue(keys...)
+}
+
+// PressKey queues key k, which is either a character or a function key
+// such as KEY_DOWN. A function key the screen lacks is queued as the
+// characters an xterm sends for it
+func (m *Memory) PressKey(k goncurses.Key) error {
+	if k < goncurses.KEY_DOWN || m.keys == nil || m.keys[k] {
+		return m.queue(k)
+	}
+	m.update()
+	seq, err := keySequence(m.vt, k)
+	if err != nil {
+		return err
+	}
+	return m.TypeString(seq)
+}
+
+// Resize changes the size of the screen and queues KEY_RESIZE
+func (m *Memory) Resize(rows, cols int) error {
+	keys := m.pending()
+	if err := m.screen.Resize(rows, cols); err != nil {
+		m.queue(keys...)
+		return err
+	}
+	goncurses.FlushInput()
+	m.vt.Resize(rows, cols)
+	return m.queue(append(keys, goncurses.KEY_RESIZE)...)
+}
+
+// Screen returns the ncurses screen drawn in memory
+func (m *Memory) Screen() *goncurses.Screen {
+	return m.screen
+}
+
+// Beeps returns the number of times the bell has been sounded
+func (m *Memory) Beeps() int {
+	m.update()
+	m.vt.mu.Lock()
+	defer m.vt.mu.Unlock()
+	return m.vt.bells
+}
+
+// Flashes returns the number of times the screen has been flashed
+func (m *Memory) Flashes() int {
+	m.update()
+	m.vt.mu.Lock()
+	defer m.vt.mu.Unlock()
+	return m.vt.flashes
+}
+
+// Ended returns true if the screen has been ended and not refreshed since
+func (m *Memory) Ended() bool {
+	return m.screen.IsEnd()
+}
+
+// Cell returns the cell at row y, column x of the screen
+func (m *Memory) Cell(y, x int) Cell {
+	m.update()
+	return m.vt.Cell(y, x)
+}
+
+// Cursor returns the position of the cursor and whether it is visible
+func (m *Memory) Cursor() (y, x int, visible bool) {
+	m.update()
+	return m.vt.Cursor()
+}
+
+// Line returns the text of row y of the screen with trailing blanks
+// removed
+func (m *Memory) Line(y int) string {
+	m.update()
+	return m.vt.Line(y)
+}
+
+// String returns the text of the whole screen, one line per row
+func (m *Memory) String() string {
+	m.update()
+	return m.vt.String()
+}
+
+// Snapshot returns a snapshot of the screen
+func (m *Memory) Snapshot(flags SnapshotFlags) string {
+	m.update()
+	return m.vt.Snapshot(flags)
+}
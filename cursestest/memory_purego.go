@@ -0,0 +1,280 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package cursestest
+
+import (
+	"time"
+
+	"github.com/rthornton128/goncurses"
+)
+
+// attrs maps the attributes of a goncurses cell to those of a VT cell.
+// Standout is shown as reverse video, as it is by an xterm
+var attrs = []struct {
+	from goncurses.Char
+	to   Attr
+}{
+	{goncurses.A_BOLD, AttrBold},
+	{goncurses.A_DIM, AttrDim},
+	{goncurses.A_UNDERLINE, AttrUnderline},
+	{goncurses.A_BLINK, AttrBlink},
+	{goncurses.A_REVERSE, AttrReverse},
+	{goncurses.A_STANDOUT, AttrReverse},
+	{goncurses.A_INVIS, AttrInvisible},
+}
+
+type memoryCell struct {
+	y, x int
+	c    Cell
+}
+
+// Memory is a goncurses.Backend which draws into a VT held in memory
+// rather than on a terminal. It lets code built on windows and panels be
+// tested without a terminal or a child process:
+//
+//	mem := cursestest.NewMemory(24, 80)
+//	stdscr, err := mem.Start()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer mem.Close()
+//
+// What has been drawn is inspected with Cell, Line, Cursor, String and
+// Snapshot once the program has refreshed. Input is queued by
+// TypeString and PressKey and read in order; reading from an empty queue
+// returns no key at once, whatever the window's delay, so a test never
+// waits for input. Start is the same as passing the backend to
+// InitBackend.
+type Memory struct {
+	vt      *VT
+	colors  int
+	has     map[goncurses.Key]bool
+	keys    []goncurses.Key
+	pending []memoryCell
+	clear   bool
+	cursorY int
+	cursorX int
+	visible bool
+	raw     bool
+	ended   bool
+	beeps   int
+	flashes int
+}
+
+// NewMemory returns a backend of rows by cols which can display 256
+// colors
+func NewMemory(rows, cols int) *Memory {
+	return &Memory{vt: NewVT(rows, cols), colors: 256, visible: true}
+}
+
+// SetColors sets the number of colors the backend can display. Zero leaves
+// it with only the default colors, as a monochrome terminal, and makes
+// StartColor fail. It must be called before InitBackend
+func (m *Memory) SetColors(n int) {
+	m.colors = n
+}
+
+// SetKeys limits the function keys the backend has to keys, so HasKey
+// reports that it lacks any others. Pressing a key the backend lacks sends
+// its escape sequence a character at a time, as a real terminal would. It
+// must be called before Start
+func (m *Memory) SetKeys(keys ...goncurses.Key) {
+	m.has = make(map[goncurses.Key]bool)
+	for _, k := range keys {
+		m.has[k] = true
+	}
+}
+
+// Start passes the backend to InitBackend and returns stdscr
+func (m *Memory) Start() (*goncurses.Window, error) {
+	return goncurses.InitBackend(m)
+}
+
+// Close ends the package's screen. What was drawn on it may still be
+// inspected
+func (m *Memory) Close() error {
+	goncurses.End()
+	return nil
+}
+
+// TypeString queues s as if it had been typed, a byte at a time. It never
+// fails, returning an error only to match the ncurses build
+func (m *Memory) TypeString(s string) error {
+	for i := 0; i < len(s); i++ {
+		m.keys = append(m.keys, goncurses.Key(s[i]))
+	}
+	return nil
+}
+
+// PressKey queues key k, which is either a character or a function key
+// such as KEY_DOWN. A function key the backend lacks is queued as the
+// characters an xterm sends for it
+func (m *Memory) PressKey(k goncurses.Key) error {
+	if k < goncurses.KEY_DOWN || m.HasKey(k) {
+		m.keys = append(m.keys, k)
+		return nil
+	}
+	seq, err := keySequence(m.vt, k)
+	if err != nil {
+		return err
+	}
+	return m.TypeString(seq)
+}
+
+// Resize changes the size of the screen and queues KEY_RESIZE, which
+// resizes the package's screen and stdscr when it is read
+func (m *Memory) Resize(rows, cols int) error {
+	m.vt.Resize(rows, cols)
+	m.keys = append(m.keys, goncurses.KEY_RESIZE)
+	return nil
+}
+
+// HasKey returns true if the backend has function key k
+func (m *Memory) HasKey(k goncurses.Key) bool {
+	if m.has == nil {
+		return k >= goncurses.KEY_DOWN && k < goncurses.KEY_MAX
+	}
+	return m.has[k]
+}
+
+// Beeps returns the number of times the bell has been sounded
+func (m *Memory) Beeps() int {
+	return m.beeps
+}
+
+// Flashes returns the number of times the screen has been flashed
+func (m *Memory) Flashes() int {
+	return m.flashes
+}
+
+// Ended returns true if the backend has been ended and not started again
+func (m *Memory) Ended() bool {
+	return m.ended
+}
+
+// Raw returns true if raw input is on
+func (m *Memory) Raw() bool {
+	return m.raw
+}
+
+// Init prepares the backend for use
+func (m *Memory) Init() error {
+	m.ended = false
+	return nil
+}
+
+// End marks the backend as ended. The screen is left as it was drawn
+func (m *Memory) End() error {
+	m.ended = true
+	return nil
+}
+
+// Colors returns the number of colors set by SetColors
+func (m *Memory) Colors() int {
+	return m.colors
+}
+
+// SetCell sets the cell at y, x to c when the backend is next flushed
+func (m *Memory) SetCell(y, x int, c goncurses.Cell) {
+	cell := Cell{Ch: c.Ch, Comb: c.Comb, Fg: Color(c.Fg), Bg: Color(c.Bg)}
+	for _, a := range attrs {
+		if c.Attr&a.from != 0 {
+			cell.Attr |= a.to
+		}
+	}
+	m.pending = append(m.pending, memoryCell{y, x, cell})
+}
+
+// SetCursor moves the cursor to y, x and shows it unless visibility is 0
+func (m *Memory) SetCursor(y, x, visibility int) {
+	m.cursorY, m.cursorX, m.visible = y, x, visibility != 0
+}
+
+// Clear clears the screen when the backend is next flushed
+func (m *Memory) Clear() {
+	m.clear = true
+	m.pending = nil
+}
+
+// Flush applies the cells and cursor set since the last Flush to the VT
+func (m *Memory) Flush() error {
+	vt := m.vt
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	if m.clear {
+		vt.grid = newGrid(vt.rows, vt.cols)
+		vt.main = vt.grid
+		m.clear = false
+	}
+	for _, p := range m.pending {
+		if p.y >= 0 && p.y < vt.rows && p.x >= 0 && p.x < vt.cols {
+			vt.grid[p.y][p.x] = p.c
+		}
+	}
+	m.pending = m.pending[:0]
+	vt.y = clamp(m.cursorY, 0, vt.rows-1)
+	vt.x = clamp(m.cursorX, 0, vt.cols-1)
+	vt.visible = m.visible
+	return nil
+}
+
+// ReadKey returns the next key queued, or false if there are none. It
+// never waits
+func (m *Memory) ReadKey(timeout time.Duration) (goncurses.Key, bool) {
+	if len(m.keys) == 0 {
+		return 0, false
+	}
+	k := m.keys[0]
+	m.keys = m.keys[1:]
+	return k, true
+}
+
+// SetRaw turns raw input on or off
+func (m *Memory) SetRaw(on bool) {
+	m.raw = on
+}
+
+// Beep sounds the bell
+func (m *Memory) Beep() {
+	m.beeps++
+}
+
+// Flash flashes the screen
+func (m *Memory) Flash() {
+	m.flashes++
+}
+
+// Size returns the number of rows and columns of the screen
+func (m *Memory) Size() (rows, cols int) {
+	return m.vt.Size()
+}
+
+// Cell returns the cell at row y, column x of the screen
+func (m *Memory) Cell(y, x int) Cell {
+	return m.vt.Cell(y, x)
+}
+
+// Cursor returns the position of the cursor and whether it is visible
+func (m *Memory) Cursor() (y, x int, visible bool) {
+	return m.vt.Cursor()
+}
+
+// Line returns the text of row y of the screen with trailing blanks
+// removed
+func (m *Memory) Line(y int) string {
+	return m.vt.Line(y)
+}
+
+// String returns the text of the whole screen, one line per row
+func (m *Memory) String() string {
+	return m.vt.String()
+}
+
+// Snapshot returns a snapshot of the screen
+func (m *Memory) Snapshot(flags SnapshotFlags) string {
+	return m.vt.Snapshot(flags)
+}
@@ -0,0 +1,141 @@
+// +build !windows purego
+
+package cursestest_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+func TestMemoryDraw(t *testing.T) {
+	mem := cursestest.NewMemory(6, 20)
+	stdscr, err := mem.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	goncurses.InitPair(1, goncurses.C_YELLOW, goncurses.C_BLACK)
+	stdscr.MovePrint(0, 0, "title")
+	stdscr.Refresh()
+
+	win, err := goncurses.NewWindow(3, 10, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	win.Box(0, 0)
+	win.ColorOn(1)
+	win.AttrOn(goncurses.A_REVERSE)
+	win.MovePrint(1, 1, "ok")
+	panel := goncurses.NewPanel(win)
+	goncurses.UpdatePanels()
+	goncurses.Update()
+
+	want := "title\n\n    ┌────────┐\n    │ok      │\n    └────────┘\n"
+	if got := mem.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	c := mem.Cell(3, 5)
+	if c.Fg != cursestest.Color(goncurses.C_YELLOW) ||
+		c.Attr != cursestest.AttrReverse {
+		t.Errorf("expected reverse yellow, got %+v", c)
+	}
+	if y, x, visible := mem.Cursor(); y != 3 || x != 7 || !visible {
+		t.Errorf("expected a visible cursor at 3, 7, got %d, %d, %v", y, x,
+			visible)
+	}
+
+	panel.Hide()
+	goncurses.UpdatePanels()
+	goncurses.Update()
+	if got := mem.Line(3); got != "" {
+		t.Errorf("expected the panel to be hidden, got %q", got)
+	}
+}
+
+func TestMemoryInput(t *testing.T) {
+	mem := cursestest.NewMemory(4, 20)
+	stdscr, err := mem.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	mem.PressKey(goncurses.KEY_DOWN)
+	mem.TypeString("abcx")
+	mem.PressKey(goncurses.KEY_BACKSPACE)
+	mem.TypeString("\n")
+	if k := stdscr.GetChar(); k != goncurses.KEY_DOWN {
+		t.Errorf("expected KEY_DOWN, got %v", k)
+	}
+	s, err := stdscr.GetString(10)
+	if err != nil || s != "abc" {
+		t.Errorf("expected abc, got %q, %v", s, err)
+	}
+	if k := stdscr.GetChar(); k != 0 {
+		t.Errorf("expected no key from an empty queue, got %v", k)
+	}
+
+	mem.Resize(6, 30)
+	if k := stdscr.GetChar(); k != goncurses.KEY_RESIZE {
+		t.Errorf("expected KEY_RESIZE, got %v", k)
+	}
+	if rows, cols := stdscr.MaxYX(); rows != 6 || cols != 30 {
+		t.Errorf("expected stdscr to be 6x30, got %dx%d", rows, cols)
+	}
+	goncurses.Beep()
+	if mem.Beeps() != 1 {
+		t.Errorf("expected one beep, got %d", mem.Beeps())
+	}
+}
+
+func TestMemoryMonochrome(t *testing.T) {
+	mem := cursestest.NewMemory(4, 20)
+	mem.SetColors(0)
+	if _, err := mem.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+	goncurses.End()
+	if goncurses.StartColor() == nil {
+		t.Error("expected StartColor to fail without colors")
+	}
+	if !mem.Ended() {
+		t.Error("expected the backend to have been ended")
+	}
+}
+
+func TestMemoryKeys(t *testing.T) {
+	mem := cursestest.NewMemory(4, 20)
+	mem.SetKeys(goncurses.KEY_UP)
+	stdscr, err := mem.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+	stdscr.Keypad(true)
+
+	if !goncurses.HasKey(goncurses.KEY_UP) {
+		t.Error("expected the screen to have KEY_UP")
+	}
+	if goncurses.HasKey(goncurses.KEY_DOWN) {
+		t.Error("expected the screen to lack KEY_DOWN")
+	}
+	mem.PressKey(goncurses.KEY_UP)
+	mem.PressKey(goncurses.KEY_DOWN)
+	if k := stdscr.GetChar(); k != goncurses.KEY_UP {
+		t.Errorf("expected KEY_UP, got %v", k)
+	}
+	var seq []goncurses.Key
+	for k := stdscr.GetChar(); k != 0; k = stdscr.GetChar() {
+		seq = append(seq, k)
+	}
+	if len(seq) != 3 || seq[0] != goncurses.KEY_ESC || seq[2] != 'B' {
+		t.Errorf("expected the escape sequence of KEY_DOWN, got %v", seq)
+	}
+}
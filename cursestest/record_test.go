@@ -0,0 +1,107 @@
+// +build !windows,!purego
+
+package cursestest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/internal/pty"
+)
+
+// TestRecordedTerm is here rather than with the goncurses tests because
+// those use Init, and screens made by NewTerm cannot be mixed with it in
+// the same process
+func TestRecordedTerm(t *testing.T) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Close()
+	if err := pty.SetSize(master, 8, 30); err != nil {
+		t.Fatal(err)
+	}
+	var seen bytes.Buffer
+	drained := make(chan struct{})
+	go func() {
+		io.Copy(&seen, master)
+		close(drained)
+	}()
+
+	var cast bytes.Buffer
+	rec := goncurses.NewRecorder(&cast)
+	rec.Env = map[string]string{"TERM": "xterm-256color"}
+	screen, err := goncurses.NewRecordedTerm("xterm-256color", slave, slave,
+		rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdscr := goncurses.StdScr()
+	stdscr.Timeout(1000)
+	stdscr.MovePrint(1, 2, "recorded")
+	stdscr.Refresh()
+	master.Write([]byte("q"))
+	if k := stdscr.GetChar(); k != 'q' {
+		t.Errorf("expected q, got %v", k)
+	}
+	screen.End()
+	screen.Delete()
+
+	// once the screen is deleted input is left for the program
+	master.Write([]byte("x\n"))
+	slave.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 8)
+	if n, err := slave.Read(buf); err != nil || string(buf[:n]) != "x\n" {
+		t.Errorf("expected x to be left unread, got %q, %v", buf[:n], err)
+	}
+	slave.Close()
+	<-drained
+
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+	var header struct {
+		Version, Width, Height int
+		Env                    map[string]string
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 30 || header.Height != 8 ||
+		header.Env["TERM"] != "xterm-256color" {
+		t.Errorf("unexpected header %s", lines[0])
+	}
+	input := ""
+	for _, line := range lines[1:] {
+		var ev []interface{}
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev[1] == "i" {
+			input += ev[2].(string)
+		}
+	}
+	if input != "q" {
+		t.Errorf("expected input %q to be recorded, got %q", "q", input)
+	}
+
+	var replayed bytes.Buffer
+	if err := goncurses.Replay(&replayed, &cast, 0); err != nil {
+		t.Fatal(err)
+	}
+	// the terminal also echoed the input typed after the screen was deleted
+	if !bytes.HasPrefix(seen.Bytes(), replayed.Bytes()) {
+		t.Errorf("replayed output differs from the terminal's:\n%q\n%q",
+			replayed.Bytes(), seen.Bytes())
+	}
+	if !bytes.Contains(seen.Bytes(), []byte("recorded")) {
+		t.Errorf("expected output to be passed to the terminal, got %q",
+			seen.Bytes())
+	}
+}
@@ -0,0 +1,130 @@
+// +build !windows,!purego
+
+package cursestest_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+func newTerminal(t *testing.T, rows, cols int) *cursestest.Terminal {
+	term, err := cursestest.NewTerminal(rows, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goncurses.Cursor(0)
+	return term
+}
+
+func newItems(t *testing.T, names ...string) []*goncurses.MenuItem {
+	items := make([]*goncurses.MenuItem, len(names))
+	for i, name := range names {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		items[i] = item
+	}
+	return items
+}
+
+func TestMenuLayout(t *testing.T) {
+	term := newTerminal(t, 8, 30)
+	defer term.Close()
+
+	items := newItems(t, "Apple", "Banana", "Cherry", "Damson", "Elder",
+		"Fig", "Grape")
+	defer func() {
+		for _, item := range items {
+			item.Free()
+		}
+	}()
+	items[4].Selectable(false)
+
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+
+	win, err := goncurses.NewWindow(6, 28, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	win.Box(0, 0)
+	sub := win.Derived(4, 26, 1, 1)
+	defer sub.Delete()
+	menu.SetWindow(win)
+	menu.SubWindow(sub)
+	menu.Format(2, 2)
+	menu.Mark("* ")
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+
+	term.StdScr().Refresh()
+	win.Refresh()
+	term.AssertSnapshot(t, "menu_posted", cursestest.SnapshotStyle)
+
+	menu.Driver(goncurses.REQ_DOWN)
+	menu.Driver(goncurses.REQ_DOWN)
+	win.Refresh()
+	term.AssertSnapshot(t, "menu_scrolled", cursestest.SnapshotStyle)
+}
+
+func TestFormLayout(t *testing.T) {
+	term := newTerminal(t, 6, 30)
+	defer term.Close()
+
+	fields := make([]*goncurses.Field, 2)
+	for i := range fields {
+		field, err := goncurses.NewField(1, 12, int32(i*2+1), 10, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer field.Free()
+		field.SetBackground(goncurses.A_UNDERLINE)
+		field.Options(goncurses.FO_AUTOSKIP, false)
+		fields[i] = field
+	}
+	fields[0].SetBuffer("goncurses")
+
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.Free()
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer form.UnPost()
+
+	stdscr := term.StdScr()
+	stdscr.MovePrint(1, 2, "Name:")
+	stdscr.MovePrint(3, 2, "Email:")
+	form.Driver(goncurses.REQ_NEXT_FIELD)
+	for _, c := range "me@host" {
+		form.Driver(goncurses.FormDriverReq(c))
+	}
+	stdscr.Refresh()
+	term.AssertSnapshot(t, "form_filled", cursestest.SnapshotAll)
+}
+
+func TestMemoryConfirm(t *testing.T) {
+	mem := cursestest.NewMemory(10, 40)
+	if _, err := mem.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	mem.PressKey(goncurses.KEY_RIGHT)
+	mem.PressKey(goncurses.KEY_RETURN)
+	yes, err := goncurses.Confirm("Quit", "Really quit?")
+	if err != nil || yes {
+		t.Errorf("expected No to be picked, got %v, %v", yes, err)
+	}
+}
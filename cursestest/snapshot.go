@@ -0,0 +1,221 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cursestest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SnapshotFlags select what a snapshot records besides the text of the
+// screen
+type SnapshotFlags int
+
+const (
+	// SnapshotStyle records the attributes and colors of every cell
+	SnapshotStyle SnapshotFlags = 1 << iota
+	// SnapshotCursor records the position and visibility of the cursor
+	SnapshotCursor
+)
+
+// SnapshotAll records everything
+const SnapshotAll = SnapshotStyle | SnapshotCursor
+
+// styleKeys are the characters used to mark cells in the style section of
+// a snapshot, in the order styles are first seen. Cells in the default
+// style are marked with a '.'
+const styleKeys = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var attrNames = []struct {
+	attr Attr
+	name string
+}{
+	{AttrBold, "bold"},
+	{AttrDim, "dim"},
+	{AttrItalic, "italic"},
+	{AttrUnderline, "underline"},
+	{AttrBlink, "blink"},
+	{AttrReverse, "reverse"},
+	{AttrInvisible, "invisible"},
+	{AttrStrike, "strike"},
+}
+
+// Snapshot renders the screen in a stable text form suitable for golden
+// files. The first line gives the size of the screen and, with
+// SnapshotCursor, the cursor position. Each row of the screen follows
+// between '|' characters, so trailing blanks are visible.
+//
+// With SnapshotStyle a second grid follows in which each cell is marked
+// with a key for its style, '.' being the default style, and then a
+// legend describing each key:
+//
+//	size 2x6
+//	|bold  |
+//	|      |
+//	style
+//	|aaaa..|
+//	|......|
+//	a bold fg=1 bg=default
+//
+// Styles are keyed in the order they are first seen, reading left to right
+// and top to bottom, so the keys are stable as long as the screen is.
+func (vt *VT) Snapshot(flags SnapshotFlags) string {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "size %dx%d", vt.rows, vt.cols)
+	if flags&SnapshotCursor != 0 {
+		state := "visible"
+		if !vt.visible {
+			state = "hidden"
+		}
+		fmt.Fprintf(&b, " cursor %d,%d %s", vt.y, vt.x, state)
+	}
+	b.WriteByte('\n')
+	for _, row := range vt.grid {
+		b.WriteByte('|')
+		for _, c := range row {
+			if c.Ch != 0 {
+				b.WriteRune(c.Ch)
+				b.WriteString(c.Comb)
+			}
+		}
+		b.WriteString("|\n")
+	}
+	if flags&SnapshotStyle == 0 {
+		return b.String()
+	}
+
+	b.WriteString("style\n")
+	keys := map[Cell]rune{}
+	var legend []string
+	for _, row := range vt.grid {
+		b.WriteByte('|')
+		for _, c := range row {
+			c.Ch, c.Comb = ' ', ""
+			if c == blankCell {
+				b.WriteByte('.')
+				continue
+			}
+			key, ok := keys[c]
+			if !ok {
+				key = styleKey(len(legend))
+				keys[c] = key
+				legend = append(legend, string(key)+" "+styleString(c))
+			}
+			b.WriteRune(key)
+		}
+		b.WriteString("|\n")
+	}
+	for _, l := range legend {
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// styleKey returns the key for the nth style. Once the letters and digits
+// run out, characters from the Latin-1 supplement onwards are used
+func styleKey(n int) rune {
+	if n < len(styleKeys) {
+		return rune(styleKeys[n])
+	}
+	return rune(0xc0 + n - len(styleKeys))
+}
+
+// styleString describes the attributes and colors of c
+func styleString(c Cell) string {
+	var parts []string
+	for _, a := range attrNames {
+		if c.Attr&a.attr != 0 {
+			parts = append(parts, a.name)
+		}
+	}
+	parts = append(parts, "fg="+c.Fg.String(), "bg="+c.Bg.String())
+	return strings.Join(parts, " ")
+}
+
+// UpdateEnv names the environment variable which, when set to anything but
+// an empty string, "0" or "false", makes AssertSnapshot rewrite golden
+// files rather than compare against them
+const UpdateEnv = "CURSESTEST_UPDATE"
+
+// Update makes AssertSnapshot rewrite golden files rather than compare
+// against them. The package does not define any flags itself; a test
+// package wanting an -update flag should bind it to this variable:
+//
+//	func init() {
+//		flag.BoolVar(&cursestest.Update, "update", false,
+//			"rewrite golden files")
+//	}
+var Update bool
+
+// updating reports whether golden files should be rewritten rather than
+// compared against
+func updating() bool {
+	if Update {
+		return true
+	}
+	switch os.Getenv(UpdateEnv) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+// TB is the part of testing.TB used to report a failed snapshot. It is
+// satisfied by *testing.T and *testing.B
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertSnapshot compares got with the golden file testdata/name.golden
+// and fails the test if they differ, reporting the first line which does.
+// Set Update, or run the tests with CURSESTEST_UPDATE=1 in the
+// environment, to write got to the golden file instead.
+func AssertSnapshot(tb TB, name, got string) {
+	tb.Helper()
+	path := filepath.Join("testdata", filepath.FromSlash(name)+".golden")
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("%v (set %s=1 to create it)", err, UpdateEnv)
+	}
+	want := string(data)
+	if got == want {
+		return
+	}
+	gl, wl := strings.Split(got, "\n"), strings.Split(want, "\n")
+	line := 0
+	for line < len(gl) && line < len(wl) && gl[line] == wl[line] {
+		line++
+	}
+	var g, w string
+	if line < len(gl) {
+		g = gl[line]
+	}
+	if line < len(wl) {
+		w = wl[line]
+	}
+	tb.Errorf("snapshot does not match %s at line %d:\nwant %q\n got %q\n"+
+		"full snapshot:\n%s", path, line+1, w, g, got)
+}
@@ -0,0 +1,56 @@
+package cursestest_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+func init() {
+	flag.BoolVar(&cursestest.Update, "update", false,
+		"rewrite cursestest golden files")
+}
+
+func TestSnapshot(t *testing.T) {
+	vt := cursestest.NewVT(2, 6)
+	vt.Write([]byte("\x1b[1;31mbold\x1b[0m\r\n\x1b[44m \x1b[0m \x1b[7mr\x1b[?25l"))
+
+	want := "size 2x6\n|bold  |\n|  r   |\n"
+	if got := vt.Snapshot(0); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	want = "size 2x6 cursor 1,3 hidden\n|bold  |\n|  r   |\nstyle\n" +
+		"|aaaa..|\n|b.c...|\n" +
+		"a bold fg=1 bg=default\nb fg=default bg=4\n" +
+		"c reverse fg=default bg=default\n"
+	if got := vt.Snapshot(cursestest.SnapshotAll); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAssertSnapshotUpdate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	const snapshot = "size 1x2\n|ok|\n"
+	update := cursestest.Update
+	cursestest.Update = true
+	cursestest.AssertSnapshot(t, "updated", snapshot)
+	cursestest.Update = update
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "updated.golden"))
+	if err != nil || string(data) != snapshot {
+		t.Fatalf("expected Update to write the golden file, got %q, %v",
+			data, err)
+	}
+	cursestest.AssertSnapshot(t, "updated", snapshot)
+}
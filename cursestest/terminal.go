@@ -0,0 +1,258 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+// Package cursestest runs goncurses against an emulated terminal so that
+// programs can be tested without a real one. A Terminal connects a new
+// ncurses screen to a pseudo-terminal whose output is interpreted by an
+// in-process xterm emulator, VT. After drawing, call Sync and inspect the
+// screen with Line, Cell and Cursor, or compare the whole screen with a
+// golden file using AssertSnapshot:
+//
+//	term, err := cursestest.NewTerminal(24, 80)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer term.Close()
+//
+//	term.StdScr().MovePrint(0, 0, "Hello")
+//	term.StdScr().Refresh()
+//	if err := term.Sync(); err != nil {
+//		t.Fatal(err)
+//	}
+//	if term.Line(0) != "Hello" {
+//		t.Errorf("unexpected screen:\n%s", term)
+//	}
+//
+// Input is scripted with TypeString, PressKey, Click and Resize. It is
+// queued on the terminal and read by the program the next time it asks for
+// input, so a test sends the input for a flow first and then runs it.
+//
+// The terminal is always of type Term, and of the size given, whatever the
+// environment the tests run in.
+//
+// Terminal needs cgo and ncurses. Memory draws without a terminal at all,
+// so it suits unit tests of layout code. It runs an ncurses screen whose
+// output is kept in memory; with the purego tag it is a goncurses.Backend
+// instead, and forms, menus and dialogs cannot be tested since they are
+// not part of that build.
+package cursestest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/internal/pty"
+)
+
+// Term is the terminal type ncurses is told it is talking to
+const Term = "xterm-256color"
+
+// SyncTimeout is how long Sync waits for output to reach the emulator
+var SyncTimeout = 5 * time.Second
+
+// syncPrefix starts the operating system command used as a sync marker.
+// Terminals ignore commands they do not know
+const syncPrefix = "goncurses-sync;"
+
+// Terminal is an ncurses screen running on an emulated terminal
+type Terminal struct {
+	vt            *VT
+	master, slave *os.File
+	screen        *goncurses.Screen
+	stdscr        *goncurses.Window
+	syncID        int
+	synced        chan int
+	done          chan struct{}
+}
+
+// NewTerminal creates a terminal of the given size and starts ncurses on
+// it with NewTerm. The new screen is made the current one. Init must not be
+// called. The screen is resized to the size of the terminal if the LINES
+// and COLUMNS environment variables say otherwise, though lines ripped off
+// with RipOffLine keep the width ncurses first gave them
+func NewTerminal(rows, cols int) (*Terminal, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+	if err := pty.SetSize(master, rows, cols); err != nil {
+		master.Close()
+		slave.Close()
+		return nil, err
+	}
+
+	t := &Terminal{
+		vt:     NewVT(rows, cols),
+		master: master,
+		slave:  slave,
+		synced: make(chan int, 1),
+		done:   make(chan struct{}),
+	}
+	t.vt.respond = func(b []byte) { master.Write(b) }
+	t.vt.onOSC = t.osc
+	go t.read()
+
+	t.screen, err = goncurses.NewTerm(Term, slave, slave)
+	if err != nil {
+		master.Close()
+		slave.Close()
+		<-t.done
+		return nil, err
+	}
+	if _, err := t.screen.Set(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	// ncurses takes the size of the screen from LINES and COLUMNS, when
+	// set, or from the terminal's description if told not to ask the
+	// terminal, so it may not match. The KEY_RESIZE queued by resizing the
+	// screen to fit is dropped since the program has not seen a resize
+	if r, c := t.screen.Size(); r != rows || c != cols {
+		if err := t.screen.Resize(rows, cols); err != nil {
+			t.Close()
+			return nil, err
+		}
+		goncurses.FlushInput()
+	}
+	t.stdscr = goncurses.StdScr()
+	return t, nil
+}
+
+// read feeds the output of the terminal to the emulator until the master
+// is closed
+func (t *Terminal) read() {
+	defer close(t.done)
+	buf := make([]byte, 4096)
+	for {
+		n, err := t.master.Read(buf)
+		t.vt.Write(buf[:n])
+		if err != nil {
+			return
+		}
+	}
+}
+
+// osc is called by the emulator for each operating system command. Sync
+// markers are passed on to Sync, dropping any which were not waited for
+func (t *Terminal) osc(s string) {
+	if !strings.HasPrefix(s, syncPrefix) {
+		return
+	}
+	id, err := strconv.Atoi(s[len(syncPrefix):])
+	if err != nil {
+		return
+	}
+	select {
+	case <-t.synced:
+	default:
+	}
+	t.synced <- id
+}
+
+// Screen returns the ncurses screen running on the terminal
+func (t *Terminal) Screen() *goncurses.Screen {
+	return t.screen
+}
+
+// StdScr returns the standard screen window of the terminal
+func (t *Terminal) StdScr() *goncurses.Window {
+	return t.stdscr
+}
+
+// VT returns the emulator interpreting the terminal's output
+func (t *Terminal) VT() *VT {
+	return t.vt
+}
+
+// Sync waits until everything ncurses has written to the terminal so far
+// has been interpreted by the emulator. Call it after refreshing and before
+// inspecting the screen. It fails if the output does not arrive within
+// SyncTimeout
+func (t *Terminal) Sync() error {
+	t.syncID++
+	marker := fmt.Sprintf("\x1b]%s%d\x07", syncPrefix, t.syncID)
+	if _, err := t.slave.Write([]byte(marker)); err != nil {
+		return err
+	}
+	timeout := time.After(SyncTimeout)
+	for {
+		select {
+		case id := <-t.synced:
+			if id == t.syncID {
+				return nil
+			}
+		case <-timeout:
+			return errors.New("cursestest: timed out waiting for output")
+		case <-t.done:
+			return errors.New("cursestest: terminal closed")
+		}
+	}
+}
+
+// Cell returns the cell at row y, column x of the screen
+func (t *Terminal) Cell(y, x int) Cell {
+	return t.vt.Cell(y, x)
+}
+
+// Cursor returns the position of the cursor and whether it is visible
+func (t *Terminal) Cursor() (y, x int, visible bool) {
+	return t.vt.Cursor()
+}
+
+// Line returns the text of row y of the screen with trailing blanks
+// removed
+func (t *Terminal) Line(y int) string {
+	return t.vt.Line(y)
+}
+
+// Size returns the number of rows and columns of the terminal
+func (t *Terminal) Size() (rows, cols int) {
+	return t.vt.Size()
+}
+
+// String returns the text of the whole screen, one line per row
+func (t *Terminal) String() string {
+	return t.vt.String()
+}
+
+// Snapshot waits for the output of the terminal to be interpreted, as Sync
+// does, and returns a snapshot of the screen
+func (t *Terminal) Snapshot(flags SnapshotFlags) (string, error) {
+	if err := t.Sync(); err != nil {
+		return "", err
+	}
+	return t.vt.Snapshot(flags), nil
+}
+
+// AssertSnapshot compares a snapshot of the screen with the golden file
+// testdata/name.golden. See the package-level AssertSnapshot
+func (t *Terminal) AssertSnapshot(tb TB, name string,
+	flags SnapshotFlags) {
+	tb.Helper()
+	s, err := t.Snapshot(flags)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	AssertSnapshot(tb, name, s)
+}
+
+// Close ends ncurses on the terminal, frees the screen and closes the
+// pseudo-terminal
+func (t *Terminal) Close() error {
+	t.screen.End()
+	t.screen.Delete()
+	err := t.slave.Close()
+	if merr := t.master.Close(); err == nil {
+		err = merr
+	}
+	<-t.done
+	return err
+}
@@ -0,0 +1,129 @@
+// +build !windows,!purego
+
+package cursestest_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+func TestTerminal(t *testing.T) {
+	term, err := cursestest.NewTerminal(10, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer term.Close()
+
+	if rows, cols := term.StdScr().MaxYX(); rows != 10 || cols != 40 {
+		t.Fatalf("expected a 10x40 screen, got %dx%d", rows, cols)
+	}
+
+	stdscr := term.StdScr()
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	goncurses.InitPair(1, goncurses.C_RED, goncurses.C_BLUE)
+	stdscr.Box(0, 0)
+	stdscr.ColorOn(1)
+	stdscr.AttrOn(goncurses.A_BOLD)
+	stdscr.MovePrint(2, 3, "hello")
+	stdscr.AttrOff(goncurses.A_BOLD)
+	stdscr.ColorOff(1)
+	stdscr.Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "┌──────────────────────────────────────┐"; term.Line(0) != want {
+		t.Errorf("expected border %q, got %q", want, term.Line(0))
+	}
+	if want := "│  hello                               │"; term.Line(2) != want {
+		t.Errorf("expected %q, got %q", want, term.Line(2))
+	}
+	want := cursestest.Cell{Ch: 'h', Attr: cursestest.AttrBold,
+		Fg: goncurses.C_RED, Bg: goncurses.C_BLUE}
+	if got := term.Cell(2, 3); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if y, x, _ := term.Cursor(); y != 2 || x != 8 {
+		t.Errorf("expected cursor at 2,8, got %d,%d", y, x)
+	}
+}
+
+func TestRipOffLine(t *testing.T) {
+	var header, footer *goncurses.Window
+	goncurses.RipOffLine(true, func(w *goncurses.Window, cols int) {
+		header = w
+		if cols != 40 {
+			t.Errorf("expected the ripped off line to be 40 wide, got %d",
+				cols)
+		}
+	})
+	goncurses.RipOffLine(false, func(w *goncurses.Window, cols int) {
+		footer = w
+	})
+	term, err := cursestest.NewTerminal(10, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer term.Close()
+	if header == nil || footer == nil {
+		t.Fatal("expected both lines to have been ripped off")
+	}
+
+	stdscr := term.StdScr()
+	if rows, cols := stdscr.MaxYX(); rows != 8 || cols != 40 {
+		t.Fatalf("expected stdscr to be 8x40, got %dx%d", rows, cols)
+	}
+	header.Print("header")
+	header.NoutRefresh()
+	footer.Print("footer")
+	footer.NoutRefresh()
+	stdscr.Clear()
+	stdscr.Print("body")
+	stdscr.Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	for y, want := range map[int]string{0: "header", 1: "body",
+		9: "footer"} {
+		if got := term.Line(y); got != want {
+			t.Errorf("expected line %d to be %q, got %q", y, want, got)
+		}
+	}
+}
+
+func TestRipOffLineFreed(t *testing.T) {
+	goncurses.RipOffLine(false, func(w *goncurses.Window, cols int) {})
+	term, err := cursestest.NewTerminal(10, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	term.Close()
+
+	// resizing redraws the bottom lines ripped off from every screen, so
+	// this fails if the first screen was freed along with its line
+	term, err = cursestest.NewTerminal(10, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer term.Close()
+	if err := term.Resize(6, 20); err != nil {
+		t.Fatal(err)
+	}
+	// no lines were ripped off from this screen
+	if rows, cols := term.StdScr().MaxYX(); rows != 6 || cols != 20 {
+		t.Fatalf("expected stdscr to be 6x20, got %dx%d", rows, cols)
+	}
+	term.StdScr().Print("resized")
+	term.StdScr().Refresh()
+	if err := term.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := term.Line(0); got != "resized" {
+		t.Errorf("expected line 0 to be \"resized\", got %q", got)
+	}
+}
@@ -0,0 +1,232 @@
+// +build !windows,!purego
+
+package cursestest_test
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+// waitLine waits for row y of vt to read want
+func waitLine(t *testing.T, vt *cursestest.VT, y int, want string) {
+	t.Helper()
+	deadline := time.Now().Add(cursestest.SyncTimeout)
+	for vt.Line(y) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected line %d to be %q, screen is:\n%s", y, want, vt)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNewTermIO(t *testing.T) {
+	type seat struct {
+		vt     *cursestest.VT
+		input  *io.PipeWriter
+		screen *goncurses.Screen
+	}
+	seats := make([]seat, 2)
+	for i := range seats {
+		rows, cols := 6+i*2, 20+i*10
+		pr, pw := io.Pipe()
+		vt := cursestest.NewVT(rows, cols)
+		screen, err := goncurses.NewTermIO(cursestest.Term, vt, pr, rows,
+			cols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pw.Close()
+		defer screen.Delete()
+		defer screen.End()
+		seats[i] = seat{vt, pw, screen}
+	}
+
+	for i, s := range seats {
+		if _, err := s.screen.Set(); err != nil {
+			t.Fatal(err)
+		}
+		if rows, cols := s.screen.Size(); rows != 6+i*2 || cols != 20+i*10 {
+			t.Errorf("expected screen %d to be %dx%d, got %dx%d", i, 6+i*2,
+				20+i*10, rows, cols)
+		}
+		stdscr := goncurses.StdScr()
+		stdscr.Timeout(1000)
+		stdscr.MovePrint(1, 1, "seat ", i)
+		stdscr.Refresh()
+	}
+	for i, s := range seats {
+		waitLine(t, s.vt, 1, " seat "+string(rune('0'+i)))
+	}
+
+	// input reaches only the screen it was sent to
+	seats[1].input.Write([]byte("x"))
+	seats[1].screen.Set()
+	if k := goncurses.StdScr().GetChar(); k != 'x' {
+		t.Errorf("expected x on screen 1, got %v", k)
+	}
+
+	if err := seats[0].screen.Resize(4, 12); err != nil {
+		t.Fatal(err)
+	}
+	if rows, cols := seats[0].screen.Size(); rows != 4 || cols != 12 {
+		t.Errorf("expected a 4x12 screen after resizing, got %dx%d", rows,
+			cols)
+	}
+	seats[0].screen.Set()
+	stdscr := goncurses.StdScr()
+	if k := stdscr.GetChar(); k != goncurses.KEY_RESIZE {
+		t.Errorf("expected KEY_RESIZE, got %v", k)
+	}
+	if rows, cols := stdscr.MaxYX(); rows != 4 || cols != 12 {
+		t.Errorf("expected stdscr to be 4x12, got %dx%d", rows, cols)
+	}
+}
+
+func TestNewTermIOInput(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	vt := cursestest.NewVT(6, 20)
+	screen, err := goncurses.NewTermIO(cursestest.Term, vt, server, 6, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen.Set()
+	stdscr := goncurses.StdScr()
+	stdscr.Timeout(1000)
+	go client.Write([]byte("a"))
+	if k := stdscr.GetChar(); k != 'a' {
+		t.Errorf("expected a, got %v", k)
+	}
+	screen.End()
+	screen.Delete()
+
+	// the connection is left for the program once the screen is deleted
+	go client.Write([]byte("b"))
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1)
+	if n, err := server.Read(buf); err != nil || string(buf[:n]) != "b" {
+		t.Errorf("expected b to be left unread, got %q, %v", buf[:n], err)
+	}
+}
+
+func TestScreenFunctions(t *testing.T) {
+	var vts [2]*cursestest.VT
+	var inputs [2]*io.PipeWriter
+	var screens [2]*goncurses.Screen
+	for i := range screens {
+		pr, pw := io.Pipe()
+		vts[i] = cursestest.NewVT(6, 20)
+		screen, err := goncurses.NewTermIO(cursestest.Term, vts[i], pr, 6, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pw.Close()
+		defer screen.Delete()
+		defer screen.End()
+		inputs[i], screens[i] = pw, screen
+	}
+
+	// the second screen is current, yet all of this acts on the first
+	a := screens[0]
+	win, err := a.NewWindow(2, 10, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if win.Screen() != a || win.Derived(1, 5, 0, 0).Screen() != a {
+		t.Error("expected the window to belong to the screen it was made on")
+	}
+	if err := a.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.InitPair(1, goncurses.C_RED, goncurses.C_BLACK); err != nil {
+		t.Fatal(err)
+	}
+	a.Cursor(0)
+	win.ColorOn(1)
+	win.Print("first")
+	a.StdScr().NoutRefresh()
+	win.NoutRefresh()
+	if err := a.Update(); err != nil {
+		t.Fatal(err)
+	}
+	waitLine(t, vts[0], 1, "  first")
+	if fg := vts[0].Cell(1, 2).Fg; fg != cursestest.Color(goncurses.C_RED) {
+		t.Errorf("expected red text, got color %d", fg)
+	}
+
+	inputs[0].Write([]byte("y"))
+	win.Timeout(1000)
+	if k := win.GetChar(); k != 'y' {
+		t.Errorf("expected y on the first screen, got %v", k)
+	}
+	if cur, _ := screens[1].Set(); cur != screens[1] {
+		t.Error("expected the second screen to still be current")
+	}
+
+	b := screens[1].StdScr()
+	b.Print("second")
+	b.Refresh()
+	waitLine(t, vts[1], 0, "second")
+	if line := vts[0].Line(0); line != "" {
+		t.Errorf("expected the first screen's top line to be blank, got %q",
+			line)
+	}
+}
+
+func TestScreenClose(t *testing.T) {
+	opr, opw := io.Pipe()
+	defer opw.Close()
+	other, err := goncurses.NewTermIO(cursestest.Term, cursestest.NewVT(5, 10),
+		opr, 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	vt := cursestest.NewVT(5, 10)
+	screen, err := goncurses.NewTermIO(cursestest.Term, vt, pr, 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Set()
+	if name := screen.TermName(); name != cursestest.Term {
+		t.Errorf("expected terminal %q, got %q", cursestest.Term, name)
+	}
+	if !screen.HasColors() {
+		t.Error("expected the terminal to have colors")
+	}
+	if screen.IsEnd() {
+		t.Error("expected the screen not to have ended")
+	}
+	if err := screen.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !screen.IsEnd() {
+		t.Error("expected a closed screen to have ended")
+	}
+	if cur, _ := other.Set(); cur != other {
+		t.Error("expected closing a screen to leave the current screen alone")
+	}
+	if err := screen.Close(); err != goncurses.ErrScreenClosed {
+		t.Errorf("expected closing twice to fail with ErrScreenClosed, got %v",
+			err)
+	}
+	if _, err := screen.Set(); err != goncurses.ErrScreenClosed {
+		t.Errorf("expected Set to fail with ErrScreenClosed, got %v", err)
+	}
+	if _, err := screen.NewWindow(1, 1, 0, 0); err != goncurses.ErrScreenClosed {
+		t.Errorf("expected NewWindow to fail with ErrScreenClosed, got %v", err)
+	}
+	if rows, cols := screen.Size(); rows != 0 || cols != 0 {
+		t.Errorf("expected a closed screen to have no size, got %dx%d", rows,
+			cols)
+	}
+}
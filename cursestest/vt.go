@@ -0,0 +1,1020 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cursestest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rthornton128/goncurses/internal/width"
+)
+
+// Attr is a set of character attributes
+type Attr uint16
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrReverse
+	AttrInvisible
+	AttrStrike
+)
+
+// Color is a terminal color. It is either DefaultColor, an index into the
+// terminal's 256 color palette or a 24-bit color made by RGB
+type Color int32
+
+// DefaultColor is the terminal's default foreground or background color
+const DefaultColor Color = -1
+
+const colorRGB = 1 << 24
+
+// RGB returns a 24-bit color
+func RGB(r, g, b uint8) Color {
+	return Color(colorRGB | int32(r)<<16 | int32(g)<<8 | int32(b))
+}
+
+// IsRGB reports whether c is a 24-bit color rather than a palette index
+func (c Color) IsRGB() bool {
+	return c >= 0 && c&colorRGB != 0
+}
+
+// RGB returns the components of a 24-bit color
+func (c Color) RGB() (r, g, b uint8) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c)
+}
+
+func (c Color) String() string {
+	switch {
+	case c == DefaultColor:
+		return "default"
+	case c.IsRGB():
+		r, g, b := c.RGB()
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+	return fmt.Sprint(int32(c))
+}
+
+// Cell is a single character position on the screen. A wide character
+// takes two cells, the second of which has a Ch of 0. Comb holds any
+// combining characters, such as accents, drawn over Ch
+type Cell struct {
+	Ch     rune
+	Comb   string
+	Attr   Attr
+	Fg, Bg Color
+}
+
+var blankCell = Cell{Ch: ' ', Fg: DefaultColor, Bg: DefaultColor}
+
+// decGraphics maps the DEC special graphics character set, used by
+// terminals to draw lines and boxes, to Unicode
+var decGraphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'h': '░', 'i': '␋', 'j': '┘',
+	'k': '┐', 'l': '┌', 'm': '└', 'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─',
+	'r': '⎼', 's': '⎽', 't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│',
+	'y': '≤', 'z': '≥', '{': 'π', '|': '≠', '}': '£', '~': '·',
+}
+
+// parser states
+const (
+	stGround = iota
+	stEscape
+	stCharset // ESC ( or ESC ), waiting for the character set
+	stSkip    // discard one byte
+	stCSI
+	stOSC
+	stOSCEscape
+	stString // DCS, APC, PM or SOS, ignored up to the terminator
+	stStringEscape
+)
+
+type cursor struct {
+	y, x   int
+	pen    Cell
+	origin bool
+	g      [2]byte
+	gl     int
+}
+
+// VT is an in-process emulation of an xterm compatible terminal. Output
+// written to it is interpreted and the resulting screen can be inspected
+// with Cell, Line and Cursor. The emulation covers what ncurses uses with
+// the xterm family of terminal descriptions: cursor movement, erasing,
+// insertion and deletion, scrolling regions, the alternate screen, line
+// drawing characters and 8, 256 and 24-bit color. Every character is
+// assumed to occupy a single column.
+//
+// A VT is safe for use by multiple goroutines.
+type VT struct {
+	mu         sync.Mutex
+	rows, cols int
+	grid       [][]Cell
+	main, alt  [][]Cell
+	altActive  bool
+	tabs       []bool
+
+	cursor
+	saved       cursor
+	wrapPending bool
+	top, bottom int
+	visible     bool
+	autowrap    bool
+	insert      bool
+	appCursor   bool
+	appKeypad   bool
+	mouse       bool // mouse button events are reported
+	sgrMouse    bool // mouse events are reported in SGR (1006) form
+	last        rune
+	bells       int // bells rung
+	flashes     int // times reverse video has been turned on
+
+	state   int
+	slot    int // character set being designated
+	params  []int
+	private byte
+	inter   []byte
+	osc     []byte
+	pending []byte // incomplete UTF-8 sequence
+
+	// respond, if not nil, is passed replies to device status and
+	// attribute requests, which a real terminal sends as input
+	respond func([]byte)
+	// onOSC, if not nil, is called with the text of each operating system
+	// command
+	onOSC func(string)
+}
+
+// NewVT returns a terminal of the given size with a blank screen and the
+// cursor in the top left corner
+func NewVT(rows, cols int) *VT {
+	vt := &VT{rows: rows, cols: cols}
+	vt.reset()
+	return vt
+}
+
+func newGrid(rows, cols int) [][]Cell {
+	grid := make([][]Cell, rows)
+	for y := range grid {
+		grid[y] = make([]Cell, cols)
+		for x := range grid[y] {
+			grid[y][x] = blankCell
+		}
+	}
+	return grid
+}
+
+func (vt *VT) reset() {
+	vt.main = newGrid(vt.rows, vt.cols)
+	vt.alt = newGrid(vt.rows, vt.cols)
+	vt.grid = vt.main
+	vt.altActive = false
+	vt.tabs = make([]bool, vt.cols)
+	for x := 8; x < vt.cols; x += 8 {
+		vt.tabs[x] = true
+	}
+	vt.cursor = cursor{pen: blankCell, g: [2]byte{'B', 'B'}}
+	vt.saved = vt.cursor
+	vt.wrapPending = false
+	vt.top, vt.bottom = 0, vt.rows-1
+	vt.visible = true
+	vt.autowrap = true
+	vt.insert = false
+	vt.appCursor = false
+	vt.appKeypad = false
+	vt.mouse = false
+	vt.sgrMouse = false
+	vt.state = stGround
+}
+
+// Size returns the number of rows and columns of the screen
+func (vt *VT) Size() (rows, cols int) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.rows, vt.cols
+}
+
+// Resize changes the size of the screen, keeping the contents of the top
+// left corner and resetting the scrolling region
+func (vt *VT) Resize(rows, cols int) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+
+	resize := func(old [][]Cell) [][]Cell {
+		grid := newGrid(rows, cols)
+		for y := 0; y < rows && y < len(old); y++ {
+			copy(grid[y], old[y])
+		}
+		return grid
+	}
+	vt.main, vt.alt = resize(vt.main), resize(vt.alt)
+	vt.grid = vt.main
+	if vt.altActive {
+		vt.grid = vt.alt
+	}
+	tabs := make([]bool, cols)
+	copy(tabs, vt.tabs)
+	for x := (len(vt.tabs) + 7) / 8 * 8; x < cols; x += 8 {
+		tabs[x] = true
+	}
+	vt.tabs = tabs
+	vt.rows, vt.cols = rows, cols
+	vt.top, vt.bottom = 0, rows-1
+	vt.y, vt.x = clamp(vt.y, 0, rows-1), clamp(vt.x, 0, cols-1)
+	vt.wrapPending = false
+}
+
+// Cell returns the cell at row y, column x. Positions outside of the
+// screen return a blank cell
+func (vt *VT) Cell(y, x int) Cell {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	if y < 0 || y >= vt.rows || x < 0 || x >= vt.cols {
+		return blankCell
+	}
+	return vt.grid[y][x]
+}
+
+// Line returns the text of row y with trailing blanks removed
+func (vt *VT) Line(y int) string {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.line(y)
+}
+
+func (vt *VT) line(y int) string {
+	if y < 0 || y >= vt.rows {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range vt.grid[y] {
+		if c.Ch != 0 {
+			b.WriteRune(c.Ch)
+			b.WriteString(c.Comb)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// String returns the text of the whole screen, one line per row, with
+// trailing blanks removed from each
+func (vt *VT) String() string {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	lines := make([]string, vt.rows)
+	for y := range lines {
+		lines[y] = vt.line(y)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cursor returns the position of the cursor and whether it is visible
+func (vt *VT) Cursor() (y, x int, visible bool) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.y, vt.x, vt.visible
+}
+
+// AppCursorKeys reports whether the terminal is in application cursor key
+// mode, in which the cursor keys send ESC O rather than ESC [ sequences
+func (vt *VT) AppCursorKeys() bool {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.appCursor
+}
+
+// MouseTracking reports whether the terminal has been asked to report
+// mouse button events
+func (vt *VT) MouseTracking() bool {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.mouse
+}
+
+// keypadMode reports whether the keypad is in application mode
+func (vt *VT) keypadMode() bool {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.appKeypad
+}
+
+// mouseModes returns whether mouse button events are reported and whether
+// they are reported in SGR form
+func (vt *VT) mouseModes() (tracking, sgr bool) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.mouse, vt.sgrMouse
+}
+
+// AltScreen reports whether the alternate screen is being displayed
+func (vt *VT) AltScreen() bool {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	return vt.altActive
+}
+
+// Write interprets p as output to the terminal. It never fails
+func (vt *VT) Write(p []byte) (int, error) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+	for _, b := range p {
+		vt.feed(b)
+	}
+	return len(p), nil
+}
+
+func (vt *VT) feed(b byte) {
+	switch vt.state {
+	case stGround:
+		if len(vt.pending) > 0 || b >= 0x80 {
+			vt.pending = append(vt.pending, b)
+			if !utf8.FullRune(vt.pending) {
+				return
+			}
+			r, _ := utf8.DecodeRune(vt.pending)
+			vt.pending = vt.pending[:0]
+			vt.print(r)
+			return
+		}
+		if b < 0x20 || b == 0x7f {
+			vt.control(b)
+			return
+		}
+		vt.print(rune(b))
+	case stEscape:
+		vt.escape(b)
+	case stCharset:
+		vt.g[vt.slot] = b
+		vt.state = stGround
+	case stSkip:
+		vt.state = stGround
+	case stCSI:
+		vt.csiByte(b)
+	case stOSC:
+		switch b {
+		case 0x07:
+			vt.endOSC()
+		case 0x1b:
+			vt.state = stOSCEscape
+		default:
+			vt.osc = append(vt.osc, b)
+		}
+	case stOSCEscape:
+		if b == '\\' {
+			vt.endOSC()
+			return
+		}
+		vt.state = stEscape
+		vt.escape(b)
+	case stString:
+		if b == 0x1b {
+			vt.state = stStringEscape
+		} else if b == 0x07 {
+			vt.state = stGround
+		}
+	case stStringEscape:
+		if b == '\\' {
+			vt.state = stGround
+			return
+		}
+		vt.state = stEscape
+		vt.escape(b)
+	}
+}
+
+func (vt *VT) endOSC() {
+	vt.state = stGround
+	if vt.onOSC != nil {
+		vt.onOSC(string(vt.osc))
+	}
+}
+
+func (vt *VT) control(b byte) {
+	switch b {
+	case 0x07: // BEL
+		vt.bells++
+	case 0x08: // BS
+		if vt.x > 0 {
+			vt.x--
+		}
+		vt.wrapPending = false
+	case 0x09: // HT
+		vt.x = vt.nextTab(vt.x)
+		vt.wrapPending = false
+	case 0x0a, 0x0b, 0x0c: // LF, VT, FF
+		vt.index()
+	case 0x0d: // CR
+		vt.x = 0
+		vt.wrapPending = false
+	case 0x0e: // SO
+		vt.gl = 1
+	case 0x0f: // SI
+		vt.gl = 0
+	case 0x1b:
+		vt.state = stEscape
+	}
+}
+
+func (vt *VT) escape(b byte) {
+	vt.state = stGround
+	switch b {
+	case '[':
+		vt.state = stCSI
+		vt.params = vt.params[:0]
+		vt.private = 0
+		vt.inter = vt.inter[:0]
+	case ']':
+		vt.state = stOSC
+		vt.osc = vt.osc[:0]
+	case 'P', '_', '^', 'X':
+		vt.state = stString
+	case '(', ')':
+		vt.slot = int(b - '(')
+		vt.state = stCharset
+	case '*', '+', '#', '%', ' ':
+		// G2 and G3 designations, DECALN and the like are ignored
+		vt.state = stSkip
+	case '7':
+		vt.saveCursor()
+	case '8':
+		vt.restoreCursor()
+	case 'D':
+		vt.index()
+	case 'E':
+		vt.x = 0
+		vt.index()
+	case 'M':
+		vt.reverseIndex()
+	case 'H':
+		if vt.x < vt.cols {
+			vt.tabs[vt.x] = true
+		}
+	case 'c':
+		vt.reset()
+	case '=':
+		vt.appKeypad = true
+	case '>':
+		vt.appKeypad = false
+	case 0x1b:
+		vt.state = stEscape
+	}
+}
+
+func (vt *VT) csiByte(b byte) {
+	switch {
+	case b >= '0' && b <= '9':
+		if len(vt.params) == 0 {
+			vt.params = append(vt.params, 0)
+		}
+		n := &vt.params[len(vt.params)-1]
+		if *n < 65535 {
+			*n = *n*10 + int(b-'0')
+		}
+	case b == ';' || b == ':':
+		if len(vt.params) == 0 {
+			vt.params = append(vt.params, 0)
+		}
+		vt.params = append(vt.params, 0)
+	case b >= '<' && b <= '?':
+		vt.private = b
+	case b >= 0x20 && b <= 0x2f:
+		vt.inter = append(vt.inter, b)
+	case b >= 0x40 && b <= 0x7e:
+		vt.state = stGround
+		if len(vt.inter) == 0 {
+			vt.csi(b)
+		}
+	case b == 0x1b:
+		vt.state = stEscape
+	case b < 0x20:
+		vt.control(b)
+	}
+}
+
+// param returns parameter i, or def if it is missing or zero
+func (vt *VT) param(i, def int) int {
+	if i < len(vt.params) && vt.params[i] != 0 {
+		return vt.params[i]
+	}
+	return def
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+func (vt *VT) csi(final byte) {
+	if vt.private == '?' {
+		switch final {
+		case 'h', 'l':
+			vt.setPrivateModes(final == 'h')
+		}
+		return
+	}
+	if vt.private != 0 {
+		if vt.private == '>' && final == 'c' && vt.respond != nil {
+			vt.respond([]byte("\x1b[>0;0;0c"))
+		}
+		return
+	}
+
+	n := vt.param(0, 1)
+	top, bottom := vt.margins()
+	switch final {
+	case '@':
+		vt.insertBlanks(n)
+	case 'A':
+		vt.moveTo(vt.y-n, vt.x, top, bottom)
+	case 'B', 'e':
+		vt.moveTo(vt.y+n, vt.x, top, bottom)
+	case 'C', 'a':
+		vt.moveTo(vt.y, vt.x+n, 0, vt.rows-1)
+	case 'D':
+		vt.moveTo(vt.y, vt.x-n, 0, vt.rows-1)
+	case 'E':
+		vt.moveTo(vt.y+n, 0, top, bottom)
+	case 'F':
+		vt.moveTo(vt.y-n, 0, top, bottom)
+	case 'G', '`':
+		vt.moveTo(vt.y, n-1, 0, vt.rows-1)
+	case 'H', 'f':
+		y, x := vt.param(0, 1)-1, vt.param(1, 1)-1
+		if vt.origin {
+			vt.moveTo(y+vt.top, x, vt.top, vt.bottom)
+		} else {
+			vt.moveTo(y, x, 0, vt.rows-1)
+		}
+	case 'I':
+		for ; n > 0; n-- {
+			vt.x = vt.nextTab(vt.x)
+		}
+	case 'J':
+		vt.eraseDisplay(vt.param(0, 0))
+	case 'K':
+		vt.eraseLine(vt.param(0, 0))
+	case 'L':
+		vt.insertLines(n)
+	case 'M':
+		vt.deleteLines(n)
+	case 'P':
+		vt.deleteChars(n)
+	case 'S':
+		vt.scrollUp(vt.top, vt.bottom, n)
+	case 'T':
+		vt.scrollDown(vt.top, vt.bottom, n)
+	case 'X':
+		vt.erase(vt.y, vt.x, vt.x+n)
+	case 'Z':
+		for ; n > 0 && vt.x > 0; n-- {
+			vt.x--
+			for vt.x > 0 && !vt.tabs[vt.x] {
+				vt.x--
+			}
+		}
+		vt.wrapPending = false
+	case 'b':
+		if vt.last != 0 {
+			for ; n > 0; n-- {
+				vt.print(vt.last)
+			}
+		}
+	case 'c':
+		if vt.respond != nil {
+			vt.respond([]byte("\x1b[?1;2c"))
+		}
+	case 'd':
+		if vt.origin {
+			vt.moveTo(n-1+vt.top, vt.x, vt.top, vt.bottom)
+		} else {
+			vt.moveTo(n-1, vt.x, 0, vt.rows-1)
+		}
+	case 'g':
+		switch vt.param(0, 0) {
+		case 0:
+			if vt.x < vt.cols {
+				vt.tabs[vt.x] = false
+			}
+		case 3:
+			for x := range vt.tabs {
+				vt.tabs[x] = false
+			}
+		}
+	case 'h', 'l':
+		for _, mode := range vt.params {
+			if mode == 4 {
+				vt.insert = final == 'h'
+			}
+		}
+	case 'm':
+		vt.sgr()
+	case 'n':
+		if vt.respond == nil {
+			return
+		}
+		switch vt.param(0, 0) {
+		case 5:
+			vt.respond([]byte("\x1b[0n"))
+		case 6:
+			y := vt.y
+			if vt.origin {
+				y -= vt.top
+			}
+			vt.respond([]byte(fmt.Sprintf("\x1b[%d;%dR", y+1, vt.x+1)))
+		}
+	case 'r':
+		top, bottom := vt.param(0, 1)-1, vt.param(1, vt.rows)-1
+		if top < bottom && bottom < vt.rows {
+			vt.top, vt.bottom = top, bottom
+			vt.y, vt.x = 0, 0
+			if vt.origin {
+				vt.y = vt.top
+			}
+			vt.wrapPending = false
+		}
+	case 's':
+		vt.saveCursor()
+	case 'u':
+		vt.restoreCursor()
+	}
+}
+
+// margins returns the rows relative cursor movement is confined to: the
+// scrolling region if the cursor is inside it, otherwise the whole screen
+func (vt *VT) margins() (top, bottom int) {
+	if vt.y >= vt.top && vt.y <= vt.bottom {
+		return vt.top, vt.bottom
+	}
+	return 0, vt.rows - 1
+}
+
+// moveTo moves the cursor, keeping it between rows top and bottom
+func (vt *VT) moveTo(y, x, top, bottom int) {
+	vt.y = clamp(y, top, bottom)
+	vt.x = clamp(x, 0, vt.cols-1)
+	vt.wrapPending = false
+}
+
+func (vt *VT) setPrivateModes(on bool) {
+	for _, mode := range vt.params {
+		switch mode {
+		case 1:
+			vt.appCursor = on
+		case 5:
+			// reverse video, which is turned on and off to flash
+			if on {
+				vt.flashes++
+			}
+		case 6:
+			vt.origin = on
+			vt.moveTo(0, 0, 0, vt.rows-1)
+			if on {
+				vt.y = vt.top
+			}
+		case 7:
+			vt.autowrap = on
+		case 25:
+			vt.visible = on
+		case 1000, 1002, 1003:
+			vt.mouse = on
+		case 1006:
+			vt.sgrMouse = on
+		case 47, 1047:
+			vt.switchScreen(on, false)
+		case 1048:
+			if on {
+				vt.saveCursor()
+			} else {
+				vt.restoreCursor()
+			}
+		case 1049:
+			if on {
+				vt.saveCursor()
+				vt.switchScreen(true, true)
+			} else {
+				vt.switchScreen(false, false)
+				vt.restoreCursor()
+			}
+		}
+	}
+}
+
+// switchScreen selects the alternate screen or returns to the main one
+func (vt *VT) switchScreen(alt, clear bool) {
+	if alt == vt.altActive {
+		return
+	}
+	vt.altActive = alt
+	vt.grid = vt.main
+	if alt {
+		vt.grid = vt.alt
+		if clear {
+			vt.eraseDisplay(2)
+		}
+	}
+}
+
+func (vt *VT) saveCursor() {
+	vt.saved = vt.cursor
+}
+
+func (vt *VT) restoreCursor() {
+	vt.cursor = vt.saved
+	vt.y = clamp(vt.y, 0, vt.rows-1)
+	vt.x = clamp(vt.x, 0, vt.cols-1)
+	vt.wrapPending = false
+}
+
+func (vt *VT) sgr() {
+	if len(vt.params) == 0 {
+		vt.params = append(vt.params, 0)
+	}
+	for i := 0; i < len(vt.params); i++ {
+		switch p := vt.params[i]; {
+		case p == 0:
+			vt.pen = blankCell
+		case p == 1:
+			vt.pen.Attr |= AttrBold
+		case p == 2:
+			vt.pen.Attr |= AttrDim
+		case p == 3:
+			vt.pen.Attr |= AttrItalic
+		case p == 4:
+			vt.pen.Attr |= AttrUnderline
+		case p == 5 || p == 6:
+			vt.pen.Attr |= AttrBlink
+		case p == 7:
+			vt.pen.Attr |= AttrReverse
+		case p == 8:
+			vt.pen.Attr |= AttrInvisible
+		case p == 9:
+			vt.pen.Attr |= AttrStrike
+		case p == 21 || p == 22:
+			vt.pen.Attr &^= AttrBold | AttrDim
+		case p == 23:
+			vt.pen.Attr &^= AttrItalic
+		case p == 24:
+			vt.pen.Attr &^= AttrUnderline
+		case p == 25:
+			vt.pen.Attr &^= AttrBlink
+		case p == 27:
+			vt.pen.Attr &^= AttrReverse
+		case p == 28:
+			vt.pen.Attr &^= AttrInvisible
+		case p == 29:
+			vt.pen.Attr &^= AttrStrike
+		case p >= 30 && p <= 37:
+			vt.pen.Fg = Color(p - 30)
+		case p == 38:
+			vt.pen.Fg, i = vt.extendedColor(i)
+		case p == 39:
+			vt.pen.Fg = DefaultColor
+		case p >= 40 && p <= 47:
+			vt.pen.Bg = Color(p - 40)
+		case p == 48:
+			vt.pen.Bg, i = vt.extendedColor(i)
+		case p == 49:
+			vt.pen.Bg = DefaultColor
+		case p >= 90 && p <= 97:
+			vt.pen.Fg = Color(p - 90 + 8)
+		case p >= 100 && p <= 107:
+			vt.pen.Bg = Color(p - 100 + 8)
+		}
+	}
+}
+
+// extendedColor parses the 256 or 24-bit color following parameter i and
+// returns it with the index of the last parameter used
+func (vt *VT) extendedColor(i int) (Color, int) {
+	switch vt.param(i+1, 0) {
+	case 5:
+		return Color(clamp(vt.param(i+2, 0), 0, 255)), i + 2
+	case 2:
+		return RGB(uint8(vt.param(i+2, 0)), uint8(vt.param(i+3, 0)),
+			uint8(vt.param(i+4, 0))), i + 4
+	}
+	return DefaultColor, len(vt.params)
+}
+
+func (vt *VT) print(r rune) {
+	if vt.g[vt.gl] == '0' {
+		if g, ok := decGraphics[r]; ok {
+			r = g
+		}
+	}
+	n := width.Rune(r)
+	switch {
+	case n == 0:
+		vt.combine(r)
+		return
+	case n == 2 && vt.cols < 2:
+		r, n = ' ', 1
+	}
+	if vt.wrapPending && vt.autowrap {
+		vt.x = 0
+		vt.index()
+	}
+	vt.wrapPending = false
+	if n == 2 && vt.x == vt.cols-1 {
+		// a wide character does not fit in the last column
+		if vt.autowrap {
+			vt.set(vt.y, vt.x, vt.blank())
+			vt.x = 0
+			vt.index()
+		} else {
+			vt.x--
+		}
+	}
+	if vt.insert {
+		vt.insertBlanks(n)
+	}
+	cell := vt.pen
+	cell.Ch = r
+	vt.set(vt.y, vt.x, cell)
+	if n == 2 {
+		cell.Ch = 0
+		vt.set(vt.y, vt.x+1, cell)
+	}
+	vt.last = r
+	if vt.x+n >= vt.cols {
+		vt.x = vt.cols - 1
+		vt.wrapPending = vt.autowrap
+	} else {
+		vt.x += n
+	}
+}
+
+// set sets the cell at y, x. A wide character partly overwritten is
+// replaced by a blank
+func (vt *VT) set(y, x int, c Cell) {
+	row := vt.grid[y]
+	if row[x].Ch == 0 && c.Ch != 0 && x > 0 {
+		row[x-1].Ch, row[x-1].Comb = ' ', ""
+	}
+	if row[x].Ch != 0 && x+1 < vt.cols && row[x+1].Ch == 0 {
+		row[x+1].Ch = ' '
+	}
+	row[x] = c
+}
+
+// combine adds the combining character r to the last character printed
+func (vt *VT) combine(r rune) {
+	x := vt.x - 1
+	if vt.wrapPending {
+		x = vt.x
+	}
+	if x < 0 {
+		return
+	}
+	row := vt.grid[vt.y]
+	if row[x].Ch == 0 && x > 0 {
+		x--
+	}
+	row[x].Comb += string(r)
+}
+
+// blank returns an empty cell. Erased cells take the current background
+// color, as xterm does
+func (vt *VT) blank() Cell {
+	cell := blankCell
+	cell.Bg = vt.pen.Bg
+	return cell
+}
+
+// erase blanks columns from up to, but not including, to on row y
+func (vt *VT) erase(y, from, to int) {
+	from, to = clamp(from, 0, vt.cols), clamp(to, 0, vt.cols)
+	blank := vt.blank()
+	for x := from; x < to; x++ {
+		vt.grid[y][x] = blank
+	}
+	vt.wrapPending = false
+}
+
+func (vt *VT) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		vt.erase(vt.y, vt.x, vt.cols)
+	case 1:
+		vt.erase(vt.y, 0, vt.x+1)
+	case 2:
+		vt.erase(vt.y, 0, vt.cols)
+	}
+}
+
+func (vt *VT) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		vt.eraseLine(0)
+		for y := vt.y + 1; y < vt.rows; y++ {
+			vt.erase(y, 0, vt.cols)
+		}
+	case 1:
+		for y := 0; y < vt.y; y++ {
+			vt.erase(y, 0, vt.cols)
+		}
+		vt.eraseLine(1)
+	case 2, 3:
+		for y := 0; y < vt.rows; y++ {
+			vt.erase(y, 0, vt.cols)
+		}
+	}
+}
+
+func (vt *VT) nextTab(x int) int {
+	for x++; x < vt.cols-1; x++ {
+		if vt.tabs[x] {
+			return x
+		}
+	}
+	return vt.cols - 1
+}
+
+// index moves the cursor down a line, scrolling if it is at the bottom of
+// the scrolling region
+func (vt *VT) index() {
+	vt.wrapPending = false
+	if vt.y == vt.bottom {
+		vt.scrollUp(vt.top, vt.bottom, 1)
+	} else if vt.y < vt.rows-1 {
+		vt.y++
+	}
+}
+
+// reverseIndex moves the cursor up a line, scrolling if it is at the top
+// of the scrolling region
+func (vt *VT) reverseIndex() {
+	vt.wrapPending = false
+	if vt.y == vt.top {
+		vt.scrollDown(vt.top, vt.bottom, 1)
+	} else if vt.y > 0 {
+		vt.y--
+	}
+}
+
+// scrollUp moves rows top to bottom up by n, blanking the rows uncovered
+func (vt *VT) scrollUp(top, bottom, n int) {
+	n = clamp(n, 0, bottom-top+1)
+	for y := top; y <= bottom; y++ {
+		if y+n <= bottom {
+			copy(vt.grid[y], vt.grid[y+n])
+		} else {
+			vt.erase(y, 0, vt.cols)
+		}
+	}
+}
+
+// scrollDown moves rows top to bottom down by n, blanking the rows
+// uncovered
+func (vt *VT) scrollDown(top, bottom, n int) {
+	n = clamp(n, 0, bottom-top+1)
+	for y := bottom; y >= top; y-- {
+		if y-n >= top {
+			copy(vt.grid[y], vt.grid[y-n])
+		} else {
+			vt.erase(y, 0, vt.cols)
+		}
+	}
+}
+
+func (vt *VT) insertLines(n int) {
+	if vt.y < vt.top || vt.y > vt.bottom {
+		return
+	}
+	vt.scrollDown(vt.y, vt.bottom, n)
+	vt.x = 0
+}
+
+func (vt *VT) deleteLines(n int) {
+	if vt.y < vt.top || vt.y > vt.bottom {
+		return
+	}
+	vt.scrollUp(vt.y, vt.bottom, n)
+	vt.x = 0
+}
+
+func (vt *VT) insertBlanks(n int) {
+	row := vt.grid[vt.y]
+	n = clamp(n, 0, vt.cols-vt.x)
+	copy(row[vt.x+n:], row[vt.x:])
+	vt.erase(vt.y, vt.x, vt.x+n)
+}
+
+func (vt *VT) deleteChars(n int) {
+	row := vt.grid[vt.y]
+	n = clamp(n, 0, vt.cols-vt.x)
+	copy(row[vt.x:], row[vt.x+n:])
+	vt.erase(vt.y, vt.cols-n, vt.cols)
+}
@@ -0,0 +1,120 @@
+package cursestest_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+func TestVTText(t *testing.T) {
+	vt := cursestest.NewVT(3, 10)
+	vt.Write([]byte("hello\r\nworld, wrapped\x1b[1;3H\x1b[1PX"))
+	want := "heXo\nworld, wra\npped"
+	if vt.String() != want {
+		t.Errorf("expected %q, got %q", want, vt.String())
+	}
+	if y, x, _ := vt.Cursor(); y != 0 || x != 3 {
+		t.Errorf("expected cursor at 0,3, got %d,%d", y, x)
+	}
+
+	vt.Write([]byte("\x1b[2;4H\x1b[K\x1b[3;1H\x1b[2@\x1b[1;1H\x1b[3X"))
+	want = "   o\nwor\n  pped"
+	if vt.String() != want {
+		t.Errorf("expected %q, got %q", want, vt.String())
+	}
+}
+
+func TestVTWideCharacters(t *testing.T) {
+	vt := cursestest.NewVT(3, 5)
+	vt.Write([]byte("日本x\r\nabcd語\x1b[3;4He\u0301"))
+	if got := vt.Line(0); got != "日本x" {
+		t.Errorf("expected 日本x, got %q", got)
+	}
+	if c := vt.Cell(0, 4); c.Ch != 'x' {
+		t.Errorf("expected x in column 4, got %q", c.Ch)
+	}
+	if c := vt.Cell(0, 1); c.Ch != 0 {
+		t.Errorf("expected the right half of a wide character, got %q", c.Ch)
+	}
+	// a wide character which does not fit wraps whole
+	if got := vt.Line(1); got != "abcd" {
+		t.Errorf("expected abcd, got %q", got)
+	}
+	if c := vt.Cell(2, 0); c.Ch != '語' {
+		t.Errorf("expected the wide character on the next line, got %q", c.Ch)
+	}
+	// and a combining character is drawn over the one before
+	if c := vt.Cell(2, 3); c.Ch != 'e' || c.Comb != "\u0301" {
+		t.Errorf("expected e with an accent, got %+v", c)
+	}
+	if y, x, _ := vt.Cursor(); y != 2 || x != 4 {
+		t.Errorf("expected the cursor at 2, 4, got %d, %d", y, x)
+	}
+
+	// overwriting half of a wide character blanks the other half
+	vt.Write([]byte("\x1b[1;2Hz"))
+	if got := vt.Line(0); got != " z本x" {
+		t.Errorf("expected the first character to be blanked, got %q", got)
+	}
+}
+
+func TestVTScrolling(t *testing.T) {
+	vt := cursestest.NewVT(4, 5)
+	vt.Write([]byte("a\r\nb\r\nc\r\nd"))
+	vt.Write([]byte("\x1b[2;3r\x1b[3;1H\n"))
+	if want := "a\nc\n\nd"; vt.String() != want {
+		t.Errorf("scroll region: expected %q, got %q", want, vt.String())
+	}
+	vt.Write([]byte("\x1b[r\x1b[2;1H\x1b[L"))
+	if want := "a\n\nc\n"; vt.String() != want {
+		t.Errorf("insert line: expected %q, got %q", want, vt.String())
+	}
+	vt.Write([]byte("\x1b[1;1H\x1b[2M"))
+	if want := "c\n\n\n"; vt.String() != want {
+		t.Errorf("delete line: expected %q, got %q", want, vt.String())
+	}
+}
+
+func TestVTAttributes(t *testing.T) {
+	vt := cursestest.NewVT(2, 10)
+	vt.Write([]byte("\x1b[1;4;31;48;5;200ma\x1b[22;38;2;1;2;3mb\x1b[mc"))
+	vt.Write([]byte("\x1b[44m\x1b[2;1H\x1b[K"))
+
+	tests := []struct {
+		y, x int
+		want cursestest.Cell
+	}{
+		{0, 0, cursestest.Cell{Ch: 'a', Attr: cursestest.AttrBold |
+			cursestest.AttrUnderline, Fg: 1, Bg: 200}},
+		{0, 1, cursestest.Cell{Ch: 'b', Attr: cursestest.AttrUnderline,
+			Fg: cursestest.RGB(1, 2, 3), Bg: 200}},
+		{0, 2, cursestest.Cell{Ch: 'c', Fg: cursestest.DefaultColor,
+			Bg: cursestest.DefaultColor}},
+		{1, 5, cursestest.Cell{Ch: ' ', Fg: cursestest.DefaultColor, Bg: 4}},
+	}
+	for _, test := range tests {
+		if got := vt.Cell(test.y, test.x); got != test.want {
+			t.Errorf("%d,%d: expected %+v, got %+v", test.y, test.x,
+				test.want, got)
+		}
+	}
+}
+
+func TestVTModes(t *testing.T) {
+	vt := cursestest.NewVT(2, 10)
+	vt.Write([]byte("main\x1b[?1049h\x1b[H\x1b(0lqk\x1b(B\x1b[?25l\x1b]0;title\x07"))
+	if vt.Line(0) != "┌─┐" || !vt.AltScreen() {
+		t.Errorf("expected box corner on alternate screen, got %q", vt.Line(0))
+	}
+	if _, _, visible := vt.Cursor(); visible {
+		t.Error("expected cursor to be hidden")
+	}
+	vt.Write([]byte("\x1b[?1049l\x1b[?25h"))
+	if vt.Line(0) != "main" || vt.AltScreen() {
+		t.Errorf("expected main screen to be restored, got %q", vt.Line(0))
+	}
+	if y, x, visible := vt.Cursor(); y != 0 || x != 4 || !visible {
+		t.Errorf("expected visible cursor at 0,4, got %d,%d %v", y, x,
+			visible)
+	}
+}
@@ -0,0 +1,245 @@
+// +build !windows purego
+
+package cursestest_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+// These tests run on both builds, so the pure-Go windows are held to what
+// ncurses does
+
+// startMemory starts a Memory screen of rows by cols, closed when the test
+// ends
+func startMemory(t *testing.T, rows, cols int) (*cursestest.Memory,
+	*goncurses.Window) {
+	mem := cursestest.NewMemory(rows, cols)
+	stdscr, err := mem.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mem.Close() })
+	return mem, stdscr
+}
+
+// windowText returns the text of row y of w with trailing blanks kept
+func windowText(w *goncurses.Window, y int) string {
+	_, cols := w.MaxYX()
+	s := make([]rune, cols)
+	for x := range s {
+		s[x] = rune(w.MoveInChar(y, x) & goncurses.A_CHARTEXT)
+	}
+	return string(s)
+}
+
+func TestMemoryScroll(t *testing.T) {
+	mem, stdscr := startMemory(t, 6, 20)
+	stdscr.Refresh()
+	win, err := goncurses.NewWindow(3, 10, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	for y, s := range []string{"one", "two", "three"} {
+		win.MovePrint(y, 0, s)
+	}
+
+	win.Scroll(1)
+	if got := windowText(win, 0); got != "one       " {
+		t.Errorf("expected no scrolling before ScrollOk, got %q", got)
+	}
+	win.ScrollOk(true)
+	win.Scroll(1)
+	if got := windowText(win, 0); got != "two       " {
+		t.Errorf("expected two on the first line, got %q", got)
+	}
+	if got := windowText(win, 2); got != "          " {
+		t.Errorf("expected a blank last line, got %q", got)
+	}
+	win.Scroll(-2)
+	if got := windowText(win, 2); got != "two       " {
+		t.Errorf("expected two on the last line, got %q", got)
+	}
+
+	// printing past the last line scrolls the window
+	win.Erase()
+	win.Print("a\nb\nc\nd")
+	win.Refresh()
+	want := []string{"", "  b", "  c", "  d", ""}
+	for y, w := range want {
+		if got := mem.Line(y); got != w {
+			t.Errorf("expected line %d to be %q, got %q", y, w, got)
+		}
+	}
+	if y, x := win.CursorYX(); y != 2 || x != 1 {
+		t.Errorf("expected the cursor at 2, 1, got %d, %d", y, x)
+	}
+}
+
+func TestMemoryWindowResize(t *testing.T) {
+	mem, stdscr := startMemory(t, 6, 20)
+	stdscr.Refresh()
+	win, err := goncurses.NewWindow(2, 5, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	win.MovePrint(0, 0, "abcde")
+	win.MovePrint(1, 3, "f")
+
+	win.Resize(3, 8)
+	if rows, cols := win.MaxYX(); rows != 3 || cols != 8 {
+		t.Fatalf("expected a 3x8 window, got %dx%d", rows, cols)
+	}
+	if got := windowText(win, 0); got != "abcde   " {
+		t.Errorf("expected the contents to be kept, got %q", got)
+	}
+	win.MovePrint(2, 0, "grown")
+	win.Refresh()
+	if got := mem.Line(3); got != " grown" {
+		t.Errorf("expected the new line to be shown, got %q", got)
+	}
+
+	win.Move(1, 4)
+	win.Resize(1, 3)
+	if rows, cols := win.MaxYX(); rows != 1 || cols != 3 {
+		t.Fatalf("expected a 1x3 window, got %dx%d", rows, cols)
+	}
+	if got := windowText(win, 0); got != "abc" {
+		t.Errorf("expected the contents to be cut, got %q", got)
+	}
+	if y, x := win.CursorYX(); y != 0 || x != 2 {
+		t.Errorf("expected the cursor inside the window, got %d, %d", y, x)
+	}
+}
+
+func TestMemoryCopy(t *testing.T) {
+	_, stdscr := startMemory(t, 6, 20)
+	stdscr.Refresh()
+	src, err := goncurses.NewWindow(2, 6, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Delete()
+	src.MovePrint(0, 0, "ab  cd")
+	src.MovePrint(1, 0, "efghij")
+
+	// dst overlaps the last four columns of src
+	newDst := func() *goncurses.Window {
+		dst, err := goncurses.NewWindow(2, 6, 0, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst.MovePrint(0, 0, "xxxxxx")
+		dst.MovePrint(1, 0, "yyyyyy")
+		return dst
+	}
+
+	dst := newDst()
+	if err := dst.Overlay(src); err != nil {
+		t.Fatal(err)
+	}
+	if got := windowText(dst, 0); got != "xxcdxx" {
+		t.Errorf("expected overlay to skip blanks, got %q", got)
+	}
+	if got := windowText(dst, 1); got != "ghijyy" {
+		t.Errorf("expected overlay to copy the second line, got %q", got)
+	}
+	dst.Delete()
+
+	dst = newDst()
+	if err := dst.Overwrite(src); err != nil {
+		t.Fatal(err)
+	}
+	if got := windowText(dst, 0); got != "  cdxx" {
+		t.Errorf("expected overwrite to copy blanks, got %q", got)
+	}
+	dst.Delete()
+
+	dst = newDst()
+	defer dst.Delete()
+	if err := dst.Copy(src, 1, 1, 0, 3, 0, 5, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := windowText(dst, 0); got != "xxxfgh" {
+		t.Errorf("expected fgh to be copied, got %q", got)
+	}
+	if err := dst.Copy(src, 1, 4, 0, 0, 0, 5, false); err == nil {
+		t.Error("expected copying from beyond src to fail")
+	}
+}
+
+func TestMemoryPad(t *testing.T) {
+	mem, stdscr := startMemory(t, 6, 20)
+	stdscr.Refresh()
+	pad, err := goncurses.NewPad(10, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pad.Delete()
+	for y := 0; y < 10; y++ {
+		pad.MovePrintf(y, 0, "row %d of the pad", y)
+	}
+
+	// pad rows 2 to 4 from column 4 are shown at 1, 1 to 3, 10
+	if err := pad.Refresh(2, 4, 1, 1, 3, 10); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", " 2 of the p", " 3 of the p", " 4 of the p", ""}
+	for y, w := range want {
+		if got := mem.Line(y); got != w {
+			t.Errorf("expected line %d to be %q, got %q", y, w, got)
+		}
+	}
+
+	// the area is trimmed to the end of the pad, and then must fit on
+	// the screen
+	if err := pad.Refresh(8, 0, 4, 0, 10, 15); err != nil {
+		t.Fatal(err)
+	}
+	if got := mem.Line(5); got != "row 9 of the pad" {
+		t.Errorf("expected row 9 on the last line, got %q", got)
+	}
+	if err := pad.Refresh(0, 0, 0, 0, 3, 25); err == nil {
+		t.Error("expected an area wider than the screen to fail")
+	}
+	if err := pad.Refresh(0, 0, 3, 3, 2, 2); err == nil {
+		t.Error("expected an empty area to fail")
+	}
+}
+
+func TestMemoryGetString(t *testing.T) {
+	mem, stdscr := startMemory(t, 4, 20)
+	goncurses.Echo(true)
+
+	mem.TypeString("hellx")
+	mem.PressKey(goncurses.KEY_BACKSPACE)
+	mem.TypeString("o world\n")
+	stdscr.Move(1, 2)
+	s, err := stdscr.GetString(7)
+	if err != nil || s != "hello w" {
+		t.Errorf("expected the string to stop at 7 characters, got %q, %v",
+			s, err)
+	}
+	if got := mem.Line(1); got != "  hello w" {
+		t.Errorf("expected the string to be echoed, got %q", got)
+	}
+	if mem.Beeps() == 0 {
+		t.Error("expected typing past the limit to beep")
+	}
+
+	goncurses.Echo(false)
+	mem.TypeString("z")
+	if k := stdscr.MoveGetChar(2, 5); k != 'z' {
+		t.Errorf("expected z, got %v", k)
+	}
+	if y, x := stdscr.CursorYX(); y != 2 || x != 5 {
+		t.Errorf("expected the cursor at 2, 5, got %d, %d", y, x)
+	}
+	if k := stdscr.GetChar(); k != 0 {
+		t.Errorf("expected no key from an empty queue, got %v", k)
+	}
+}
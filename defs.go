@@ -2,10 +2,19 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #cgo !darwin,!openbsd,!windows pkg-config: ncurses
 // #include <curses.h>
+// #ifndef BUTTON5_PRESSED
+// #define BUTTON5_PRESSED 0
+// #define BUTTON5_RELEASED 0
+// #define BUTTON5_CLICKED 0
+// #define BUTTON5_DOUBLE_CLICKED 0
+// #define BUTTON5_TRIPLE_CLICKED 0
+// #endif
 import "C"
 
 // Synconize options for Sync() function
@@ -265,6 +274,11 @@ const (
 	M_B4_CLICKED                 = C.BUTTON4_CLICKED
 	M_B4_DBL_CLICKED             = C.BUTTON4_DOUBLE_CLICKED
 	M_B4_TPL_CLICKED             = C.BUTTON4_TRIPLE_CLICKED
+	M_B5_PRESSED                 = C.BUTTON5_PRESSED // button 5, zero if unsupported
+	M_B5_RELEASED                = C.BUTTON5_RELEASED
+	M_B5_CLICKED                 = C.BUTTON5_CLICKED
+	M_B5_DBL_CLICKED             = C.BUTTON5_DOUBLE_CLICKED
+	M_B5_TPL_CLICKED             = C.BUTTON5_TRIPLE_CLICKED
 	M_CTRL                       = C.BUTTON_CTRL           // ctrl-click
 	M_SHIFT                      = C.BUTTON_SHIFT          // shift-click
 	M_POSITION                   = C.REPORT_MOUSE_POSITION // mouse moved
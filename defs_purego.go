@@ -0,0 +1,226 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+// The values below are those of ncurses so that programs behave the same
+// whichever way the package is built.
+
+// Synconize options for Sync() function
+const (
+	SYNC_NONE   = iota
+	SYNC_CURSOR // Sync cursor in all sub/derived windows
+	SYNC_DOWN   // Sync changes in all parent windows
+	SYNC_UP     // Sync change in all child windows
+)
+
+type Char uint32
+
+// Text attributes
+const (
+	A_NORMAL     Char = 0
+	A_STANDOUT        = 0x10000
+	A_UNDERLINE       = 0x20000
+	A_REVERSE         = 0x40000
+	A_BLINK           = 0x80000
+	A_DIM             = 0x100000
+	A_BOLD            = 0x200000
+	A_PROTECT         = 0x1000000
+	A_INVIS           = 0x800000
+	A_ALTCHARSET      = 0x400000
+	A_CHARTEXT        = 0xff
+)
+
+// Definitions for printed characters not found on most keyboards.
+const (
+	/* VT100 symbols */
+	ACS_ULCORNER Char = A_ALTCHARSET + 'l'
+	ACS_LLCORNER      = A_ALTCHARSET + 'm'
+	ACS_URCORNER      = A_ALTCHARSET + 'k'
+	ACS_LRCORNER      = A_ALTCHARSET + 'j'
+	ACS_LTEE          = A_ALTCHARSET + 't'
+	ACS_RTEE          = A_ALTCHARSET + 'u'
+	ACS_BTEE          = A_ALTCHARSET + 'v'
+	ACS_TTEE          = A_ALTCHARSET + 'w'
+	ACS_HLINE         = A_ALTCHARSET + 'q'
+	ACS_VLINE         = A_ALTCHARSET + 'x'
+	ACS_PLUS          = A_ALTCHARSET + 'n'
+	ACS_S1            = A_ALTCHARSET + 'o'
+	ACS_S9            = A_ALTCHARSET + 's'
+	ACS_DIAMOND       = A_ALTCHARSET + '`'
+	ACS_CKBOARD       = A_ALTCHARSET + 'a'
+	ACS_DEGREE        = A_ALTCHARSET + 'f'
+	ACS_PLMINUS       = A_ALTCHARSET + 'g'
+	ACS_BULLET        = A_ALTCHARSET + '~'
+
+	/* Teletype 5410v1 symbols */
+	ACS_LARROW  = A_ALTCHARSET + ','
+	ACS_RARROW  = A_ALTCHARSET + '+'
+	ACS_DARROW  = A_ALTCHARSET + '.'
+	ACS_UARROW  = A_ALTCHARSET + '-'
+	ACS_BOARD   = A_ALTCHARSET + 'h'
+	ACS_LANTERN = A_ALTCHARSET + 'i'
+	ACS_BLOCK   = A_ALTCHARSET + '0'
+
+	/* Undocumented, not well supported */
+	ACS_S3       = A_ALTCHARSET + 'p'
+	ACS_S7       = A_ALTCHARSET + 'r'
+	ACS_LEQUAL   = A_ALTCHARSET + 'y'
+	ACS_GEQUAL   = A_ALTCHARSET + 'z'
+	ACS_PI       = A_ALTCHARSET + '{'
+	ACS_NEQUAL   = A_ALTCHARSET + '|'
+	ACS_STERLING = A_ALTCHARSET + '}'
+)
+
+// Colors available to ncurses. Combine these with the dim/bold attributes
+// for bright/dark versions of each color. These colors can be used for
+// both background and foreground colors.
+const (
+	C_BLACK   int16 = 0
+	C_BLUE          = 4
+	C_CYAN          = 6
+	C_GREEN         = 2
+	C_MAGENTA       = 5
+	C_RED           = 1
+	C_WHITE         = 7
+	C_YELLOW        = 3
+)
+
+type Key int
+
+const (
+	KEY_TAB       Key = 9         // tab
+	KEY_RETURN        = 10        // enter key vs. KEY_ENTER
+	KEY_ESC           = 27        // esc key
+	KEY_DOWN          = 0402      // down arrow key
+	KEY_UP            = 0403      // up arrow key
+	KEY_LEFT          = 0404      // left arrow key
+	KEY_RIGHT         = 0405      // right arrow key
+	KEY_HOME          = 0406      // home key
+	KEY_BACKSPACE     = 0407      // backpace
+	KEY_F1            = 0410 + 1  // F1 key
+	KEY_F2            = 0410 + 2  // F2 key
+	KEY_F3            = 0410 + 3  // F3 key
+	KEY_F4            = 0410 + 4  // F4 key
+	KEY_F5            = 0410 + 5  // F5 key
+	KEY_F6            = 0410 + 6  // F6 key
+	KEY_F7            = 0410 + 7  // F7 key
+	KEY_F8            = 0410 + 8  // F8 key
+	KEY_F9            = 0410 + 9  // F9 key
+	KEY_F10           = 0410 + 10 // F10 key
+	KEY_F11           = 0410 + 11 // F11 key
+	KEY_F12           = 0410 + 12 // F12 key
+	KEY_DL            = 0510      // delete-line key
+	KEY_IL            = 0511      // insert-line key
+	KEY_DC            = 0512      // delete-character key
+	KEY_IC            = 0513      // insert-character key
+	KEY_EIC           = 0514      // sent by rmir or smir in insert mode
+	KEY_CLEAR         = 0515      // clear-screen or erase key
+	KEY_EOS           = 0516      // clear-to-end-of-screen key
+	KEY_EOL           = 0517      // clear-to-end-of-line key
+	KEY_SF            = 0520      // scroll-forward key
+	KEY_SR            = 0521      // scroll-backward key
+	KEY_PAGEDOWN      = 0522      // page-down key (next-page)
+	KEY_PAGEUP        = 0523      // page-up key (prev-page)
+	KEY_STAB          = 0524      // set-tab key
+	KEY_CTAB          = 0525      // clear-tab key
+	KEY_CATAB         = 0526      // clear-all-tabs key
+	KEY_ENTER         = 0527      // enter/send key
+	KEY_PRINT         = 0532      // print key
+	KEY_LL            = 0533      // lower-left key (home down)
+	KEY_A1            = 0534      // upper left of keypad
+	KEY_A3            = 0535      // upper right of keypad
+	KEY_B2            = 0536      // center of keypad
+	KEY_C1            = 0537      // lower left of keypad
+	KEY_C3            = 0540      // lower right of keypad
+	KEY_BTAB          = 0541      // back-tab key
+	KEY_BEG           = 0542      // begin key
+	KEY_CANCEL        = 0543      // cancel key
+	KEY_CLOSE         = 0544      // close key
+	KEY_COMMAND       = 0545      // command key
+	KEY_COPY          = 0546      // copy key
+	KEY_CREATE        = 0547      // create key
+	KEY_END           = 0550      // end key
+	KEY_EXIT          = 0551      // exit key
+	KEY_FIND          = 0552      // find key
+	KEY_HELP          = 0553      // help key
+	KEY_MARK          = 0554      // mark key
+	KEY_MESSAGE       = 0555      // message key
+	KEY_MOVE          = 0556      // move key
+	KEY_NEXT          = 0557      // next key
+	KEY_OPEN          = 0560      // open key
+	KEY_OPTIONS       = 0561      // options key
+	KEY_PREVIOUS      = 0562      // previous key
+	KEY_REDO          = 0563      // redo key
+	KEY_REFERENCE     = 0564      // reference key
+	KEY_REFRESH       = 0565      // refresh key
+	KEY_REPLACE       = 0566      // replace key
+	KEY_RESTART       = 0567      // restart key
+	KEY_RESUME        = 0570      // resume key
+	KEY_SAVE          = 0571      // save key
+	KEY_SBEG          = 0572      // shifted begin key
+	KEY_SCANCEL       = 0573      // shifted cancel key
+	KEY_SCOMMAND      = 0574      // shifted command key
+	KEY_SCOPY         = 0575      // shifted copy key
+	KEY_SCREATE       = 0576      // shifted create key
+	KEY_SDC           = 0577      // shifted delete-character key
+	KEY_SDL           = 0600      // shifted delete-line key
+	KEY_SELECT        = 0601      // select key
+	KEY_SEND          = 0602      // shifted end key
+	KEY_SEOL          = 0603      // shifted clear-to-end-of-line key
+	KEY_SEXIT         = 0604      // shifted exit key
+	KEY_SFIND         = 0605      // shifted find key
+	KEY_SHELP         = 0606      // shifted help key
+	KEY_SHOME         = 0607      // shifted home key
+	KEY_SIC           = 0610      // shifted insert-character key
+	KEY_SLEFT         = 0611      // shifted left-arrow key
+	KEY_SMESSAGE      = 0612      // shifted message key
+	KEY_SMOVE         = 0613      // shifted move key
+	KEY_SNEXT         = 0614      // shifted next key
+	KEY_SOPTIONS      = 0615      // shifted options key
+	KEY_SPREVIOUS     = 0616      // shifted previous key
+	KEY_SPRINT        = 0617      // shifted print key
+	KEY_SREDO         = 0620      // shifted redo key
+	KEY_SREPLACE      = 0621      // shifted replace key
+	KEY_SRIGHT        = 0622      // shifted right-arrow key
+	KEY_SRSUME        = 0623      // shifted resume key
+	KEY_SSAVE         = 0624      // shifted save key
+	KEY_SSUSPEND      = 0625      // shifted suspend key
+	KEY_SUNDO         = 0626      // shifted undo key
+	KEY_SUSPEND       = 0627      // suspend key
+	KEY_UNDO          = 0630      // undo key
+	KEY_MOUSE         = 0631      // any mouse event
+	KEY_RESIZE        = 0632      // Terminal resize event
+	KEY_MAX           = 0777      // Maximum key value is KEY_EVENT (0633)
+)
+
+var keyList = map[Key]string{
+	KEY_TAB:       "tab",
+	KEY_RETURN:    "enter", // On some keyboards?
+	KEY_DOWN:      "down",
+	KEY_UP:        "up",
+	KEY_LEFT:      "left",
+	KEY_RIGHT:     "right",
+	KEY_HOME:      "home",
+	KEY_BACKSPACE: "backspace",
+	KEY_ENTER:     "enter", // And not others?
+	KEY_F1:        "F1",
+	KEY_F2:        "F2",
+	KEY_F3:        "F3",
+	KEY_F4:        "F4",
+	KEY_F5:        "F5",
+	KEY_F6:        "F6",
+	KEY_F7:        "F7",
+	KEY_F8:        "F8",
+	KEY_F9:        "F9",
+	KEY_F10:       "F10",
+	KEY_F11:       "F11",
+	KEY_F12:       "F12",
+	KEY_MOUSE:     "mouse",
+	KEY_PAGEUP:    "page up",
+	KEY_PAGEDOWN:  "page down",
+	KEY_ESC:       "esc",
+}
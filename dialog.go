@@ -0,0 +1,391 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+// #include <curses.h>
+import "C"
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// dialogMaxWidth limits how wide a dialog grows to fit its text before
+// the text is wrapped
+const dialogMaxWidth = 60
+
+// dialog is a bordered window on its own panel, centred on the screen
+type dialog struct {
+	win    *Window
+	panel  *Panel
+	cursor C.int
+	echo   bool
+}
+
+// openDialog creates a dialog of h rows and w columns, shrunk to fit the
+// screen if necessary, with title printed on its top border. cursor sets
+// the cursor visibility while the dialog is open. Echo is turned off until
+// the dialog is closed since echoed keys would be drawn over the dialog
+// and, in a Prompt, mixed into the text being edited
+func openDialog(title string, h, w int, cursor int) (*dialog, error) {
+	rows, cols := StdScr().MaxYX()
+	if h > rows {
+		h = rows
+	}
+	if w > cols {
+		w = cols
+	}
+	win, err := NewWindow(h, w, (rows-h)/2, (cols-w)/2)
+	if err != nil {
+		return nil, err
+	}
+	win.Keypad(true)
+	win.Box(0, 0)
+	if title != "" {
+		title = truncate(" "+title+" ", w-4)
+		win.MovePrint(0, 2, title)
+	}
+
+	panel := NewPanel(win)
+	if panel == nil {
+		win.Delete()
+		return nil, errors.New("Failed to create panel")
+	}
+	d := &dialog{win: win, panel: panel, echo: echoing()}
+	Echo(false)
+	d.cursor = C.curs_set(C.int(cursor))
+	return d, nil
+}
+
+// show draws the panel stack with the dialog on top
+func (d *dialog) show() {
+	UpdatePanels()
+	Update()
+}
+
+// close removes the dialog, redrawing whatever it covered
+func (d *dialog) close() {
+	d.panel.Delete()
+	d.win.Delete()
+	if d.cursor != C.ERR {
+		C.curs_set(d.cursor)
+	}
+	if d.echo {
+		Echo(true)
+	}
+	UpdatePanels()
+	Update()
+}
+
+// buttons draws labels centred on row with the one at index focus
+// highlighted
+func (d *dialog) buttons(row int, labels []string, focus int) {
+	_, w := d.win.MaxYX()
+	width := 0
+	for _, l := range labels {
+		width += stringWidth(l) + 5
+	}
+	x := (w - width + 1) / 2
+	for i, l := range labels {
+		if i == focus {
+			d.win.AttrOn(A_REVERSE)
+		}
+		d.win.MovePrint(row, x, "< "+l+" >")
+		if i == focus {
+			d.win.AttrOff(A_REVERSE)
+		}
+		x += stringWidth(l) + 5
+	}
+}
+
+// truncate returns the longest leading part of s no more than width
+// columns wide
+func truncate(s string, width int) string {
+	w := 0
+	for i, r := range s {
+		w += runeWidth(r)
+		if w > width {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// wrapText breaks s into lines no wider than width columns, splitting at
+// spaces where possible. Newlines in s are kept
+func wrapText(s string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for stringWidth(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				head := truncate(word, width)
+				if head == "" {
+					// a character wider than the line gets one to itself
+					_, n := utf8.DecodeRuneInString(word)
+					head = word[:n]
+				}
+				lines = append(lines, head)
+				word = word[len(head):]
+			}
+			switch {
+			case word == "":
+			case line == "":
+				line = word
+			case stringWidth(line)+1+stringWidth(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// dialogWidth returns the width of a dialog needed to show lines, a title
+// and content at least min columns wide, capped at dialogMaxWidth plus the
+// border
+func dialogWidth(title string, lines []string, min int) int {
+	w := min
+	if tw := stringWidth(title) + 2; tw > w {
+		w = tw
+	}
+	for _, l := range lines {
+		if lw := stringWidth(l); lw > w {
+			w = lw
+		}
+	}
+	if w > dialogMaxWidth {
+		w = dialogMaxWidth
+	}
+	return w + 4
+}
+
+// textWidth returns the width text in a dialog is wrapped to
+func textWidth() int {
+	_, cols := StdScr().MaxYX()
+	if cols-4 < dialogMaxWidth {
+		return cols - 4
+	}
+	return dialogMaxWidth
+}
+
+// MessageBox shows msg in a dialog until the user dismisses it with Enter,
+// Space or Escape. Long lines are wrapped to fit the screen.
+func MessageBox(title, msg string) error {
+	lines := wrapText(msg, textWidth())
+	d, err := openDialog(title, len(lines)+4, dialogWidth(title, lines, 8),
+		0)
+	if err != nil {
+		return err
+	}
+	defer d.close()
+
+	for i, l := range lines {
+		d.win.MovePrint(i+1, 2, l)
+	}
+	d.buttons(len(lines)+2, []string{"OK"}, 0)
+	for {
+		d.show()
+		switch d.win.GetChar() {
+		case KEY_RETURN, KEY_ENTER, KEY_ESC, ' ':
+			return nil
+		}
+	}
+}
+
+// Confirm asks a yes or no question. The arrow and tab keys move between
+// the Yes and No buttons and Enter picks one; 'y' and 'n' answer directly.
+// Escape returns ErrCancelled.
+func Confirm(title, question string) (bool, error) {
+	lines := wrapText(question, textWidth())
+	d, err := openDialog(title, len(lines)+4, dialogWidth(title, lines, 16),
+		0)
+	if err != nil {
+		return false, err
+	}
+	defer d.close()
+
+	for i, l := range lines {
+		d.win.MovePrint(i+1, 2, l)
+	}
+	yes := true
+	for {
+		focus := 1
+		if yes {
+			focus = 0
+		}
+		d.buttons(len(lines)+2, []string{"Yes", "No"}, focus)
+		d.show()
+		switch d.win.GetChar() {
+		case KEY_LEFT, KEY_RIGHT, KEY_TAB, KEY_BTAB:
+			yes = !yes
+		case 'y', 'Y':
+			return true, nil
+		case 'n', 'N':
+			return false, nil
+		case KEY_RETURN, KEY_ENTER:
+			return yes, nil
+		case KEY_ESC:
+			return false, ErrCancelled
+		}
+	}
+}
+
+// Prompt asks for a line of text, which is edited with the keys in
+// DefaultFormKeymap starting from initial. Enter submits the text, with
+// trailing blanks removed. If validate is not nil it is called first and
+// any error it returns is shown in the dialog while the user corrects the
+// text. Escape returns ErrCancelled.
+func Prompt(title, label, initial string, validate func(string) error) (
+	string, error) {
+	lines := wrapText(label, textWidth())
+	w := dialogWidth(title, lines, 30)
+	d, err := openDialog(title, len(lines)+5, w, 1)
+	if err != nil {
+		return "", err
+	}
+	defer d.close()
+	for i, l := range lines {
+		d.win.MovePrint(i+1, 2, l)
+	}
+	row := len(lines) + 1
+	_, w = d.win.MaxYX()
+
+	field, err := NewField(1, int32(w-4), 0, 0, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	fields := []*Field{field, nil}
+	field.SetBackground(A_UNDERLINE)
+	field.Options(FO_AUTOSKIP|FO_STATIC, false)
+	field.SetBuffer(initial)
+
+	form, err := NewForm(fields)
+	if err != nil {
+		field.Free()
+		return "", err
+	}
+	sub := d.win.Derived(1, w-4, row, 2)
+	form.SetWindow(d.win)
+	form.SetSub(sub)
+	defer func() {
+		form.UnPost()
+		form.Free()
+		fields[0].Free()
+		sub.Delete()
+	}()
+	if err := form.Post(); err != nil {
+		return "", err
+	}
+	form.Driver(REQ_END_LINE)
+
+	for {
+		form.PositionCursor()
+		d.show()
+		switch k := d.win.GetChar(); k {
+		case KEY_ESC:
+			return "", ErrCancelled
+		case KEY_RETURN, KEY_ENTER:
+			form.Driver(REQ_VALIDATION)
+			value := strings.TrimRight(field.Buffer(), " ")
+			if validate == nil {
+				return value, nil
+			}
+			err := validate(value)
+			if err == nil {
+				return value, nil
+			}
+			msg := truncate(err.Error(), w-4)
+			d.win.HLine(row+1, 2, ' ', w-4)
+			d.win.AttrOn(A_BOLD)
+			d.win.MovePrint(row+1, 2, msg)
+			d.win.AttrOff(A_BOLD)
+		default:
+			form.HandleKey(k)
+		}
+	}
+}
+
+// Choose shows options in a list and returns the index of the one picked
+// with Enter or a double click. The list scrolls if it does not fit on the
+// screen. Escape returns ErrCancelled.
+func Choose(title string, options []string) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("No options to choose from")
+	}
+	rows, _ := StdScr().MaxYX()
+	h := len(options)
+	if h > rows-2 {
+		h = rows - 2
+	}
+	w := dialogWidth(title, options, 10) + 2
+	d, err := openDialog(title, h+2, w, 0)
+	if err != nil {
+		return -1, err
+	}
+	defer d.close()
+	h, w = d.win.MaxYX()
+
+	items := make([]*MenuItem, len(options))
+	for i, opt := range options {
+		items[i], err = NewItem(opt, "")
+		if err != nil {
+			for _, item := range items[:i] {
+				item.Free()
+			}
+			return -1, err
+		}
+	}
+	menu, err := NewMenu(items)
+	if err != nil {
+		for _, item := range items {
+			item.Free()
+		}
+		return -1, err
+	}
+	sub := d.win.Derived(h-2, w-4, 1, 2)
+	defer func() {
+		menu.UnPost()
+		menu.Free()
+		for _, item := range items {
+			item.Free()
+		}
+		sub.Delete()
+	}()
+	menu.SetWindow(d.win)
+	menu.SubWindow(sub)
+	menu.Format(h-2, 1)
+	menu.Mark("> ")
+	if err := menu.Post(); err != nil {
+		return -1, err
+	}
+
+	chosen := -1
+	menu.OnActivate(func(item *MenuItem) {
+		chosen = item.Index()
+	})
+	for chosen < 0 {
+		d.show()
+		switch k := d.win.GetChar(); k {
+		case KEY_ESC:
+			return -1, ErrCancelled
+		case KEY_RETURN, KEY_ENTER:
+			chosen = menu.Current(nil).Index()
+		default:
+			menu.HandleKey(k)
+		}
+	}
+	return chosen, nil
+}
@@ -0,0 +1,168 @@
+// +build !windows,!purego
+
+package goncurses_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+// newDialogTerminal returns a terminal ready for a dialog to read keys. The
+// keypad is turned on first so that keys are pressed in the mode the
+// dialog reads them in
+func newDialogTerminal(t *testing.T) *cursestest.Terminal {
+	term := newTerminal(t, 12, 40)
+	goncurses.SetEscDelay(10)
+	term.StdScr().Keypad(true)
+	term.StdScr().Refresh()
+	term.Sync()
+	return term
+}
+
+func TestConfirm(t *testing.T) {
+	term := newDialogTerminal(t)
+	defer term.Close()
+
+	term.PressKey(goncurses.KEY_RIGHT)
+	term.PressKey(goncurses.KEY_RETURN)
+	if yes, err := goncurses.Confirm("Test", "Continue?"); yes || err != nil {
+		t.Errorf("expected false, nil; got %v, %v", yes, err)
+	}
+	term.TypeString("y")
+	if yes, err := goncurses.Confirm("Test", "Continue?"); !yes || err != nil {
+		t.Errorf("expected true, nil; got %v, %v", yes, err)
+	}
+	term.PressKey(goncurses.KEY_ESC)
+	if _, err := goncurses.Confirm("Test", "Continue?"); err != goncurses.ErrCancelled {
+		t.Errorf("expected ErrCancelled, got %v", err)
+	}
+}
+
+func TestMessageBox(t *testing.T) {
+	term := newDialogTerminal(t)
+	defer term.Close()
+	stdscr := term.StdScr()
+	stdscr.MovePrint(0, 0, "underneath")
+	stdscr.Refresh()
+
+	// the dialog is drawn before the first key is read, so wait for its
+	// button, the last thing drawn, to appear and then dismiss it. Sync
+	// writes to the terminal, so the emulator is polled instead while
+	// MessageBox draws
+	done := make(chan error)
+	go func() {
+		done <- goncurses.MessageBox("Note", strings.Repeat("word ", 20))
+	}()
+	vt := term.VT()
+	deadline := time.Now().Add(cursestest.SyncTimeout)
+	for !strings.Contains(vt.String(), "< OK >") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the dialog to be drawn, screen is:\n%s", vt)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var lines []string
+	for y := 0; y < 12; y++ {
+		if line := vt.Line(y); strings.Contains(line, "word") {
+			lines = append(lines, strings.Trim(line, " |"))
+		}
+	}
+	term.TypeString(" ")
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// 20 words of 4 letters wrapped to the 36 columns left by the border
+	if len(lines) != 3 {
+		t.Errorf("expected the message on 3 lines, got %q", lines)
+	}
+	term.Sync()
+	if !strings.HasPrefix(term.Line(0), "underneath") {
+		t.Errorf("expected the screen to be restored, got %q", term.Line(0))
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	term := newDialogTerminal(t)
+	defer term.Close()
+
+	calls := 0
+	validate := func(s string) error {
+		calls++
+		if s != "abc" {
+			return errors.New("expected abc")
+		}
+		return nil
+	}
+	term.PressKey(goncurses.KEY_RETURN)
+	term.PressKey(goncurses.KEY_BACKSPACE)
+	term.TypeString("c")
+	term.PressKey(goncurses.KEY_RETURN)
+	s, err := goncurses.Prompt("Test", "Value", "abd", validate)
+	if s != "abc" || err != nil || calls != 2 {
+		t.Errorf("expected abc, nil after 2 calls; got %q, %v after %d", s,
+			err, calls)
+	}
+
+	term.PressKey(goncurses.KEY_ESC)
+	if _, err := goncurses.Prompt("Test", "Value", "", nil); err != goncurses.ErrCancelled {
+		t.Errorf("expected ErrCancelled, got %v", err)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	term := newDialogTerminal(t)
+	defer term.Close()
+
+	term.PressKey(goncurses.KEY_DOWN)
+	term.PressKey(goncurses.KEY_DOWN)
+	term.PressKey(goncurses.KEY_RETURN)
+	if i, err := goncurses.Choose("Test", []string{"a", "b", "c"}); i != 2 ||
+		err != nil {
+		t.Errorf("expected 2, nil; got %d, %v", i, err)
+	}
+	if _, err := goncurses.Choose("Test", nil); err == nil {
+		t.Error("expected an error choosing from no options")
+	}
+}
+
+func TestDialogEcho(t *testing.T) {
+	term := newDialogTerminal(t)
+	defer term.Close()
+	stdscr := term.StdScr()
+
+	// echo is off while the dialog reads keys and back on afterwards
+	term.TypeString("nx")
+	if yes, _ := goncurses.Confirm("Test", "Continue?"); yes {
+		t.Error("expected false")
+	}
+	stdscr.Move(0, 0)
+	if k := stdscr.GetChar(); k != 'x' {
+		t.Fatalf("expected x, got %d", k)
+	}
+	stdscr.Refresh()
+	term.Sync()
+	if line := term.Line(0); !strings.HasPrefix(line, "x") {
+		t.Errorf("expected x to be echoed, got %q", line)
+	}
+	if strings.Contains(term.String(), "n") {
+		t.Errorf("expected n not to be echoed, got\n%s", term.String())
+	}
+
+	// and stays off if it was off before
+	goncurses.Echo(false)
+	term.TypeString("ny")
+	goncurses.Confirm("Test", "Continue?")
+	stdscr.Move(1, 0)
+	stdscr.GetChar()
+	stdscr.Refresh()
+	term.Sync()
+	if line := term.Line(1); strings.TrimSpace(line) != "" {
+		t.Errorf("expected nothing to be echoed, got %q", line)
+	}
+}
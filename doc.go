@@ -32,6 +32,17 @@
 // concurrently. Failure to do so will result in unpredictable and
 // undefined behaviour in your program.
 //
+// Building with the purego tag (go build -tags purego) replaces ncurses
+// with a Go implementation of windows, pads and panels which needs neither
+// cgo nor a C library. Init then draws on the terminal named by $TERM,
+// reading its terminfo entry, while InitBackend draws on any Backend, such
+// as the in-memory screen of cursestest.Memory used by unit tests. Backend
+// and InitBackend only exist in the purego build; when building against
+// ncurses the library draws through ncurses itself. The form, menu, mouse
+// and soft label functions, dialogs, KeyOk, RipOffLine, NewTerm, Screen
+// and NewRecordedTerm are only available when building against ncurses;
+// Recorder and Replay work with either.
+//
 // The examples directory contains demonstrations of many of the capabilities
 // goncurses can provide.
 package goncurses
@@ -0,0 +1,59 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+/* This example walks through each of the ready made dialogs */
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+func main() {
+	stdscr, _ := gc.Init()
+	defer gc.End()
+
+	gc.Raw(true)
+	gc.Echo(false)
+	gc.SetEscDelay(50)
+	gc.MouseMask(gc.M_B1_CLICKED|gc.M_B1_DBL_CLICKED, nil)
+
+	stdscr.MovePrint(0, 0, "The screen behind the dialogs is restored as "+
+		"each one closes")
+	stdscr.Refresh()
+
+	name, err := gc.Prompt("Welcome", "What is your name?", "",
+		func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return errors.New("a name is required")
+			}
+			return nil
+		})
+	if err == gc.ErrCancelled {
+		return
+	}
+
+	colours := []string{"Red", "Green", "Blue", "Yellow", "Magenta", "Cyan"}
+	choice, err := gc.Choose("Favourite colour", colours)
+	if err == gc.ErrCancelled {
+		return
+	}
+
+	ok, err := gc.Confirm("Confirm", fmt.Sprintf("Is %s your favourite "+
+		"colour, %s?", colours[choice], name))
+	if err == gc.ErrCancelled {
+		return
+	}
+	msg := "I knew it!"
+	if !ok {
+		msg = "Oh well, it's a fine colour anyway."
+	}
+	gc.MessageBox("Result", msg)
+}
@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 /* This simple example demonstrates how to implement a form */
 package main
 
@@ -62,7 +64,7 @@ func main() {
 		case gc.KEY_BACKSPACE:
 			form.Driver(gc.REQ_CLR_FIELD)
 		default:
-			form.Driver(ch)
+			form.HandleKey(ch)
 		}
 		ch = stdscr.GetChar()
 	}
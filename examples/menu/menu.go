@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 /* This example demonstrates the use of the menu library */
 package main
 
@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 /* This example shows a scrolling menu similar to that found in the ncurses
  * examples from TLDP */
 package main
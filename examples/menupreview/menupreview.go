@@ -0,0 +1,72 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+/* This example uses menu hooks to preview the highlighted item in a
+ * separate window */
+package main
+
+import gc "github.com/rthornton128/goncurses"
+
+func main() {
+	stdscr, _ := gc.Init()
+	defer gc.End()
+
+	gc.Raw(true)
+	gc.Echo(false)
+	gc.Cursor(0)
+	stdscr.Keypad(true)
+
+	files := [][2]string{
+		{"main.go", "package main; the program's entry point"},
+		{"menu.go", "wrappers for the menu library"},
+		{"form.go", "wrappers for the form library"},
+		{"panel.go", "wrappers for the panel library"},
+		{"README.md", "an overview of the project"},
+	}
+	items := make([]*gc.MenuItem, len(files))
+	for i, f := range files {
+		items[i], _ = gc.NewItem(f[0], f[1])
+		defer items[i].Free()
+	}
+
+	menu, _ := gc.NewMenu(items)
+	defer menu.Free()
+	menu.Option(gc.O_SHOWDESC, false)
+
+	stdscr.MovePrint(0, 0, "Use up/down arrows to move; 'q' to exit")
+	stdscr.Refresh()
+
+	list, _ := gc.NewWindow(len(files)+2, 16, 2, 0)
+	list.Keypad(true)
+	list.Box(0, 0)
+	menu.SetWindow(list)
+	menu.SubWindow(list.Derived(len(files), 14, 1, 1))
+	defer list.Delete()
+
+	preview, _ := gc.NewWindow(len(files)+2, 44, 2, 17)
+	defer preview.Delete()
+
+	menu.OnItemEnter(func(item *gc.MenuItem) {
+		preview.Erase()
+		preview.Box(0, 0)
+		preview.MovePrint(1, 2, item.Name())
+		preview.MovePrint(3, 2, item.Description())
+		preview.NoutRefresh()
+	})
+	menu.Post()
+	defer menu.UnPost()
+
+	for {
+		list.NoutRefresh()
+		gc.Update()
+		ch := list.GetChar()
+		if ch == 'q' {
+			return
+		}
+		menu.Driver(gc.DriverActions[ch])
+	}
+}
@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 /* This example show a basic menu similar to that found in the ncurses
  * examples from TLDP */
 package main
@@ -18,6 +20,8 @@ func main() {
 	gc.Echo(false)
 	gc.Cursor(0)
 	stdscr.Keypad(true)
+	gc.MouseMask(gc.M_B1_CLICKED|gc.M_B1_DBL_CLICKED|gc.M_B4_PRESSED|
+		gc.M_B5_PRESSED, nil)
 
 	// build the menu items
 	menu_items := []string{
@@ -40,10 +44,14 @@ func main() {
 	defer menu.Free()
 
 	menu.Option(gc.O_ONEVALUE, false)
+	menu.OnActivate(func(item *gc.MenuItem) {
+		item.SetValue(!item.Value())
+	})
 
 	y, _ := stdscr.MaxYX()
-	stdscr.MovePrint(y-3, 0, "Use up/down arrows to move, spacebar to "+
-		"toggle and enter to print. 'q' to exit")
+	stdscr.MovePrint(y-3, 0, "Use up/down arrows to move, spacebar or "+
+		"a double click to toggle, 'a' to select all, 'c' to clear and enter to print. "+
+		"'q' to exit")
 	stdscr.Refresh()
 
 	menu.Post()
@@ -56,21 +64,21 @@ func main() {
 		switch ch {
 		case 'q':
 			return
-		case ' ':
-			menu.Driver(gc.REQ_TOGGLE)
+		case 'a':
+			menu.SelectAll()
+		case 'c':
+			menu.ClearSelection()
 		case gc.KEY_RETURN, gc.KEY_ENTER:
 			var list string
-			for _, item := range menu.Items() {
-				if item.Value() {
-					list += "\"" + item.Name() + "\" "
-				}
+			for _, item := range menu.Selected() {
+				list += "\"" + item.Name() + "\" "
 			}
 			stdscr.Move(20, 0)
 			stdscr.ClearToEOL()
 			stdscr.MovePrint(20, 0, list)
 			stdscr.Refresh()
 		default:
-			menu.Driver(gc.DriverActions[ch])
+			menu.HandleKey(ch)
 		}
 	}
 }
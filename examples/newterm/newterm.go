@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 // This example demonstrates how one might write to multiple terminals from a
 // single program or redirect output. In order to run the program you must
 // supply a single argument with is a path to a pseudo-terminal device.
@@ -0,0 +1,65 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+/* This example shows a growable, multi-line field which displays markers
+ * when its text is scrolled out of view */
+package main
+
+import (
+	"fmt"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+func main() {
+	stdscr, _ := gc.Init()
+	defer gc.End()
+
+	gc.Echo(false)
+	gc.CBreak(true)
+	stdscr.Keypad(true)
+
+	notes, _ := gc.NewField(4, 30, 2, 2, 0, 0)
+	defer notes.Free()
+	notes.SetBackground(gc.A_UNDERLINE)
+	notes.SetOptionsOff(gc.FO_STATIC)
+	notes.SetMax(50)
+
+	fields := []*gc.Field{notes}
+	form, _ := gc.NewForm(fields)
+	defer form.Free()
+
+	// edit by line rather than moving between fields
+	keys := gc.FormKeymap{}
+	for k, req := range gc.DefaultFormKeymap {
+		keys[k] = req
+	}
+	keys[gc.KEY_UP] = gc.REQ_PREV_LINE
+	keys[gc.KEY_DOWN] = gc.REQ_NEXT_LINE
+	keys[gc.KEY_RETURN] = gc.REQ_NEW_LINE
+	keys[gc.KEY_PAGEUP] = gc.REQ_SCR_BPAGE
+	keys[gc.KEY_PAGEDOWN] = gc.REQ_SCR_FPAGE
+	form.SetKeymap(keys)
+
+	form.Post()
+	defer form.UnPost()
+	stdscr.MovePrint(0, 0, "Type some notes. F1 to exit")
+	form.DrawScrollMarkers(notes)
+	stdscr.Refresh()
+
+	for ch := stdscr.GetChar(); ch != gc.KEY_F1; ch = stdscr.GetChar() {
+		form.HandleKey(ch)
+		form.DrawScrollMarkers(notes)
+
+		rows, cols, max, _ := notes.DynamicInfo()
+		stdscr.MovePrint(8, 0, fmt.Sprintf("buffer %dx%d (max %d)", rows,
+			cols, max))
+		stdscr.ClearToEOL()
+		form.PositionCursor()
+		stdscr.Refresh()
+	}
+}
@@ -0,0 +1,71 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+/* This example narrows a list of commands as the user types, in the style
+ * of an editor's command palette */
+package main
+
+import gc "github.com/rthornton128/goncurses"
+
+func main() {
+	stdscr, _ := gc.Init()
+	defer gc.End()
+
+	gc.Raw(true)
+	gc.Echo(false)
+	gc.Cursor(0)
+	stdscr.Keypad(true)
+
+	commands := []string{"Open File", "Save File", "Save All", "Close Window",
+		"Find in Files", "Go to Line", "Toggle Comment", "Format Document",
+		"Split Editor", "Quit"}
+	items := make([]*gc.MenuItem, len(commands))
+	for i, c := range commands {
+		items[i], _ = gc.NewItem(c, "")
+		defer items[i].Free()
+	}
+
+	menu, _ := gc.NewMenu(items)
+	defer menu.Free()
+
+	win, _ := gc.NewWindow(10, 30, 2, 0)
+	win.Keypad(true)
+	win.Box(0, 0)
+	menu.SetWindow(win)
+	menu.SubWindow(win.Derived(7, 28, 2, 1))
+	menu.Format(7, 1)
+	defer win.Delete()
+
+	stdscr.MovePrint(0, 0, "Type to filter, enter to run a command, "+
+		"Esc to exit")
+	stdscr.Refresh()
+
+	filter := gc.NewFuzzyFilter(menu)
+	menu.Post()
+	defer menu.UnPost()
+
+	for {
+		win.MovePrint(1, 1, "> "+filter.Query())
+		win.ClearToEOL()
+		win.Box(0, 0)
+		win.Refresh()
+		switch ch := win.GetChar(); ch {
+		case 27:
+			return
+		case gc.KEY_RETURN, gc.KEY_ENTER:
+			stdscr.Move(13, 0)
+			stdscr.ClearToEOL()
+			if item := menu.Current(nil); item != nil {
+				stdscr.MovePrint(13, 0, "Ran: "+item.Name())
+			}
+			stdscr.Refresh()
+			filter.Driver(gc.REQ_CLEAR_PATTERN)
+		default:
+			filter.HandleKey(ch)
+		}
+	}
+}
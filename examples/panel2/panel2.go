@@ -42,8 +42,6 @@ func main() {
 
 	}
 
-	active := 2
-
 	for {
 		gc.UpdatePanels()
 		gc.Update()
@@ -52,11 +50,8 @@ func main() {
 		case 'q':
 			return
 		case gc.KEY_TAB:
-			active += 1
-			if active > 2 {
-				active = 0
-			}
-			panels[active].Top()
+			// raising the bottom panel cycles through the stack
+			gc.BottomPanel().Top()
 		}
 	}
 }
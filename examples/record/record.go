@@ -0,0 +1,73 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+/* This example demonstrates recording a session to a file and playing it
+ * back. Run it with the name of a file to record to, type some text and
+ * press F1 to quit. Then run it with -replay and the same file to watch the
+ * session again. The recording can also be played with asciinema */
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+func main() {
+	replay := flag.Bool("replay", false, "play back the recording")
+	speed := flag.Float64("speed", 1, "playback speed, 0 for no delay")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: record [-replay] [-speed n] file")
+	}
+
+	if *replay {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err := gc.Replay(os.Stdout, f, *speed); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Create(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	rec := gc.NewRecorder(f)
+	rec.Title = "goncurses record example"
+
+	screen, err := gc.NewRecordedTerm("", os.Stdout, os.Stdin, rec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer screen.Delete()
+	defer screen.End()
+
+	gc.Raw(true)
+	gc.Echo(false)
+	stdscr := gc.StdScr()
+	stdscr.Keypad(true)
+	stdscr.MovePrint(0, 0, "Recording. Type something, F1 to quit")
+	stdscr.Move(2, 0)
+	for {
+		stdscr.Refresh()
+		k := stdscr.GetChar()
+		if k == gc.KEY_F1 {
+			break
+		}
+		if k >= ' ' && k < 127 {
+			stdscr.AddChar(gc.Char(k))
+		}
+	}
+}
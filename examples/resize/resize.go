@@ -1,5 +1,7 @@
 // goncurses - ncurses library for Go.
 
+// +build !purego
+
 /* This example demonstrates the ability to resize. Only one of detecting SIGWINCH or KEY_RESIZE
  * is strictly needed, but depending on the options ncurses was built with, one or the other may
  * work better. */
@@ -1,5 +1,7 @@
 // Inspired by https://stackoverflow.com/a/15784352/587091
 
+// +build !purego
+
 package main
 
 // #include <sys/ioctl.h>
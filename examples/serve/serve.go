@@ -0,0 +1,96 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+/* This example serves the same screen to several users at once over TCP.
+ * Connect with a raw terminal, for example:
+ *
+ *	socat -,raw,echo=0 tcp:localhost:7777
+ *
+ * Keys typed by any user are shown to all of them; 'q' disconnects. Since
+ * ncurses is not safe for use by multiple goroutines, every screen is
+ * driven from the main goroutine, which polls each in turn for input. Each
+ * screen is used through its own methods and windows, so there is no need
+ * to switch between them with Set */
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"time"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+type seat struct {
+	conn   net.Conn
+	screen *gc.Screen
+	stdscr *gc.Window
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:7777", "address to listen on")
+	term := flag.String("term", "xterm-256color", "terminal type of users")
+	flag.Parse()
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	conns := make(chan net.Conn)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				log.Fatal(err)
+			}
+			conns <- c
+		}
+	}()
+
+	var seats []*seat
+	typed := ""
+	for {
+		select {
+		case c := <-conns:
+			screen, err := gc.NewTermIO(*term, c, c, 24, 80)
+			if err != nil {
+				log.Print(err)
+				c.Close()
+				continue
+			}
+			screen.Raw(true)
+			screen.Echo(false)
+			stdscr := screen.StdScr()
+			stdscr.Timeout(0)
+			seats = append(seats, &seat{c, screen, stdscr})
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		for i := 0; i < len(seats); i++ {
+			s := seats[i]
+			k := s.stdscr.GetChar()
+			if k == 'q' {
+				s.screen.Close()
+				s.conn.Close()
+				seats = append(seats[:i], seats[i+1:]...)
+				i--
+				continue
+			}
+			if k >= ' ' && k < 127 {
+				typed += string(rune(k))
+			}
+		}
+		for _, s := range seats {
+			s.stdscr.Erase()
+			s.stdscr.MovePrintf(0, 0, "%d users connected, 'q' to leave",
+				len(seats))
+			s.stdscr.MovePrint(2, 0, typed)
+			s.stdscr.Refresh()
+		}
+	}
+}
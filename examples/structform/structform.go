@@ -0,0 +1,51 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+/* This example demonstrates generating a form from a tagged struct */
+package main
+
+import (
+	"fmt"
+
+	gc "github.com/rthornton128/goncurses"
+)
+
+type Config struct {
+	Host    string  `form:"label=Host,width=30,required"`
+	Port    int     `form:"label=Port,width=6,type=integer,min=1,max=65535"`
+	Timeout float64 `form:"label=Timeout (s),width=8,min=0"`
+	Verbose bool    `form:"label=Verbose"`
+}
+
+func main() {
+	stdscr, _ := gc.Init()
+	gc.Echo(false)
+	gc.CBreak(true)
+
+	cfg := Config{Host: "localhost", Port: 8080, Timeout: 2.5}
+
+	stdscr.MovePrint(0, 0, "Enter submits, Esc cancels")
+	stdscr.Refresh()
+	win, _ := gc.NewWindow(10, 60, 2, 2)
+
+	form, err := gc.NewStructForm(win, &cfg)
+	if err != nil {
+		gc.End()
+		fmt.Println(err)
+		return
+	}
+	err = form.Run()
+	form.Free()
+	win.Delete()
+	gc.End()
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", cfg)
+}
@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build !windows
+// +build !windows,!purego
 
 package goncurses
 
@@ -29,6 +29,32 @@ var DriverActions = map[Key]MenuDriverReq{
 	KEY_UP:       C.REQ_UP_ITEM,
 }
 
+// FormKeymap maps keys returned by GetChar to form driver requests. See
+// Form.HandleKey
+type FormKeymap map[Key]FormDriverReq
+
+// DefaultFormKeymap is the keymap used by forms which have not been given
+// one of their own via Form.SetKeymap. The Enter key is deliberately left
+// unmapped so that applications may decide whether it submits the form or
+// inserts a new line.
+var DefaultFormKeymap = FormKeymap{
+	KEY_LEFT:      REQ_PREV_CHAR,
+	KEY_RIGHT:     REQ_NEXT_CHAR,
+	KEY_UP:        REQ_PREV_FIELD,
+	KEY_DOWN:      REQ_NEXT_FIELD,
+	KEY_TAB:       REQ_NEXT_FIELD,
+	KEY_BTAB:      REQ_PREV_FIELD,
+	KEY_HOME:      REQ_BEG_LINE,
+	KEY_END:       REQ_END_LINE,
+	KEY_BACKSPACE: REQ_DEL_PREV,
+	127:           REQ_DEL_PREV, // DEL, sent by many terminals for backspace
+	8:             REQ_DEL_PREV, // ^H
+	KEY_DC:        REQ_DEL_CHAR,
+	KEY_IC:        REQ_TOGGLE_MODE,
+	KEY_PAGEDOWN:  REQ_NEXT_PAGE,
+	KEY_PAGEUP:    REQ_PREV_PAGE,
+}
+
 var errList = map[C.int]string{
 	C.E_SYSTEM_ERROR:    "System error occurred",
 	C.E_BAD_ARGUMENT:    "Incorrect or out-of-range argument",
@@ -46,6 +72,17 @@ var errList = map[C.int]string{
 	C.E_CURRENT:         "Current",
 }
 
+// errUnknownCommand is returned by HandleKey for keys which are neither
+// mapped to a request nor printable
+var errUnknownCommand = errors.New(errList[C.E_UNKNOWN_COMMAND])
+
+// printable reports whether k is a character which the form and menu
+// drivers take as input rather than as a request. Function keys, control
+// characters and DEL are not
+func printable(k Key) bool {
+	return k >= ' ' && k != 127 && k < C.KEY_MIN
+}
+
 func ncursesError(e error) error {
 	errno, ok := e.(syscall.Errno)
 	if int(errno) == C.OK {
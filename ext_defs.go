@@ -2,12 +2,22 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build !windows
+// +build !windows,!purego
 
 package goncurses
 
 // #include <form.h>
 // #include <menu.h>
+// #ifdef O_DYNAMIC_JUSTIFY
+// #define GONCURSES_MISSING_FIELD_OPTS 0
+// #else
+// #define O_DYNAMIC_JUSTIFY 0x0400
+// #define O_NO_LEFT_STRIP 0x0800
+// #define O_EDGE_INSERT_STAY 0x1000
+// #define O_INPUT_LIMIT 0x2000
+// #define GONCURSES_MISSING_FIELD_OPTS (O_DYNAMIC_JUSTIFY | O_NO_LEFT_STRIP | \
+// 	O_EDGE_INSERT_STAY | O_INPUT_LIMIT)
+// #endif
 import "C"
 
 // Form Driver Requests
@@ -71,19 +81,45 @@ const (
 	REQ_VALIDATION                 = C.REQ_VALIDATION   // validate field
 	REQ_NEXT_CHOICE                = C.REQ_NEXT_CHOICE  // display next field choice
 	REQ_PREV_CHOICE                = C.REQ_PREV_CHOICE  // display previous field choice
+
+	// REQ_TOGGLE_MODE is not a libform request. Form.Driver translates it
+	// into REQ_INS_MODE or REQ_OVL_MODE, whichever is not currently active
+	REQ_TOGGLE_MODE FormDriverReq = C.MAX_FORM_COMMAND + 1
+)
+
+// Field Options
+type FieldOptions C.Field_Options
+
+const (
+	FO_VISIBLE  FieldOptions = C.O_VISIBLE  // Field visibility
+	FO_ACTIVE   FieldOptions = C.O_ACTIVE   // Field is sensitive/accessable
+	FO_PUBLIC   FieldOptions = C.O_PUBLIC   // Typed characters are echoed
+	FO_EDIT     FieldOptions = C.O_EDIT     // Editable
+	FO_WRAP     FieldOptions = C.O_WRAP     // Line wrapping
+	FO_BLANK    FieldOptions = C.O_BLANK    // Clear on entry
+	FO_AUTOSKIP FieldOptions = C.O_AUTOSKIP // Skip to next field when current filled
+	FO_NULLOK   FieldOptions = C.O_NULLOK   // Blank ok
+	FO_STATIC   FieldOptions = C.O_STATIC   // Fixed size
+	FO_PASSOK   FieldOptions = C.O_PASSOK   // Field validation
+
+	// ncurses extensions; turning these on fails when built against a
+	// version of ncurses which lacks them
+	FO_DYNAMIC_JUSTIFY  FieldOptions = C.O_DYNAMIC_JUSTIFY  // Justify dynamic fields
+	FO_NO_LEFT_STRIP    FieldOptions = C.O_NO_LEFT_STRIP    // Keep leading blanks on validation
+	FO_EDGE_INSERT_STAY FieldOptions = C.O_EDGE_INSERT_STAY // Stay in field when inserting at its edge
+	FO_INPUT_LIMIT      FieldOptions = C.O_INPUT_LIMIT      // Limit dynamic field input to its maximum
 )
 
+// missingFieldOptions holds the field options which the version of ncurses
+// built against lacks
+const missingFieldOptions FieldOptions = C.GONCURSES_MISSING_FIELD_OPTS
+
+// Form Options
+type FormOptions C.Form_Options
+
 const (
-	FO_VISIBLE  = C.O_VISIBLE  // Field visibility
-	FO_ACTIVE   = C.O_ACTIVE   // Field is sensitive/accessable
-	FO_PUBLIC   = C.O_PUBLIC   // Typed characters are echoed
-	FO_EDIT     = C.O_EDIT     // Editable
-	FO_WRAP     = C.O_WRAP     // Line wrapping
-	FO_BLANK    = C.O_BLANK    // Clear on entry
-	FO_AUTOSKIP = C.O_AUTOSKIP // Skip to next field when current filled
-	FO_NULLOK   = C.O_NULLOK   // Blank ok
-	FO_STATIC   = C.O_STATIC   // Fixed size
-	FO_PASSOK   = C.O_PASSOK   // Field validation
+	O_NL_OVERLOAD FormOptions = C.O_NL_OVERLOAD // New line at end of field moves to next field
+	O_BS_OVERLOAD FormOptions = C.O_BS_OVERLOAD // Backspace at start of field moves to previous field
 )
 
 // Menu Driver Requests
@@ -110,14 +146,18 @@ const (
 )
 
 // Menu Options
+type MenuOptions C.Menu_Options
+
 const (
-	O_ONEVALUE   = C.O_ONEVALUE   // Only one item can be selected
-	O_SHOWDESC   = C.O_SHOWDESC   // Display item descriptions
-	O_ROWMAJOR   = C.O_ROWMAJOR   // Display in row-major order
-	O_IGNORECASE = C.O_IGNORECASE // Ignore case when pattern-matching
-	O_SHOWMATCH  = C.O_SHOWMATCH  // Move cursor to item when pattern-matching
-	O_NONCYCLIC  = C.O_NONCYCLIC  // Don't wrap next/prev item
+	O_ONEVALUE   MenuOptions = C.O_ONEVALUE   // Only one item can be selected
+	O_SHOWDESC   MenuOptions = C.O_SHOWDESC   // Display item descriptions
+	O_ROWMAJOR   MenuOptions = C.O_ROWMAJOR   // Display in row-major order
+	O_IGNORECASE MenuOptions = C.O_IGNORECASE // Ignore case when pattern-matching
+	O_SHOWMATCH  MenuOptions = C.O_SHOWMATCH  // Move cursor to item when pattern-matching
+	O_NONCYCLIC  MenuOptions = C.O_NONCYCLIC  // Don't wrap next/prev item
 )
 
 // Menu Item Options
-const O_SELECTABLE = C.O_SELECTABLE
+type ItemOptions C.Item_Options
+
+const O_SELECTABLE ItemOptions = C.O_SELECTABLE
@@ -2,17 +2,67 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build !windows
+// +build !windows,!purego
 
 package goncurses
 
-// #cgo !darwin,!openbsd pkg-config: form
-// #cgo darwin openbsd LDFLAGS: -lform
-// #include <form.h>
-// #include <stdlib.h>
+/*
+#cgo !darwin,!openbsd pkg-config: form
+#cgo darwin openbsd LDFLAGS: -lform
+#include <form.h>
+#include <stdlib.h>
+
+static FIELD* form_field_at(FIELD** flist, int i) {
+	return flist[i];
+}
+
+static FIELD** goncurses_new_fields(int n) {
+	return calloc(n + 1, sizeof(FIELD *));
+}
+
+static void goncurses_set_field_at(FIELD** flist, int i, FIELD* field) {
+	flist[i] = field;
+}
+
+static char** goncurses_new_strings(int n) {
+	return calloc(n + 1, sizeof(char *));
+}
+
+static void goncurses_set_string_at(char** list, int i, char* s) {
+	list[i] = s;
+}
+
+// set_field_type is variadic, which cgo is unable to call directly
+static int goncurses_type_alnum(FIELD *f, int width) {
+	return set_field_type(f, TYPE_ALNUM, width);
+}
+static int goncurses_type_alpha(FIELD *f, int width) {
+	return set_field_type(f, TYPE_ALPHA, width);
+}
+static int goncurses_type_enum(FIELD *f, char **list, int ccase, int uniq) {
+	return set_field_type(f, TYPE_ENUM, list, ccase, uniq);
+}
+static int goncurses_type_integer(FIELD *f, int prec, long min, long max) {
+	return set_field_type(f, TYPE_INTEGER, prec, min, max);
+}
+static int goncurses_type_ipv4(FIELD *f) {
+	return set_field_type(f, TYPE_IPV4);
+}
+static int goncurses_type_none(FIELD *f) {
+	return set_field_type(f, NULL);
+}
+static int goncurses_type_numeric(FIELD *f, int prec, double min, double max) {
+	return set_field_type(f, TYPE_NUMERIC, prec, min, max);
+}
+static int goncurses_type_regexp(FIELD *f, char *re) {
+	return set_field_type(f, TYPE_REGEXP, re);
+}
+*/
 import "C"
 
 import (
+	"errors"
+	"regexp"
 	"syscall"
 	"unsafe"
 )
@@ -23,10 +73,29 @@ type Form struct {
 	form *C.FORM
 }
 
+// formFields holds the field array handed to libform for each form. It is
+// kept here, where every copy of a Form sees it, so that a copy calling
+// SetFields or Free does not leave the others with an array already freed.
+// Entries are removed when the form is freed
+var formFields = map[*C.FORM]**C.FIELD{}
+
+// overlayForms holds the forms in overlay mode. libform offers no way to
+// query the mode so it is recorded here, where every copy of a Form sees
+// it, as REQ_INS_MODE and REQ_OVL_MODE succeed. Entries are removed when
+// the form is freed
+var overlayForms = map[*C.FORM]bool{}
+
+// formKeymaps holds the keymaps set with SetKeymap, where every copy of a
+// Form sees them. Entries are removed when the form is freed
+var formKeymaps = map[*C.FORM]FormKeymap{}
+
 func NewField(h, w, tr, lc, oscr, nbuf int32) (*Field, error) {
 	f, err := C.new_field(C.int(h), C.int(w), C.int(tr), C.int(lc),
 		C.int(oscr), C.int(nbuf))
-	return (*Field)(f), ncursesError(err)
+	if f == nil {
+		return nil, ncursesError(err)
+	}
+	return (*Field)(f), nil
 }
 
 // Background returns the field's background character attributes
@@ -43,6 +112,11 @@ func (f *Field) Buffer() string {
 	return C.GoString(str)
 }
 
+// CurrentOptions returns all of the options currently set on the field
+func (f *Field) CurrentOptions() FieldOptions {
+	return FieldOptions(C.field_opts((*C.FIELD)(f)))
+}
+
 // Duplicate the field at the specified coordinates, returning a pointer
 // to the newly allocated object.
 func (f *Field) Duplicate(y, x int32) (*Field, error) {
@@ -50,6 +124,16 @@ func (f *Field) Duplicate(y, x int32) (*Field, error) {
 	return (*Field)(nf), ncursesError(err)
 }
 
+// DynamicInfo returns the actual size of the field's buffer, which for a
+// dynamic field (one with FO_STATIC turned off) may have grown beyond the
+// size given to NewField, and the maximum growth set by SetMax. A max of
+// zero means the field may grow without limit
+func (f *Field) DynamicInfo() (rows, cols, max int, err error) {
+	var r, c, m C.int
+	e := C.dynamic_field_info((*C.FIELD)(f), &r, &c, &m)
+	return int(r), int(c), int(m), ncursesError(syscall.Errno(e))
+}
+
 // Foreground returns the field's foreground character attributes
 func (f *Field) Foreground() Char {
 	return Char(C.field_fore((*C.FIELD)(f)))
@@ -59,6 +143,9 @@ func (f *Field) Foreground() Char {
 // leaks
 func (f *Field) Free() error {
 	err := C.free_field((*C.FIELD)(f))
+	if err == C.E_OK {
+		delete(fieldExts, f)
+	}
 	f = nil
 	return ncursesError(syscall.Errno(err))
 }
@@ -85,13 +172,21 @@ func (f *Field) Move(y, x int32) error {
 	return ncursesError(syscall.Errno(err))
 }
 
-// Options turns features on and off
-func (f *Field) Options(opts int, on bool) {
+// checkFieldOptions returns an error if opts includes options which the
+// version of ncurses built against lacks
+func checkFieldOptions(opts FieldOptions) error {
+	if opts&missingFieldOptions != 0 {
+		return errors.New("Field option not supported by this version of ncurses")
+	}
+	return nil
+}
+
+// Options turns the given field option(s) on or off. See the FO_* constants
+func (f *Field) Options(opts FieldOptions, on bool) error {
 	if on {
-		C.field_opts_on((*C.FIELD)(f), C.Field_Options(opts))
-		return
+		return f.SetOptionsOn(opts)
 	}
-	C.field_opts_off((*C.FIELD)(f), C.Field_Options(opts))
+	return f.SetOptionsOff(opts)
 }
 
 // Pad returns the padding character of the field
@@ -121,8 +216,17 @@ func (f *Field) SetMax(max int) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetOptions replaces all of the field's options with opts
+func (f *Field) SetOptions(opts FieldOptions) error {
+	if err := checkFieldOptions(opts); err != nil {
+		return err
+	}
+	err := C.set_field_opts((*C.FIELD)(f), C.Field_Options(opts))
+	return ncursesError(syscall.Errno(err))
+}
+
 // OptionsOff turns feature(s) off
-func (f *Field) SetOptionsOff(opts Char) error {
+func (f *Field) SetOptionsOff(opts FieldOptions) error {
 	err := int(C.field_opts_off((*C.FIELD)(f), C.Field_Options(opts)))
 	if err != C.E_OK {
 		return ncursesError(syscall.Errno(err))
@@ -131,7 +235,10 @@ func (f *Field) SetOptionsOff(opts Char) error {
 }
 
 // OptionsOn turns feature(s) on
-func (f *Field) SetOptionsOn(opts Char) error {
+func (f *Field) SetOptionsOn(opts FieldOptions) error {
+	if err := checkFieldOptions(opts); err != nil {
+		return err
+	}
 	err := int(C.field_opts_on((*C.FIELD)(f), C.Field_Options(opts)))
 	if err != C.E_OK {
 		return ncursesError(syscall.Errno(err))
@@ -145,6 +252,118 @@ func (f *Field) SetPad(padch int) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetAlnumType restricts the field to letters and digits. At least width
+// characters must be entered unless the field is blank and FO_NULLOK is set
+func (f *Field) SetAlnumType(width int) error {
+	err := C.goncurses_type_alnum((*C.FIELD)(f), C.int(width))
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftAlnum, width: width}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetAlphaType restricts the field to letters. At least width characters
+// must be entered unless the field is blank and FO_NULLOK is set
+func (f *Field) SetAlphaType(width int) error {
+	err := C.goncurses_type_alpha((*C.FIELD)(f), C.int(width))
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftAlpha, width: width}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetEnumType restricts the field to one of the given values. Unless
+// caseSensitive is true, case is ignored. A prefix of a value is accepted
+// and completed but, if unique is true, only when it matches a single value
+func (f *Field) SetEnumType(values []string, caseSensitive, unique bool) error {
+	// libform keeps its own copy of the list and of each value
+	clist := C.goncurses_new_strings(C.int(len(values)))
+	defer C.free(unsafe.Pointer(clist))
+	for i, v := range values {
+		cstr := C.CString(v)
+		defer C.free(unsafe.Pointer(cstr))
+		C.goncurses_set_string_at(clist, C.int(i), cstr)
+	}
+
+	var ccase, cuniq C.int
+	if caseSensitive {
+		ccase = 1
+	}
+	if unique {
+		cuniq = 1
+	}
+	err := C.goncurses_type_enum((*C.FIELD)(f), clist, ccase, cuniq)
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftEnum,
+			values:        append([]string(nil), values...),
+			caseSensitive: caseSensitive, unique: unique}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetIntegerType restricts the field to an optionally signed integer. When
+// the field is validated its buffer is rewritten zero padded to at least
+// padding digits; note that a padding of zero displays the value 0 as a
+// blank field. If min is less than max the value must lie between them,
+// inclusive
+func (f *Field) SetIntegerType(padding, min, max int) error {
+	err := C.goncurses_type_integer((*C.FIELD)(f), C.int(padding),
+		C.long(min), C.long(max))
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftInteger, min: float64(min),
+			max: float64(max)}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetIPv4Type restricts the field to a dotted-quad IP version 4 address
+func (f *Field) SetIPv4Type() error {
+	err := C.goncurses_type_ipv4((*C.FIELD)(f))
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftIPv4}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetNumericType restricts the field to a decimal number. When the field is
+// validated its buffer is rewritten with precision digits after the decimal
+// point. If min is less than max the value must lie between them, inclusive
+func (f *Field) SetNumericType(precision int, min, max float64) error {
+	err := C.goncurses_type_numeric((*C.FIELD)(f), C.int(precision),
+		C.double(min), C.double(max))
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftNumeric, min: min, max: max}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetRegexpType requires the field's buffer, including any trailing
+// padding, to match the POSIX extended regular expression
+func (f *Field) SetRegexpType(expr string) error {
+	re, rerr := regexp.CompilePOSIX(expr)
+	if rerr != nil {
+		return rerr
+	}
+	cexpr := C.CString(expr)
+	defer C.free(unsafe.Pointer(cexpr))
+
+	err := C.goncurses_type_regexp((*C.FIELD)(f), cexpr)
+	if err == C.E_OK {
+		f.ext().typ = &fieldType{kind: ftRegexp, re: re}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// ClearType removes any type set on the field. Any value will be accepted
+func (f *Field) ClearType() error {
+	err := C.goncurses_type_none((*C.FIELD)(f))
+	if err == C.E_OK {
+		f.ext().typ = nil
+		f.pruneExt()
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetBackground character and attributes (colours, etc)
 func (f *Field) SetBackground(ch Char) error {
 	err := C.set_field_back((*C.FIELD)(f), C.chtype(ch))
@@ -157,15 +376,64 @@ func (f *Field) SetForeground(ch Char) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// newFieldList copies fields into a NULL terminated array allocated in C,
+// since libform keeps a reference to it for as long as the fields are
+// connected. A nil terminating fields is not copied
+func newFieldList(fields []*Field) **C.FIELD {
+	if n := len(fields); n > 0 && fields[n-1] == nil {
+		fields = fields[:n-1]
+	}
+	cfields := C.goncurses_new_fields(C.int(len(fields)))
+	for index, field := range fields {
+		C.goncurses_set_field_at(cfields, C.int(index), (*C.FIELD)(field))
+	}
+	return cfields
+}
+
 // NewForm returns a new form object using the fields array supplied as
 // an argument
 func NewForm(fields []*Field) (Form, error) {
-	if fields[len(fields)-1] != nil {
-		fields = append(fields, nil)
+	cfields := newFieldList(fields)
+	form, err := C.new_form(cfields)
+	if form == nil {
+		C.free(unsafe.Pointer(cfields))
+		return Form{}, ncursesError(err)
 	}
-	form, err := C.new_form((**C.FIELD)(unsafe.Pointer(&fields[0])))
+	formFields[form] = cfields
+	return Form{form: form}, nil
+}
 
-	return Form{form}, ncursesError(err)
+// Current returns the field which currently has focus
+func (f *Form) Current() *Field {
+	return (*Field)(C.current_field(f.form))
+}
+
+// DrawScrollMarkers draws an up arrow in the column to the right of the
+// top row of a multi-line field when text is scrolled out of view above it,
+// and a down arrow beside the bottom row when there is more text below. The
+// markers are erased when not needed so call it after each request which
+// may scroll the field, such as REQ_SCR_FLINE or REQ_NEXT_LINE. The column
+// must lie within the form's sub-window and not be used by another field.
+func (f *Form) DrawScrollMarkers(field *Field) {
+	var rows, cols, y, x int
+	if field.Info(&rows, &cols, &y, &x, nil, nil) != nil {
+		return
+	}
+	above, below := f.FieldScroll(field)
+	sub := &Window{win: C.form_sub(f.form)}
+
+	up, down := Char(' '), Char(' ')
+	if above {
+		up = ACS_UARROW
+	}
+	if below {
+		down = ACS_DARROW
+	}
+	sub.MoveAddChar(y, x+cols, up)
+	if rows > 1 {
+		sub.MoveAddChar(y+rows-1, x+cols, down)
+	}
+	f.PositionCursor()
 }
 
 // FieldCount returns the number of fields attached to the Form
@@ -174,44 +442,182 @@ func (f *Form) FieldCount() int {
 }
 
 // Driver issues the actions requested to the form itself. See the
-// corresponding REQ_* constants
-func (f *Form) Driver(drvract Key) error {
-	err := C.form_driver(f.form, C.int(drvract))
+// corresponding REQ_* constants. Printable characters may also be passed,
+// converted to a FormDriverReq, to enter them into the current field though
+// HandleKey is usually more convenient.
+func (f *Form) Driver(req FormDriverReq) error {
+	if req == REQ_TOGGLE_MODE {
+		req = REQ_OVL_MODE
+		if overlayForms[f.form] {
+			req = REQ_INS_MODE
+		}
+	}
+	err := C.form_driver(f.form, C.int(req))
+	if err == C.E_OK {
+		switch req {
+		case REQ_INS_MODE:
+			delete(overlayForms, f.form)
+		case REQ_OVL_MODE:
+			overlayForms[f.form] = true
+		}
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// HandleKey looks up the key in the form's keymap and issues the matching
+// request. Printable characters which are not mapped are passed on to the
+// form driver as input for the current field. Any other key is rejected
+// with an unknown command error, since keys beyond KEY_MAX share their
+// codes with the form requests.
+func (f *Form) HandleKey(k Key) error {
+	if req, ok := f.Keymap()[k]; ok {
+		return f.Driver(req)
+	}
+	if !printable(k) {
+		return errUnknownCommand
+	}
+	err := C.form_driver(f.form, C.int(k))
 	return ncursesError(syscall.Errno(err))
 }
 
+// Keymap returns the keymap used by HandleKey. Unless SetKeymap has been
+// called this is DefaultFormKeymap.
+func (f *Form) Keymap() FormKeymap {
+	if km, ok := formKeymaps[f.form]; ok {
+		return km
+	}
+	return DefaultFormKeymap
+}
+
+// Fields returns the fields attached to the form
+func (f *Form) Fields() []*Field {
+	cfields := C.form_fields(f.form)
+	count := f.FieldCount()
+	if cfields == nil || count <= 0 {
+		return nil
+	}
+	fields := make([]*Field, count)
+	for index := 0; index < count; index++ {
+		fields[index] = (*Field)(C.form_field_at(cfields, C.int(index)))
+	}
+	return fields
+}
+
+// FieldScroll reports whether a multi-line field of a posted form holds
+// text scrolled out of view above or below the rows it displays. Only the
+// current field scrolls; the others are displayed from their first row.
+func (f *Form) FieldScroll(field *Field) (above, below bool) {
+	if C.current_field(f.form) == (*C.FIELD)(field) {
+		return bool(C.data_behind(f.form)), bool(C.data_ahead(f.form))
+	}
+	var rows int
+	_, cols, _, err := field.DynamicInfo()
+	if err != nil || field.Info(&rows, nil, nil, nil, nil, nil) != nil {
+		return false, false
+	}
+	return false, bufferRows(field.Buffer(), cols) > rows
+}
+
+// bufferRows returns the number of rows of buf, the contents of a field
+// whose buffer is cols wide, up to the last one holding text
+func bufferRows(buf string, cols int) int {
+	rows, row, col := 0, 0, 0
+	for _, r := range buf {
+		w := runeWidth(r)
+		if col+w > cols {
+			row, col = row+1, 0
+		}
+		col += w
+		if r != ' ' {
+			rows = row + 1
+		}
+	}
+	return rows
+}
+
 // Free the memory allocated to the form. Forms are not automatically
 // free'd by Go's garbage collection system so the memory allocated to
 // it must be explicitly free'd
 func (f *Form) Free() error {
 	err := C.free_form(f.form)
+	if err == C.E_OK {
+		delete(overlayForms, f.form)
+		delete(formKeymaps, f.form)
+		C.free(unsafe.Pointer(formFields[f.form]))
+		delete(formFields, f.form)
+	}
 	f = nil
 	return ncursesError(syscall.Errno(err))
 }
 
+// PositionCursor restores the cursor to the position required by the form
+// driver. Use it after drawing to the form's window moves the cursor.
+func (f *Form) PositionCursor() error {
+	err := C.pos_form_cursor(f.form)
+	return ncursesError(syscall.Errno(err))
+}
+
 // Post the form, making it visible and interactive
 func (f *Form) Post() error {
 	err := C.post_form(f.form)
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetCurrent moves the focus to the given field. An error is returned if the
+// field currently being edited fails validation
+func (f *Form) SetCurrent(field *Field) error {
+	err := C.set_current_field(f.form, (*C.FIELD)(field))
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetFields overwrites the current fields for the Form with new ones.
 // It is important to make sure all prior fields have been freed otherwise
 // this action will result in a memory leak
 func (f *Form) SetFields(fields []*Field) error {
-	//cfields := make([]*C.FIELD, len(fields)+1)
-	//for index, field := range fields {
-	//cfields[index] = field.field
-	//}
-	//cfields[len(fields)] = nil
-	err := C.set_form_fields(f.form, (**C.FIELD)(unsafe.Pointer(&fields[0])))
+	cfields := newFieldList(fields)
+	err := C.set_form_fields(f.form, cfields)
+	if err != C.E_OK {
+		C.free(unsafe.Pointer(cfields))
+		return ncursesError(syscall.Errno(err))
+	}
+	C.free(unsafe.Pointer(formFields[f.form]))
+	formFields[f.form] = cfields
+	return nil
+}
+
+// SetKeymap replaces the keymap used by HandleKey for this form only. Pass
+// nil to revert to DefaultFormKeymap. To alter a few bindings, copy the
+// entries of DefaultFormKeymap into a new FormKeymap first.
+func (f *Form) SetKeymap(km FormKeymap) {
+	if km == nil {
+		delete(formKeymaps, f.form)
+		return
+	}
+	formKeymaps[f.form] = km
+}
+
+// CurrentOptions returns the options currently set on the form. See the
+// O_* form options
+func (f *Form) CurrentOptions() FormOptions {
+	return FormOptions(C.form_opts(f.form))
+}
+
+// SetOptions replaces all of the form's options with opts
+func (f *Form) SetOptions(opts FormOptions) error {
+	err := C.set_form_opts(f.form, C.Form_Options(opts))
 	return ncursesError(syscall.Errno(err))
 }
 
-// SetOptions for the form
-func (f *Form) SetOptions(opts int) error {
-	_, err := C.set_form_opts(f.form, (C.Form_Options)(opts))
-	return ncursesError(err)
+// SetOptionsOff turns the given form option(s) off
+func (f *Form) SetOptionsOff(opts FormOptions) error {
+	err := C.form_opts_off(f.form, C.Form_Options(opts))
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetOptionsOn turns the given form option(s) on
+func (f *Form) SetOptionsOn(opts FormOptions) error {
+	err := C.form_opts_on(f.form, C.Form_Options(opts))
+	return ncursesError(syscall.Errno(err))
 }
 
 // SetSub sets the subwindow associated with the form
@@ -228,7 +634,7 @@ func (f *Form) SetWindow(w *Window) error {
 
 // Sub returns the subwindow associated with the form
 func (f *Form) Sub() Window {
-	return Window{C.form_sub(f.form)}
+	return Window{win: C.form_sub(f.form)}
 }
 
 // UnPost the form, removing it from the interface
@@ -0,0 +1,445 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrCancelled is returned when the user abandons a form or dialog
+// without submitting it
+var ErrCancelled = errors.New("Cancelled by user")
+
+// StructForm is a Form whose fields are generated from the tagged fields of
+// a Go struct. Only exported fields carrying a "form" tag are used. The tag
+// is a comma separated list of options:
+//
+//	label=Text   label printed to the left of the field (default: field name)
+//	width=N      width of the field in characters
+//	height=N     height of the field in rows (default: 1)
+//	type=T       one of text, integer, numeric, alpha, alnum or bool
+//	min=N        minimum value (integer, numeric) or length (text)
+//	max=N        maximum value (integer, numeric) or length (text)
+//	required     the field may not be left blank
+//	password     typed characters are not echoed
+//
+// For example:
+//
+//	type Config struct {
+//		Host string `form:"label=Host,width=30,required"`
+//		Port int    `form:"label=Port,width=6,type=integer,min=1,max=65535"`
+//	}
+//
+// A tag of "-" skips the field. When no type is given it is derived from the
+// kind of the struct field. Text, alpha and alnum need a string field,
+// integer an integer, float or string, numeric a float or string, and bool
+// a bool.
+type StructForm struct {
+	Form
+	win    *Window
+	target reflect.Value
+	specs  []*structField
+	fields []*Field
+	msgRow int
+}
+
+type structField struct {
+	label    string
+	width    int
+	height   int
+	kind     string
+	min, max *float64
+	required bool
+	password bool
+	index    int
+	row, col int
+}
+
+// NewStructForm lays out a label and a field for each tagged member of the
+// struct pointed to by v inside of window w. The window's contents are used
+// for labels and the form is attached to it. Values currently held by the
+// struct are used as the initial contents of each field.
+func NewStructForm(w *Window, v interface{}) (*StructForm, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("NewStructForm requires a non-nil pointer to a struct")
+	}
+	sf := &StructForm{win: w, target: rv.Elem()}
+
+	rt := sf.target.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sfld := rt.Field(i)
+		tag, ok := sfld.Tag.Lookup("form")
+		if !ok || tag == "-" || sfld.PkgPath != "" {
+			continue
+		}
+		spec, err := parseFormTag(sfld, tag)
+		if err != nil {
+			return nil, err
+		}
+		spec.index = i
+		sf.specs = append(sf.specs, spec)
+	}
+	if len(sf.specs) == 0 {
+		return nil, errors.New("Struct has no tagged form fields")
+	}
+
+	lw := 0
+	for _, spec := range sf.specs {
+		if w := stringWidth(spec.label); w > lw {
+			lw = w
+		}
+	}
+	sf.fields = make([]*Field, 0, len(sf.specs))
+	y := 0
+	for _, spec := range sf.specs {
+		f, err := NewField(int32(spec.height), int32(spec.width), int32(y),
+			int32(lw+2), 0, 0)
+		if err != nil {
+			sf.freeFields()
+			return nil, err
+		}
+		f.SetBackground(A_UNDERLINE)
+		f.SetOptionsOff(FO_AUTOSKIP)
+		if spec.height > 1 {
+			f.SetOptionsOn(FO_WRAP)
+		}
+		if spec.password {
+			f.SetOptionsOff(FO_PUBLIC)
+		}
+		spec := spec
+		f.SetValidator(func(s string) error {
+			_, err := sf.parse(spec, s)
+			return err
+		})
+		f.SetBuffer(sf.format(spec))
+		sf.fields = append(sf.fields, f)
+		spec.row, spec.col = y, lw+2
+		y += spec.height
+	}
+	sf.msgRow = y + 1
+
+	form, err := NewForm(sf.fields)
+	if err != nil {
+		sf.freeFields()
+		return nil, err
+	}
+	sf.Form = form
+	if err := sf.SetWindow(w); err != nil {
+		sf.Free()
+		return nil, err
+	}
+	if err := sf.SetSub(w); err != nil {
+		sf.Free()
+		return nil, err
+	}
+	return sf, nil
+}
+
+func parseFormTag(sfld reflect.StructField, tag string) (*structField, error) {
+	spec := &structField{label: sfld.Name, height: 1}
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		key, val := opt, ""
+		if i := strings.IndexByte(opt, '='); i >= 0 {
+			key, val = opt[:i], opt[i+1:]
+		}
+		var err error
+		switch key {
+		case "label":
+			spec.label = val
+		case "width":
+			spec.width, err = strconv.Atoi(val)
+		case "height":
+			spec.height, err = strconv.Atoi(val)
+		case "type":
+			spec.kind = val
+		case "min", "max":
+			var n float64
+			if n, err = strconv.ParseFloat(val, 64); err == nil {
+				if key == "min" {
+					spec.min = &n
+				} else {
+					spec.max = &n
+				}
+			}
+		case "required":
+			spec.required = true
+		case "password":
+			spec.password = true
+		default:
+			err = errors.New("unknown option")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Field %s: bad form tag option %q: %v",
+				sfld.Name, opt, err)
+		}
+	}
+
+	var class string
+	switch sfld.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		class = "integer"
+	case reflect.Float32, reflect.Float64:
+		class = "numeric"
+	case reflect.Bool:
+		class = "bool"
+	case reflect.String:
+		class = "text"
+	default:
+		return nil, fmt.Errorf("Field %s: unsupported kind %s", sfld.Name,
+			sfld.Type.Kind())
+	}
+	if spec.kind == "" {
+		spec.kind = class
+	}
+
+	// the Go kinds each type can be stored in
+	var ok bool
+	var need string
+	switch spec.kind {
+	case "text", "alpha", "alnum":
+		ok, need = class == "text", "a string"
+	case "integer":
+		ok, need = class != "bool", "an integer, float or string"
+	case "numeric":
+		ok, need = class == "numeric" || class == "text", "a float or string"
+	case "bool":
+		ok, need = class == "bool", "a bool"
+	default:
+		return nil, fmt.Errorf("Field %s: unknown type %q", sfld.Name,
+			spec.kind)
+	}
+	if !ok {
+		return nil, fmt.Errorf("Field %s: type %s requires %s, not %s",
+			sfld.Name, spec.kind, need, sfld.Type.Kind())
+	}
+
+	if spec.width <= 0 {
+		switch spec.kind {
+		case "integer", "numeric":
+			spec.width = 10
+		case "bool":
+			spec.width = 6
+		default:
+			spec.width = 20
+		}
+	}
+	if spec.height <= 0 {
+		spec.height = 1
+	}
+	return spec, nil
+}
+
+// format returns the string representation of the struct member described
+// by spec, used as the initial buffer of its field
+func (sf *StructForm) format(spec *structField) string {
+	return fmt.Sprint(sf.target.Field(spec.index).Interface())
+}
+
+// parse converts the text entered into a field into a value suitable for
+// the struct member described by spec, applying any constraints
+func (sf *StructForm) parse(spec *structField, s string) (reflect.Value, error) {
+	rv := reflect.New(sf.target.Field(spec.index).Type()).Elem()
+	if s == "" {
+		if spec.required {
+			return rv, errors.New("a value is required")
+		}
+		return rv, nil
+	}
+
+	var num float64
+	switch spec.kind {
+	case "integer":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+			if err != nil {
+				return rv, errors.New("not a valid integer")
+			}
+			rv.SetInt(n)
+			num = float64(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+			if err != nil {
+				return rv, errors.New("not a valid unsigned integer")
+			}
+			rv.SetUint(n)
+			num = float64(n)
+		default:
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return rv, errors.New("not a valid integer")
+			}
+			num = float64(n)
+			if rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64 {
+				rv.SetFloat(num)
+			} else {
+				rv.SetString(s)
+			}
+		}
+	case "numeric":
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return rv, errors.New("not a valid number")
+		}
+		num = n
+		if rv.Kind() == reflect.String {
+			rv.SetString(s)
+		} else {
+			rv.SetFloat(n)
+		}
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return rv, errors.New("must be true or false")
+		}
+		rv.SetBool(b)
+		return rv, nil
+	default:
+		for _, r := range s {
+			if spec.kind == "alpha" && !unicode.IsLetter(r) {
+				return rv, errors.New("only letters are allowed")
+			}
+			if spec.kind == "alnum" && !unicode.IsLetter(r) &&
+				!unicode.IsDigit(r) {
+				return rv, errors.New("only letters and digits are allowed")
+			}
+		}
+		rv.SetString(s)
+		num = float64(len([]rune(s)))
+		if spec.min != nil && num < *spec.min {
+			return rv, fmt.Errorf("must be at least %g characters", *spec.min)
+		}
+		if spec.max != nil && num > *spec.max {
+			return rv, fmt.Errorf("must be at most %g characters", *spec.max)
+		}
+		return rv, nil
+	}
+	if spec.min != nil && num < *spec.min {
+		return rv, fmt.Errorf("must be at least %g", *spec.min)
+	}
+	if spec.max != nil && num > *spec.max {
+		return rv, fmt.Errorf("must be at most %g", *spec.max)
+	}
+	return rv, nil
+}
+
+// Fields returns the fields generated for the struct, in the same order as
+// the struct members they represent
+func (sf *StructForm) Fields() []*Field {
+	return sf.fields
+}
+
+// Submit validates the form. If all fields are valid their values are
+// written back into the struct; otherwise the struct is left untouched, the
+// cursor is moved to the first offending field and an error naming it is
+// returned.
+func (sf *StructForm) Submit() error {
+	if err := sf.Validate(true); err != nil {
+		if verr, ok := err.(ValidationError); ok {
+			return fmt.Errorf("%s: %v", sf.specs[verr[0].Index].label,
+				verr[0].Err)
+		}
+		return err
+	}
+	for i, spec := range sf.specs {
+		v, err := sf.parse(spec, strings.TrimSpace(sf.fields[i].Buffer()))
+		if err != nil {
+			return err
+		}
+		sf.target.Field(spec.index).Set(v)
+	}
+	return nil
+}
+
+// Run posts the form and processes input until the user submits it with
+// the Enter key or abandons it with Escape, in which case ErrCancelled is
+// returned. All other keys are passed to HandleKey so the form's keymap
+// governs editing and navigation; on moving to another field the cursor is
+// placed after its contents. Invalid input prevents submission and the
+// reason is displayed below the form.
+func (sf *StructForm) Run() error {
+	if err := sf.Post(); err != nil {
+		return err
+	}
+	defer sf.UnPost()
+
+	// typed characters are entered by the form driver, not echoed
+	if echoing() {
+		Echo(false)
+		defer Echo(true)
+	}
+	sf.win.Keypad(true)
+	sf.drawLabels()
+	sf.Driver(REQ_FIRST_FIELD)
+	sf.win.Refresh()
+
+	for {
+		ch := sf.win.GetChar()
+		switch ch {
+		case 0:
+			continue
+		case KEY_ESC:
+			return ErrCancelled
+		case KEY_RETURN, KEY_ENTER:
+			if err := sf.Submit(); err != nil {
+				sf.showMessage(err.Error())
+				break
+			}
+			return nil
+		default:
+			cur := sf.Current()
+			sf.HandleKey(ch)
+			// continue after any text already entered in a field moved to
+			if sf.Current() != cur {
+				sf.Driver(REQ_END_LINE)
+			}
+		}
+		sf.win.Refresh()
+	}
+}
+
+func (sf *StructForm) drawLabels() {
+	for _, spec := range sf.specs {
+		sf.win.MovePrint(spec.row, 0, spec.label+":")
+	}
+}
+
+func (sf *StructForm) showMessage(msg string) {
+	_, w := sf.win.MaxYX()
+	sf.win.Move(sf.msgRow, 0)
+	sf.win.ClearToEOL()
+	sf.win.MovePrint(sf.msgRow, 0, truncate(msg, w))
+	sf.PositionCursor()
+}
+
+func (sf *StructForm) freeFields() {
+	for _, f := range sf.fields {
+		f.Free()
+	}
+	sf.fields = nil
+}
+
+// Free the form and all of the fields created for it
+func (sf *StructForm) Free() error {
+	err := sf.Form.Free()
+	sf.freeFields()
+	return err
+}
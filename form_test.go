@@ -0,0 +1,658 @@
+// +build !windows,!purego
+
+package goncurses_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+// newTerminal starts ncurses on an emulated terminal of rows by cols,
+// which the test must close
+func newTerminal(t *testing.T, rows, cols int) *cursestest.Terminal {
+	term, err := cursestest.NewTerminal(rows, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return term
+}
+
+func TestStructFormTags(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+
+	tests := []struct {
+		name string
+		v    interface{}
+		err  string
+	}{
+		{"non-pointer", struct {
+			A string `form:""`
+		}{}, "non-nil pointer"},
+		{"untagged", &struct{ A string }{}, "no tagged form fields"},
+		{"skipped", &struct {
+			A string `form:"-"`
+		}{}, "no tagged form fields"},
+		{"unexported", &struct {
+			a string `form:""`
+		}{}, "no tagged form fields"},
+		{"unknown option", &struct {
+			A string `form:"colour=red"`
+		}{}, `bad form tag option "colour=red"`},
+		{"bad width", &struct {
+			A string `form:"width=wide"`
+		}{}, `bad form tag option "width=wide"`},
+		{"bad min", &struct {
+			A int `form:"min=one"`
+		}{}, `bad form tag option "min=one"`},
+		{"unknown type", &struct {
+			A string `form:"type=date"`
+		}{}, `unknown type "date"`},
+		{"text integer", &struct {
+			A int `form:"type=text"`
+		}{}, "type text requires a string, not int"},
+		{"alpha float", &struct {
+			A float64 `form:"type=alpha"`
+		}{}, "type alpha requires a string, not float64"},
+		{"alnum bool", &struct {
+			A bool `form:"type=alnum"`
+		}{}, "type alnum requires a string, not bool"},
+		{"integer bool", &struct {
+			A bool `form:"type=integer"`
+		}{}, "type integer requires an integer, float or string, not bool"},
+		{"numeric bool", &struct {
+			A bool `form:"type=numeric"`
+		}{}, "type numeric requires a float or string, not bool"},
+		{"numeric integer", &struct {
+			A int `form:"type=numeric"`
+		}{}, "type numeric requires a float or string, not int"},
+		{"bool string", &struct {
+			A string `form:"type=bool"`
+		}{}, "type bool requires a bool, not string"},
+		{"bool integer", &struct {
+			A uint8 `form:"type=bool"`
+		}{}, "type bool requires a bool, not uint8"},
+		{"bool float", &struct {
+			A float32 `form:"type=bool"`
+		}{}, "type bool requires a bool, not float32"},
+		{"slice", &struct {
+			A []string `form:""`
+		}{}, "unsupported kind slice"},
+	}
+	for _, test := range tests {
+		sf, err := goncurses.NewStructForm(term.StdScr(), test.v)
+		if err == nil {
+			sf.Free()
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error containing %q, got %q", test.name,
+				test.err, err)
+		}
+	}
+}
+
+func TestStructFormLayout(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+
+	v := struct {
+		Name  string  `form:"label=Full name,width=12"`
+		Notes string  `form:"height=3"`
+		Port  int     `form:""`
+		Ratio float64 `form:""`
+		On    bool    `form:""`
+		Skip  string
+	}{Name: "gopher", Port: 80}
+	sf, err := goncurses.NewStructForm(term.StdScr(), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Free()
+
+	want := []struct{ h, w, y, x int }{
+		{1, 12, 0, 11},
+		{3, 20, 1, 11},
+		{1, 10, 4, 11},
+		{1, 10, 5, 11},
+		{1, 6, 6, 11},
+	}
+	fields := sf.Fields()
+	if len(fields) != len(want) {
+		t.Fatalf("expected %d fields, got %d", len(want), len(fields))
+	}
+	for i, f := range fields {
+		var h, w, y, x int
+		if err := f.Info(&h, &w, &y, &x, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if h != want[i].h || w != want[i].w || y != want[i].y ||
+			x != want[i].x {
+			t.Errorf("field %d: expected %dx%d at %d, %d, got %dx%d at %d, %d",
+				i, want[i].h, want[i].w, want[i].y, want[i].x, h, w, y, x)
+		}
+	}
+	if got := strings.TrimSpace(fields[0].Buffer()); got != "gopher" {
+		t.Errorf("expected the name field to hold gopher, got %q", got)
+	}
+	if got := strings.TrimSpace(fields[2].Buffer()); got != "80" {
+		t.Errorf("expected the port field to hold 80, got %q", got)
+	}
+	if opts := fields[1].CurrentOptions(); opts&goncurses.FO_WRAP == 0 {
+		t.Error("expected a multi-line field to wrap")
+	}
+}
+
+func TestStructFormWideLabel(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+
+	v := struct {
+		Size string `form:"label=Größe"`
+		City string `form:"label=東京"`
+	}{}
+	sf, err := goncurses.NewStructForm(term.StdScr(), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Free()
+
+	// the fields start two columns after the widest label, which is five
+	// columns wide though seven bytes long
+	for i, f := range sf.Fields() {
+		var h, w, y, x int
+		if err := f.Info(&h, &w, &y, &x, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if x != 7 {
+			t.Errorf("field %d: expected column 7, got %d", i, x)
+		}
+	}
+}
+
+type account struct {
+	Name  string  `form:"required,min=2,max=8"`
+	Age   uint8   `form:""`
+	Score float64 `form:"min=0,max=10"`
+	Code  string  `form:"type=alnum"`
+	Admin bool    `form:""`
+	Count int     `form:"min=1,max=5"`
+	Label string  `form:"type=integer"`
+	Pin   string  `form:"password"`
+}
+
+func TestStructFormSubmit(t *testing.T) {
+	term := newTerminal(t, 12, 40)
+	defer term.Close()
+
+	valid := []string{"Ann", "30", "2.5", "a1", "true", "3", "42", "1234"}
+	tests := []struct {
+		field int
+		value string
+		err   string
+	}{
+		{-1, "", ""},
+		{0, "", "Name: a value is required"},
+		{0, "A", "Name: must be at least 2 characters"},
+		{0, "Alexander", "Name: must be at most 8 characters"},
+		{1, "-1", "Age: not a valid unsigned integer"},
+		{1, "300", "Age: not a valid unsigned integer"},
+		{2, "ten", "Score: not a valid number"},
+		{2, "10.5", "Score: must be at most 10"},
+		{3, "a-1", "Code: only letters and digits are allowed"},
+		{4, "maybe", "Admin: must be true or false"},
+		{5, "0", "Count: must be at least 1"},
+		{5, "2.5", "Count: not a valid integer"},
+		{6, "4x", "Label: not a valid integer"},
+	}
+	for _, test := range tests {
+		var v account
+		sf, err := goncurses.NewStructForm(term.StdScr(), &v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, f := range sf.Fields() {
+			s := valid[i]
+			if i == test.field {
+				s = test.value
+			}
+			f.SetBuffer(s)
+		}
+		err = sf.Submit()
+		sf.Free()
+
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("expected %q, got %v", test.err, err)
+			}
+			if v != (account{}) {
+				t.Errorf("%s: expected the struct to be untouched, got %+v",
+					test.err, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := account{"Ann", 30, 2.5, "a1", true, 3, "42", "1234"}
+		if v != want {
+			t.Errorf("expected %+v, got %+v", want, v)
+		}
+	}
+}
+
+// newForm posts a form of single line fields of the given width, one per
+// row, on the terminal's standard screen
+func newForm(t *testing.T, term *cursestest.Terminal, n,
+	width int) (goncurses.Form, []*goncurses.Field) {
+	fields := make([]*goncurses.Field, n)
+	for i := range fields {
+		f, err := goncurses.NewField(1, int32(width), int32(i), 0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.SetOptionsOff(goncurses.FO_AUTOSKIP | goncurses.FO_BLANK)
+		fields[i] = f
+	}
+	form, err := goncurses.NewForm(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form.SetWindow(term.StdScr())
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	return form, fields
+}
+
+func freeForm(form goncurses.Form, fields []*goncurses.Field) {
+	form.UnPost()
+	form.Free()
+	for _, f := range fields {
+		f.Free()
+	}
+}
+
+func TestFormHandleKey(t *testing.T) {
+	term := newTerminal(t, 6, 30)
+	defer term.Close()
+	form, fields := newForm(t, term, 2, 10)
+	defer freeForm(form, fields)
+
+	keys := func(ks ...goncurses.Key) {
+		for _, k := range ks {
+			form.HandleKey(k)
+		}
+	}
+	buffer := func(f *goncurses.Field) string {
+		form.Driver(goncurses.REQ_VALIDATION)
+		return strings.TrimSpace(f.Buffer())
+	}
+	tests := []struct {
+		keys []goncurses.Key
+		want string
+	}{
+		{[]goncurses.Key{'a', 'b', 'c'}, "abc"},
+		{[]goncurses.Key{goncurses.KEY_HOME, 'X'}, "Xabc"},
+		{[]goncurses.Key{goncurses.KEY_IC, 'Y'}, "XYbc"},
+		{[]goncurses.Key{goncurses.KEY_IC, 'Z'}, "XYZbc"},
+		{[]goncurses.Key{goncurses.KEY_END, goncurses.KEY_BACKSPACE},
+			"XYZb"},
+		{[]goncurses.Key{goncurses.KEY_LEFT, goncurses.KEY_LEFT,
+			goncurses.KEY_DC}, "XYb"},
+		{[]goncurses.Key{goncurses.KEY_RIGHT, 127, 8}, "X"},
+	}
+	for _, test := range tests {
+		keys(test.keys...)
+		if got := buffer(fields[0]); got != test.want {
+			t.Errorf("after %v expected %q, got %q", test.keys, test.want, got)
+		}
+	}
+
+	// the mode is shared by copies of the form
+	other := form
+	other.HandleKey(goncurses.KEY_IC)
+	keys(goncurses.KEY_HOME, goncurses.KEY_IC, 'W')
+	if got := buffer(fields[0]); got != "WX" {
+		t.Errorf("expected insert mode after toggling twice, got %q", got)
+	}
+
+	keys(goncurses.KEY_TAB)
+	if form.Current() != fields[1] {
+		t.Error("expected tab to move to the second field")
+	}
+	keys(goncurses.KEY_BTAB)
+	if form.Current() != fields[0] {
+		t.Error("expected back tab to move to the first field")
+	}
+
+	// the keymap is shared by copies of the form too
+	other.SetKeymap(goncurses.FormKeymap{'q': goncurses.REQ_CLR_FIELD})
+	keys(goncurses.KEY_TAB, 'q')
+	if got := buffer(fields[0]); got != "" {
+		t.Errorf("expected the custom keymap to clear the field, got %q", got)
+	}
+	form.SetKeymap(nil)
+	keys('q')
+	if got := buffer(fields[0]); got != "q" {
+		t.Errorf("expected the default keymap to insert q, got %q", got)
+	}
+	if req := goncurses.DefaultFormKeymap[goncurses.KEY_TAB]; req !=
+		goncurses.REQ_NEXT_FIELD {
+		t.Errorf("expected tab to map to REQ_NEXT_FIELD, got %v", req)
+	}
+}
+
+func TestFormHandleExtendedKey(t *testing.T) {
+	term := newTerminal(t, 6, 30)
+	defer term.Close()
+	form, fields := newForm(t, term, 1, 10)
+	defer freeForm(form, fields)
+	stdscr := term.StdScr()
+	stdscr.Keypad(true)
+	stdscr.Timeout(1000)
+
+	for _, k := range "hello" {
+		form.HandleKey(goncurses.Key(k))
+	}
+	// ctrl+left has a code beyond KEY_MAX, in the range of form requests
+	term.TypeString("\x1b[1;5D")
+	k := stdscr.GetChar()
+	if k <= goncurses.KEY_MAX {
+		t.Fatalf("expected an extended key, got %s", goncurses.KeyString(k))
+	}
+	if err := form.HandleKey(k); err == nil {
+		t.Error("expected an unmapped extended key to be rejected")
+	}
+	form.Driver(goncurses.REQ_VALIDATION)
+	if got := strings.TrimSpace(fields[0].Buffer()); got != "hello" {
+		t.Errorf("expected the buffer to be unchanged, got %q", got)
+	}
+}
+
+func TestFormSetFieldsCopy(t *testing.T) {
+	term := newTerminal(t, 6, 30)
+	defer term.Close()
+	form, fields := newForm(t, term, 2, 10)
+	form.UnPost()
+
+	// the field array belongs to the form, not to the copy replacing it
+	other := form
+	if err := other.SetFields(fields[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if got := form.Fields(); len(got) != 1 || got[0] != fields[1] {
+		t.Errorf("expected the copy's fields to replace the form's, got %v",
+			got)
+	}
+	fields[0].Free()
+	freeForm(form, fields[1:])
+}
+
+func TestStructFormRun(t *testing.T) {
+	term := newTerminal(t, 10, 40)
+	defer term.Close()
+	term.StdScr().Keypad(true)
+
+	v := struct {
+		User string `form:""`
+		Host string `form:""`
+	}{"root", "local"}
+	sf, err := goncurses.NewStructForm(term.StdScr(), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Free()
+
+	term.PressKey(goncurses.KEY_TAB)
+	term.TypeString("host")
+	term.PressKey(goncurses.KEY_BTAB)
+	term.TypeString("!")
+	term.PressKey(goncurses.KEY_RETURN)
+	if err := sf.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if v.User != "root!" || v.Host != "localhost" {
+		t.Errorf("expected root! and localhost, got %q and %q", v.User,
+			v.Host)
+	}
+
+	term.PressKey(goncurses.KEY_ESC)
+	if err := sf.Run(); err != goncurses.ErrCancelled {
+		t.Errorf("expected ErrCancelled, got %v", err)
+	}
+}
+
+func TestFieldOptions(t *testing.T) {
+	f, err := goncurses.NewField(1, 10, 0, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Free()
+
+	if err := f.Options(goncurses.FO_AUTOSKIP|goncurses.FO_BLANK,
+		false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Options(goncurses.FO_NO_LEFT_STRIP, true); err != nil {
+		t.Fatal(err)
+	}
+	opts := f.CurrentOptions()
+	if opts&(goncurses.FO_AUTOSKIP|goncurses.FO_BLANK) != 0 {
+		t.Errorf("expected autoskip and blank to be off, got %#x", opts)
+	}
+	if opts&goncurses.FO_NO_LEFT_STRIP == 0 {
+		t.Errorf("expected no left strip to be on, got %#x", opts)
+	}
+	if err := f.SetOptions(goncurses.FO_VISIBLE |
+		goncurses.FO_ACTIVE); err != nil {
+		t.Fatal(err)
+	}
+	if opts := f.CurrentOptions(); opts != goncurses.FO_VISIBLE|goncurses.FO_ACTIVE {
+		t.Errorf("expected only visible and active, got %#x", opts)
+	}
+}
+
+func TestFieldScroll(t *testing.T) {
+	term := newTerminal(t, 8, 30)
+	defer term.Close()
+
+	notes, err := goncurses.NewField(3, 10, 1, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notes.Free()
+	notes.SetOptionsOff(goncurses.FO_STATIC | goncurses.FO_BLANK)
+	other, err := goncurses.NewField(2, 10, 5, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Free()
+	other.SetOptionsOff(goncurses.FO_STATIC)
+	other.SetBuffer(strings.Repeat("x", 25))
+
+	form, err := goncurses.NewForm([]*goncurses.Field{notes, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.Free()
+	form.SetWindow(term.StdScr())
+	if err := form.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer form.UnPost()
+
+	marks := func() string {
+		form.DrawScrollMarkers(notes)
+		term.StdScr().Refresh()
+		if err := term.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		return string(term.Cell(1, 11).Ch) + string(term.Cell(3, 11).Ch)
+	}
+	if above, below := form.FieldScroll(notes); above || below {
+		t.Errorf("expected an empty field not to scroll, got %v, %v", above,
+			below)
+	}
+	if got := marks(); got != "  " {
+		t.Errorf("expected no markers, got %q", got)
+	}
+
+	for _, c := range strings.Repeat("abcdefghij", 5) {
+		form.Driver(goncurses.FormDriverReq(c))
+	}
+	if above, below := form.FieldScroll(notes); !above || below {
+		t.Errorf("expected text above only, got %v, %v", above, below)
+	}
+	if got := marks(); got != "^ " {
+		t.Errorf("expected an up marker, got %q", got)
+	}
+
+	form.Driver(goncurses.REQ_BEG_FIELD)
+	if above, below := form.FieldScroll(notes); above || !below {
+		t.Errorf("expected text below only, got %v, %v", above, below)
+	}
+	if got := marks(); got != " v" {
+		t.Errorf("expected a down marker, got %q", got)
+	}
+
+	if above, below := form.FieldScroll(other); above || !below {
+		t.Errorf("expected the other field to have text below only, got %v, %v",
+			above, below)
+	}
+	other.SetBuffer("short")
+	if above, below := form.FieldScroll(other); above || below {
+		t.Errorf("expected short text not to scroll, got %v, %v", above,
+			below)
+	}
+}
+
+func TestFormValidate(t *testing.T) {
+	term := newTerminal(t, 6, 30)
+	defer term.Close()
+
+	noNull := func(f *goncurses.Field) error {
+		return f.SetOptionsOff(goncurses.FO_NULLOK)
+	}
+	alnum := func(f *goncurses.Field) error { return f.SetAlnumType(3) }
+	alpha := func(f *goncurses.Field) error { return f.SetAlphaType(0) }
+	colors := []string{"red", "green", "grey"}
+	enum := func(f *goncurses.Field) error {
+		return f.SetEnumType(colors, false, true)
+	}
+	enumAny := func(f *goncurses.Field) error {
+		return f.SetEnumType(colors, true, false)
+	}
+	integer := func(f *goncurses.Field) error {
+		return f.SetIntegerType(0, 1, 10)
+	}
+	ipv4 := func(f *goncurses.Field) error { return f.SetIPv4Type() }
+	numeric := func(f *goncurses.Field) error {
+		return f.SetNumericType(2, 0, 1)
+	}
+	re := func(f *goncurses.Field) error {
+		return f.SetRegexpType("^[a-z]+ *$")
+	}
+	cleared := func(f *goncurses.Field) error {
+		f.SetAlphaType(0)
+		return f.ClearType()
+	}
+	validator := func(f *goncurses.Field) error {
+		f.SetValidator(func(s string) error {
+			if s == "bad" {
+				return errors.New("rejected")
+			}
+			return nil
+		})
+		return nil
+	}
+
+	tests := []struct {
+		set []func(*goncurses.Field) error
+		buf string
+		err string
+	}{
+		{[]func(*goncurses.Field) error{alnum}, "ab1", ""},
+		{[]func(*goncurses.Field) error{alnum}, "", ""},
+		{[]func(*goncurses.Field) error{alnum, noNull}, "",
+			"a value is required"},
+		{[]func(*goncurses.Field) error{alnum}, "ab",
+			"at least 3 characters are required"},
+		{[]func(*goncurses.Field) error{alnum}, "a-b",
+			"only letters and digits are allowed"},
+		{[]func(*goncurses.Field) error{alpha}, "abc", ""},
+		{[]func(*goncurses.Field) error{alpha}, "ab1",
+			"only letters are allowed"},
+		{[]func(*goncurses.Field) error{enum}, "RED", ""},
+		{[]func(*goncurses.Field) error{enum}, "gree", ""},
+		{[]func(*goncurses.Field) error{enum}, "gr",
+			"must be one of red, green, grey"},
+		{[]func(*goncurses.Field) error{enum}, "blue",
+			"must be one of red, green, grey"},
+		{[]func(*goncurses.Field) error{enumAny}, "gr", ""},
+		{[]func(*goncurses.Field) error{enumAny}, "RED",
+			"must be one of red, green, grey"},
+		{[]func(*goncurses.Field) error{integer}, "0", "must be between 1 and 10"},
+		{[]func(*goncurses.Field) error{integer}, "10", ""},
+		{[]func(*goncurses.Field) error{integer}, "11",
+			"must be between 1 and 10"},
+		{[]func(*goncurses.Field) error{integer}, "1.5", "not a valid integer"},
+		{[]func(*goncurses.Field) error{ipv4}, "10.0.0.255", ""},
+		{[]func(*goncurses.Field) error{ipv4}, "10.0.0",
+			"not a valid IPv4 address"},
+		{[]func(*goncurses.Field) error{ipv4}, "10.0.0.256",
+			"not a valid IPv4 address"},
+		{[]func(*goncurses.Field) error{numeric}, "0.5", ""},
+		{[]func(*goncurses.Field) error{numeric}, "1.5",
+			"must be between 0 and 1"},
+		{[]func(*goncurses.Field) error{numeric}, "half",
+			"not a valid number"},
+		{[]func(*goncurses.Field) error{re}, "abc", ""},
+		{[]func(*goncurses.Field) error{re}, "ABC",
+			"does not match ^[a-z]+ *$"},
+		{[]func(*goncurses.Field) error{cleared}, "123", ""},
+		{[]func(*goncurses.Field) error{validator}, "good", ""},
+		{[]func(*goncurses.Field) error{alpha, validator}, "bad", "rejected"},
+		{[]func(*goncurses.Field) error{alpha, validator}, "ba1",
+			"only letters are allowed"},
+	}
+	for _, test := range tests {
+		f, err := goncurses.NewField(1, 12, 0, 0, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, set := range test.set {
+			if err := set(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+		f.SetBuffer(test.buf)
+		form, err := goncurses.NewForm([]*goncurses.Field{f})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = form.Validate(false)
+		form.Free()
+		f.Free()
+
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%q: expected no error, got %v", test.buf, err)
+			}
+			continue
+		}
+		verr, ok := err.(goncurses.ValidationError)
+		if !ok || len(verr) != 1 || verr[0].Index != 0 || verr[0].Field != f {
+			t.Errorf("%q: expected a single field error, got %v", test.buf, err)
+			continue
+		}
+		if verr[0].Err.Error() != test.err {
+			t.Errorf("%q: expected %q, got %q", test.buf, test.err, verr[0].Err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+// #include <form.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode"
+)
+
+// FieldError describes a field which failed validation
+type FieldError struct {
+	Field *Field // the offending field
+	Index int    // index of the field in the form
+	Err   error  // the reason the field is invalid
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %d: %v", e.Index, e.Err)
+}
+
+// ValidationError is returned by Form.Validate. It lists every field which
+// failed validation in the order the fields appear in the form
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
+const (
+	ftAlnum = iota
+	ftAlpha
+	ftEnum
+	ftInteger
+	ftIPv4
+	ftNumeric
+	ftRegexp
+)
+
+// fieldType mirrors the arguments of a libform field type so that fields
+// other than the current one can be checked without the form driver
+type fieldType struct {
+	kind          int
+	width         int
+	min, max      float64
+	values        []string
+	caseSensitive bool
+	unique        bool
+	re            *regexp.Regexp
+}
+
+// fieldExt holds the Go side data associated with a Field. Entries are
+// removed when the field is freed or has neither a type nor a validator
+type fieldExt struct {
+	typ       *fieldType
+	validator func(string) error
+}
+
+var fieldExts = map[*Field]*fieldExt{}
+
+func (f *Field) ext() *fieldExt {
+	ext, ok := fieldExts[f]
+	if !ok {
+		ext = &fieldExt{}
+		fieldExts[f] = ext
+	}
+	return ext
+}
+
+// pruneExt removes the field's entry from fieldExts once it holds neither
+// a type nor a validator
+func (f *Field) pruneExt() {
+	if ext, ok := fieldExts[f]; ok && ext.typ == nil && ext.validator == nil {
+		delete(fieldExts, f)
+	}
+}
+
+// SetValidator installs a function which Form.Validate calls with the
+// contents of the field, stripped of leading and trailing blanks, after
+// any type set on the field has been checked. Returning an error marks the
+// field as invalid. Pass nil to remove the validator.
+func (f *Field) SetValidator(fn func(string) error) {
+	f.ext().validator = fn
+	f.pruneExt()
+}
+
+// check reports whether buf, the raw contents of a field, is acceptable to
+// the field's type. It mirrors the checks made by libform's built-in types
+func (t *fieldType) check(buf string, nullok bool) error {
+	s := strings.TrimSpace(buf)
+	if s == "" && t.kind != ftRegexp {
+		if nullok {
+			return nil
+		}
+		return errors.New("a value is required")
+	}
+
+	switch t.kind {
+	case ftAlnum, ftAlpha:
+		for _, r := range s {
+			if t.kind == ftAlpha && !unicode.IsLetter(r) {
+				return errors.New("only letters are allowed")
+			}
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return errors.New("only letters and digits are allowed")
+			}
+		}
+		if len([]rune(s)) < t.width {
+			return fmt.Errorf("at least %d characters are required", t.width)
+		}
+	case ftEnum:
+		matches := 0
+		for _, v := range t.values {
+			val, in := v, s
+			if !t.caseSensitive {
+				val, in = strings.ToLower(v), strings.ToLower(s)
+			}
+			if val == in {
+				return nil
+			}
+			if strings.HasPrefix(val, in) {
+				matches++
+			}
+		}
+		if matches == 0 || (t.unique && matches > 1) {
+			return fmt.Errorf("must be one of %s", strings.Join(t.values, ", "))
+		}
+	case ftInteger:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errors.New("not a valid integer")
+		}
+		if t.min < t.max && (float64(n) < t.min || float64(n) > t.max) {
+			return fmt.Errorf("must be between %g and %g", t.min, t.max)
+		}
+	case ftIPv4:
+		parts := strings.Split(s, ".")
+		if len(parts) != 4 {
+			return errors.New("not a valid IPv4 address")
+		}
+		for _, p := range parts {
+			if n, err := strconv.ParseUint(p, 10, 8); err != nil || n > 255 {
+				return errors.New("not a valid IPv4 address")
+			}
+		}
+	case ftNumeric:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errors.New("not a valid number")
+		}
+		if t.min < t.max && (n < t.min || n > t.max) {
+			return fmt.Errorf("must be between %g and %g", t.min, t.max)
+		}
+	case ftRegexp:
+		if !t.re.MatchString(buf) {
+			return fmt.Errorf("does not match %s", t.re)
+		}
+	}
+	return nil
+}
+
+// Validate checks every active field in the form. The field being edited
+// is first validated by the form driver, which also copies any partially
+// entered input into its buffer so it is not lost. Each field's type, if
+// any, and the validator installed with SetValidator are then applied to
+// its contents. If any field is invalid a ValidationError is returned and,
+// if focus is true, the first invalid field is made current. Note that the
+// form driver will not leave the current field while it holds input its
+// type rejects. Validate may be called on forms which are not posted, in
+// which case only the field buffers are examined.
+func (f *Form) Validate(focus bool) error {
+	var errs ValidationError
+
+	cur := (*Field)(C.current_field(f.form))
+	drvErr := C.form_driver(f.form, C.REQ_VALIDATION)
+
+	for i, field := range f.Fields() {
+		opts := field.CurrentOptions()
+		if opts&FO_ACTIVE == 0 {
+			continue
+		}
+		buf := field.Buffer()
+		ext := fieldExts[field]
+
+		var err error
+		if ext != nil && ext.typ != nil {
+			err = ext.typ.check(buf, opts&FO_NULLOK != 0)
+		}
+		if err == nil && field == cur && drvErr == C.E_INVALID_FIELD {
+			err = ncursesError(syscall.Errno(drvErr))
+		}
+		if err == nil && ext != nil && ext.validator != nil {
+			err = ext.validator(strings.TrimSpace(buf))
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Field: field, Index: i, Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if focus {
+		f.SetCurrent(errs[0].Field)
+	}
+	return errs
+}
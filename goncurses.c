@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 #include <stdbool.h>
 #include <stdlib.h>
 #include <curses.h>
@@ -11,6 +13,7 @@ bool is_term_resized(int y, int x) { return is_termresized(); }
 int resizeterm(int y, int x) { return resize_term(y, x); }
 int ncurses_getmouse(MEVENT *me) { return nc_getmouse(me); }
 int ncurses_has_key(int ch) { return has_key(ch) == true ? 1 : 0; }
+int ncurses_keyok(int ch, bool on) { return ERR; }
 int ncurses_ungetch(int ch) { return PDC_ungetch(ch); }
 int ncurses_wattroff(WINDOW *win, int attr ) {
 	return wattroff(win, (chtype) attr);
@@ -21,6 +24,7 @@ int ncurses_wattron(WINDOW *win, int attr) {
 #else
 int ncurses_getmouse(MEVENT *me) { return getmouse(me); }
 int ncurses_has_key(int ch) { return has_key(ch); }
+int ncurses_keyok(int ch, bool on) { return keyok(ch, on); }
 int ncurses_ungetch(int ch) { return ungetch(ch); }
 int ncurses_wattroff(WINDOW *win, int attr) { return wattroff(win, attr); }
 int ncurses_wattron(WINDOW *win, int attr) { return wattron(win, attr); }
@@ -97,3 +101,76 @@ bool goncurses_set_escdelay(int size) {
   return true;
 #endif
 }
+
+/* The ncurses_*_sp functions act on the given screen whichever is current.
+ * ncurses built without the screen pointer extension, as well as PDCurses,
+ * has no _sp functions, so the screen is made current for the call and the
+ * previous one restored afterwards */
+#if defined(NCURSES_SP_FUNCS) && NCURSES_SP_FUNCS
+#define SP_CALL(type, sp, call_sp, call) return call_sp
+#else
+#define SP_CALL(type, sp, call_sp, call) \
+	SCREEN *prev = set_term(sp); \
+	type ret = call; \
+	set_term(prev); \
+	return ret
+#endif
+
+WINDOW *ncurses_newwin_sp(SCREEN *sp, int h, int w, int y, int x) {
+	SP_CALL(WINDOW *, sp, newwin_sp(sp, h, w, y, x), newwin(h, w, y, x));
+}
+WINDOW *ncurses_newpad_sp(SCREEN *sp, int h, int w) {
+	SP_CALL(WINDOW *, sp, newpad_sp(sp, h, w), newpad(h, w));
+}
+int ncurses_doupdate_sp(SCREEN *sp) {
+	SP_CALL(int, sp, doupdate_sp(sp), doupdate());
+}
+int ncurses_beep_sp(SCREEN *sp) { SP_CALL(int, sp, beep_sp(sp), beep()); }
+int ncurses_flash_sp(SCREEN *sp) { SP_CALL(int, sp, flash_sp(sp), flash()); }
+int ncurses_cbreak_sp(SCREEN *sp, bool on) {
+	SP_CALL(int, sp, on ? cbreak_sp(sp) : nocbreak_sp(sp),
+		on ? cbreak() : nocbreak());
+}
+int ncurses_echo_sp(SCREEN *sp, bool on) {
+	SP_CALL(int, sp, on ? echo_sp(sp) : noecho_sp(sp),
+		on ? echo() : noecho());
+}
+int ncurses_raw_sp(SCREEN *sp, bool on) {
+	SP_CALL(int, sp, on ? raw_sp(sp) : noraw_sp(sp), on ? raw() : noraw());
+}
+int ncurses_curs_set_sp(SCREEN *sp, int vis) {
+	SP_CALL(int, sp, curs_set_sp(sp, vis), curs_set(vis));
+}
+int ncurses_flushinp_sp(SCREEN *sp) {
+	SP_CALL(int, sp, flushinp_sp(sp), flushinp());
+}
+bool ncurses_has_colors_sp(SCREEN *sp) {
+	SP_CALL(bool, sp, has_colors_sp(sp), has_colors());
+}
+int ncurses_start_color_sp(SCREEN *sp) {
+	SP_CALL(int, sp, start_color_sp(sp), start_color());
+}
+int ncurses_init_pair_sp(SCREEN *sp, short pair, short fg, short bg) {
+	SP_CALL(int, sp, init_pair_sp(sp, pair, fg, bg), init_pair(pair, fg, bg));
+}
+int ncurses_init_color_sp(SCREEN *sp, short col, short r, short g, short b) {
+	SP_CALL(int, sp, init_color_sp(sp, col, r, g, b), init_color(col, r, g, b));
+}
+int ncurses_use_default_colors_sp(SCREEN *sp) {
+	SP_CALL(int, sp, use_default_colors_sp(sp), use_default_colors());
+}
+int ncurses_resizeterm_sp(SCREEN *sp, int y, int x) {
+	SP_CALL(int, sp, resizeterm_sp(sp, y, x), resizeterm(y, x));
+}
+int ncurses_resize_term_sp(SCREEN *sp, int y, int x) {
+	SP_CALL(int, sp, resize_term_sp(sp, y, x), resize_term(y, x));
+}
+int ncurses_endwin_sp(SCREEN *sp) {
+	SP_CALL(int, sp, endwin_sp(sp), endwin());
+}
+bool ncurses_isendwin_sp(SCREEN *sp) {
+	SP_CALL(bool, sp, isendwin_sp(sp), isendwin());
+}
+char *ncurses_termname_sp(SCREEN *sp) {
+	SP_CALL(char *, sp, termname_sp(sp), termname());
+}
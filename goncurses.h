@@ -16,6 +16,7 @@ void ncurses_getmaxyx(WINDOW *win, int *y, int *x);
 int ncurses_getmouse(MEVENT *me);
 void ncurses_getyx(WINDOW *win, int *y, int *x);
 int ncurses_has_key(int);
+int ncurses_keyok(int ch, bool on);
 bool ncurses_has_mouse(void);
 bool ncurses_is_cleared(const WINDOW *win);
 bool ncurses_is_keypad(const WINDOW *win);
@@ -32,4 +33,25 @@ int ncurses_wstandend(WINDOW *win);
 int ncurses_wstandout(WINDOW *win);
 bool goncurses_set_escdelay(int size);
 
+WINDOW *ncurses_newwin_sp(SCREEN *sp, int h, int w, int y, int x);
+WINDOW *ncurses_newpad_sp(SCREEN *sp, int h, int w);
+int ncurses_doupdate_sp(SCREEN *sp);
+int ncurses_beep_sp(SCREEN *sp);
+int ncurses_flash_sp(SCREEN *sp);
+int ncurses_cbreak_sp(SCREEN *sp, bool on);
+int ncurses_echo_sp(SCREEN *sp, bool on);
+int ncurses_raw_sp(SCREEN *sp, bool on);
+int ncurses_curs_set_sp(SCREEN *sp, int vis);
+int ncurses_flushinp_sp(SCREEN *sp);
+bool ncurses_has_colors_sp(SCREEN *sp);
+int ncurses_start_color_sp(SCREEN *sp);
+int ncurses_init_pair_sp(SCREEN *sp, short pair, short fg, short bg);
+int ncurses_init_color_sp(SCREEN *sp, short col, short r, short g, short b);
+int ncurses_use_default_colors_sp(SCREEN *sp);
+int ncurses_resizeterm_sp(SCREEN *sp, int y, int x);
+int ncurses_resize_term_sp(SCREEN *sp, int y, int x);
+int ncurses_endwin_sp(SCREEN *sp);
+bool ncurses_isendwin_sp(SCREEN *sp);
+char *ncurses_termname_sp(SCREEN *sp);
+
 #endif /* _GONCURSES_ */
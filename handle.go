@@ -0,0 +1,33 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+// handleTable hands out integer handles for Go values. Go pointers may not
+// be kept in C memory, so the user pointers of C objects hold a handle
+// which is used to look up the associated value. Handle zero means no value
+type handleTable struct {
+	next   uintptr
+	values map[uintptr]interface{}
+}
+
+var userData = handleTable{values: make(map[uintptr]interface{})}
+
+// add stores v and returns its handle
+func (t *handleTable) add(v interface{}) uintptr {
+	t.next++
+	t.values[t.next] = v
+	return t.next
+}
+
+// get returns the value stored under handle h, or nil
+func (t *handleTable) get(h uintptr) interface{} {
+	return t.values[h]
+}
+
+// remove releases handle h
+func (t *handleTable) remove(h uintptr) {
+	delete(t.values, h)
+}
@@ -0,0 +1,248 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+// Package pty opens pseudo-terminals. It is used to run ncurses against a
+// terminal emulated in-process rather than the one the program was started
+// from.
+package pty
+
+/*
+#ifdef __linux__
+#define _GNU_SOURCE
+#endif
+#include <errno.h>
+#include <fcntl.h>
+#include <stdlib.h>
+#include <sys/ioctl.h>
+#include <sys/select.h>
+#include <termios.h>
+#include <unistd.h>
+
+static int goncurses_openpt(void) {
+	int fd = posix_openpt(O_RDWR | O_NOCTTY);
+
+	if (fd < 0)
+		return -1;
+	if (grantpt(fd) < 0 || unlockpt(fd) < 0) {
+		close(fd);
+		return -1;
+	}
+	return fd;
+}
+
+static int goncurses_set_winsize(int fd, int rows, int cols) {
+	struct winsize ws = { 0 };
+
+	ws.ws_row = rows;
+	ws.ws_col = cols;
+	return ioctl(fd, TIOCSWINSZ, &ws);
+}
+
+static int goncurses_get_winsize(int fd, int *rows, int *cols) {
+	struct winsize ws;
+
+	if (ioctl(fd, TIOCGWINSZ, &ws) < 0)
+		return -1;
+	*rows = ws.ws_row;
+	*cols = ws.ws_col;
+	return 0;
+}
+
+static int goncurses_make_raw(int fd, struct termios *saved) {
+	struct termios t;
+
+	if (tcgetattr(fd, saved) < 0)
+		return -1;
+	t = *saved;
+	cfmakeraw(&t);
+	return tcsetattr(fd, TCSAFLUSH, &t);
+}
+
+// goncurses_wait_input waits until there is input to read from fd,
+// returning 1, or from cancel, returning 0
+static int goncurses_wait_input(int fd, int cancel) {
+	fd_set fds;
+	int n;
+
+	do {
+		FD_ZERO(&fds);
+		FD_SET(fd, &fds);
+		FD_SET(cancel, &fds);
+		n = select((fd > cancel ? fd : cancel) + 1, &fds, NULL, NULL, NULL);
+	} while (n < 0 && errno == EINTR);
+	if (n < 0)
+		return -1;
+	return !FD_ISSET(cancel, &fds);
+}
+*/
+import "C"
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// Open returns the master and slave ends of a new pseudo-terminal. Data
+// written to the slave, as by a program running on the terminal, is read
+// from the master and data written to the master is read from the slave as
+// if it had been typed. Reads from the master may be interrupted by closing
+// it.
+func Open() (master, slave *os.File, err error) {
+	fd, err := C.goncurses_openpt()
+	if fd < 0 {
+		return nil, nil, os.NewSyscallError("posix_openpt", err)
+	}
+	name, err := C.ptsname(fd)
+	if name == nil {
+		C.close(fd)
+		return nil, nil, os.NewSyscallError("ptsname", err)
+	}
+	path := C.GoString(name)
+
+	if err := syscall.SetNonblock(int(fd), true); err != nil {
+		C.close(fd)
+		return nil, nil, os.NewSyscallError("setnonblock", err)
+	}
+	master = os.NewFile(uintptr(fd), "/dev/ptmx")
+	slave, err = os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// control calls fn with the file descriptor of f. Unlike f.Fd it does not
+// put the descriptor into blocking mode
+func control(f *os.File, fn func(fd C.int) error) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := rc.Control(func(fd uintptr) { ferr = fn(C.int(fd)) }); err != nil {
+		return err
+	}
+	return ferr
+}
+
+// SetSize sets the size of the terminal. f may be either end of the
+// pseudo-terminal. The process group in the foreground of the terminal, if
+// there is one, is sent SIGWINCH
+func SetSize(f *os.File, rows, cols int) error {
+	return control(f, func(fd C.int) error {
+		if r, err := C.goncurses_set_winsize(fd, C.int(rows), C.int(cols)); r < 0 {
+			return os.NewSyscallError("TIOCSWINSZ", err)
+		}
+		return nil
+	})
+}
+
+// Size returns the size of the terminal. f may be any terminal, including
+// either end of a pseudo-terminal
+func Size(f *os.File) (rows, cols int, err error) {
+	err = control(f, func(fd C.int) error {
+		var r, c C.int
+		if res, err := C.goncurses_get_winsize(fd, &r, &c); res < 0 {
+			return os.NewSyscallError("TIOCGWINSZ", err)
+		}
+		rows, cols = int(r), int(c)
+		return nil
+	})
+	return
+}
+
+// MakeRaw puts the terminal f into raw mode, in which input is passed on a
+// byte at a time without being echoed or interpreted. restore returns the
+// terminal to the mode it was in before
+func MakeRaw(f *os.File) (restore func() error, err error) {
+	var saved C.struct_termios
+	err = control(f, func(fd C.int) error {
+		if r, err := C.goncurses_make_raw(fd, &saved); r < 0 {
+			return os.NewSyscallError("tcsetattr", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return control(f, func(fd C.int) error {
+			if r, err := C.tcsetattr(fd, C.TCSADRAIN, &saved); r < 0 {
+				return os.NewSyscallError("tcsetattr", err)
+			}
+			return nil
+		})
+	}, nil
+}
+
+// Reader reads from a file, usually a terminal, in a way which can be
+// interrupted without closing the file, so that a goroutine waiting for
+// input can be stopped without taking the next thing typed
+type Reader struct {
+	f      *os.File
+	cr, cw *os.File
+	done   bool
+}
+
+// NewReader returns a Reader for f
+func NewReader(f *os.File) (*Reader, error) {
+	cr, cw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{f: f, cr: cr, cw: cw}, nil
+}
+
+// Read reads from the file once it has input. It returns io.EOF once Close
+// has been called, including when already waiting for input. Read must
+// not be called from more than one goroutine at a time
+func (r *Reader) Read(p []byte) (int, error) {
+	for !r.done {
+		var ready C.int
+		err := control(r.f, func(fd C.int) error {
+			return control(r.cr, func(cancel C.int) error {
+				var err error
+				if ready, err = C.goncurses_wait_input(fd, cancel); ready < 0 {
+					return os.NewSyscallError("select", err)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return 0, err
+		}
+		if ready == 0 {
+			r.done = true
+			r.cr.Close()
+			break
+		}
+
+		var n int
+		err = control(r.f, func(fd C.int) error {
+			var err error
+			n, err = syscall.Read(int(fd), p)
+			return err
+		})
+		switch {
+		case err == syscall.EAGAIN:
+			continue
+		case err != nil:
+			return 0, os.NewSyscallError("read", err)
+		case n == 0:
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+	return 0, io.EOF
+}
+
+// Close stops the Reader, interrupting a Read waiting for input. The file
+// is left open
+func (r *Reader) Close() error {
+	return r.cw.Close()
+}
@@ -0,0 +1,265 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terminfo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// formatFlags holds the bytes which may appear between % and the verb of a
+// printf style conversion
+const formatFlags = "-+# .0123456789"
+
+// stackValue is an entry of the stack of a parameterized string. Values
+// pushed by %p and %{} are numbers; string parameters are not supported
+type stackValue int
+
+type stack []stackValue
+
+func (s *stack) push(v stackValue) {
+	*s = append(*s, v)
+}
+
+func (s *stack) pop() stackValue {
+	if len(*s) == 0 {
+		return 0
+	}
+	v := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return v
+}
+
+func boolValue(b bool) stackValue {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Expand expands the parameterized string s, as tparm does, with up to
+// nine numeric parameters. Padding, given as $<n>, is removed since it is
+// only needed by real hardware terminals
+func Expand(s string, params ...int) string {
+	var p [9]stackValue
+	for i := 0; i < len(params) && i < len(p); i++ {
+		p[i] = stackValue(params[i])
+	}
+	var (
+		out     strings.Builder
+		st      stack
+		dynamic [26]stackValue
+		static  [26]stackValue
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '$' && i+1 < len(s) && s[i+1] == '<' {
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				i += end
+				continue
+			}
+		}
+		if c != '%' || i+1 == len(s) {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		c = s[i]
+		switch c {
+		case '%':
+			out.WriteByte('%')
+		case 'c':
+			out.WriteByte(byte(st.pop()))
+		case 's':
+			out.WriteString(strconv.Itoa(int(st.pop())))
+		case 'p':
+			if i+1 < len(s) && s[i+1] >= '1' && s[i+1] <= '9' {
+				i++
+				st.push(p[s[i]-'1'])
+			}
+		case 'P', 'g':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			v := s[i]
+			var reg *stackValue
+			switch {
+			case v >= 'a' && v <= 'z':
+				reg = &dynamic[v-'a']
+			case v >= 'A' && v <= 'Z':
+				reg = &static[v-'A']
+			default:
+				continue
+			}
+			if c == 'P' {
+				*reg = st.pop()
+			} else {
+				st.push(*reg)
+			}
+		case '\'':
+			if i+2 < len(s) {
+				st.push(stackValue(s[i+1]))
+				i += 2
+			}
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				break
+			}
+			n, _ := strconv.Atoi(s[i+1 : i+end])
+			st.push(stackValue(n))
+			i += end
+		case 'l':
+			st.push(stackValue(len(strconv.Itoa(int(st.pop())))))
+		case 'i':
+			p[0]++
+			p[1]++
+		case '+', '-', '*', '/', 'm', '&', '|', '^', '=', '>', '<', 'A',
+			'O':
+			b, a := st.pop(), st.pop()
+			st.push(operate(c, a, b))
+		case '!':
+			st.push(boolValue(st.pop() == 0))
+		case '~':
+			st.push(^st.pop())
+		case '?', ';':
+		case 't':
+			if st.pop() == 0 {
+				i = skip(s, i+1, true)
+			}
+		case 'e':
+			i = skip(s, i+1, false)
+		default:
+			// a printf style conversion: %[[:]flags][width[.precision]]
+			// [doxX]
+			start := i
+			if c == ':' {
+				i++
+			}
+			for i < len(s) && strings.IndexByte(formatFlags, s[i]) >= 0 {
+				i++
+			}
+			if i == len(s) {
+				break
+			}
+			out.WriteString(format(s[start:i], s[i], int(st.pop())))
+		}
+	}
+	return out.String()
+}
+
+// operate applies the binary operator op to a and b
+func operate(op byte, a, b stackValue) stackValue {
+	switch op {
+	case '+':
+		return a + b
+	case '-':
+		return a - b
+	case '*':
+		return a * b
+	case '/':
+		if b == 0 {
+			return 0
+		}
+		return a / b
+	case 'm':
+		if b == 0 {
+			return 0
+		}
+		return a % b
+	case '&':
+		return a & b
+	case '|':
+		return a | b
+	case '^':
+		return a ^ b
+	case '=':
+		return boolValue(a == b)
+	case '>':
+		return boolValue(a > b)
+	case '<':
+		return boolValue(a < b)
+	case 'A':
+		return boolValue(a != 0 && b != 0)
+	case 'O':
+		return boolValue(a != 0 || b != 0)
+	}
+	return 0
+}
+
+// skip returns the index of the last byte of the %e or %; ending the part
+// of a conditional starting at i. When els is false only %; ends it
+func skip(s string, i int, els bool) int {
+	depth := 0
+	for ; i+1 < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		switch s[i] {
+		case '?':
+			depth++
+		case ';':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case 'e':
+			if depth == 0 && els {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// format formats n as a printf conversion with the given flags, width and
+// precision
+func format(spec string, verb byte, n int) string {
+	spec = strings.TrimPrefix(spec, ":")
+	var digits string
+	switch verb {
+	case 'o':
+		digits = strconv.FormatInt(int64(n), 8)
+	case 'x':
+		digits = strconv.FormatInt(int64(n), 16)
+	case 'X':
+		digits = strings.ToUpper(strconv.FormatInt(int64(n), 16))
+	default:
+		digits = strconv.Itoa(n)
+	}
+	left, zero := false, false
+	for len(spec) > 0 && strings.IndexByte("-+# 0", spec[0]) >= 0 {
+		switch spec[0] {
+		case '-':
+			left = true
+		case '0':
+			zero = true
+		}
+		spec = spec[1:]
+	}
+	width, precision := spec, ""
+	if dot := strings.IndexByte(spec, '.'); dot >= 0 {
+		width, precision = spec[:dot], spec[dot+1:]
+	}
+	if p, err := strconv.Atoi(precision); err == nil {
+		for len(digits) < p {
+			digits = "0" + digits
+		}
+	}
+	w, _ := strconv.Atoi(width)
+	for len(digits) < w {
+		switch {
+		case left:
+			digits += " "
+		case zero:
+			digits = "0" + digits
+		default:
+			digits = " " + digits
+		}
+	}
+	return digits
+}
@@ -0,0 +1,340 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package terminfo reads compiled terminfo entries and expands their
+// parameterized strings without the help of the C library. It is used by
+// the pure Go build of goncurses.
+package terminfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Numeric capabilities, by their index in a compiled entry
+const (
+	Columns   = 0
+	Lines     = 2
+	MaxColors = 13
+	MaxPairs  = 14
+)
+
+// String capabilities, by their index in a compiled entry
+const (
+	Bell               = 1
+	CarriageReturn     = 2
+	ClearScreen        = 5
+	ClrEOL             = 6
+	ClrEOS             = 7
+	CursorAddress      = 10
+	CursorDown         = 11
+	CursorHome         = 12
+	CursorInvisible    = 13
+	CursorNormal       = 16
+	CursorRight        = 17
+	CursorVisible      = 20
+	EnterBlinkMode     = 26
+	EnterBoldMode      = 27
+	EnterCAMode        = 28
+	EnterDimMode       = 30
+	EnterSecureMode    = 32
+	EnterReverseMode   = 34
+	EnterStandoutMode  = 35
+	EnterUnderlineMode = 36
+	ExitAttributeMode  = 39
+	ExitCAMode         = 40
+	FlashScreen        = 45
+	KeypadLocal        = 88
+	KeypadXmit         = 89
+	Newline            = 103
+	OrigPair           = 297
+	SetForeground      = 302
+	SetBackground      = 303
+	EnterItalicsMode   = 311
+	SetAForeground     = 359
+	SetABackground     = 360
+)
+
+// Key capabilities, by their index in a compiled entry. Each is the string
+// sent by the terminal when the key is pressed
+const (
+	KeyBackspace = 55
+	KeyCatab     = 56
+	KeyClear     = 57
+	KeyCtab      = 58
+	KeyDc        = 59
+	KeyDl        = 60
+	KeyDown      = 61
+	KeyEic       = 62
+	KeyEol       = 63
+	KeyEos       = 64
+	KeyF1        = 66
+	KeyF10       = 67
+	KeyF2        = 68
+	KeyF3        = 69
+	KeyF4        = 70
+	KeyF5        = 71
+	KeyF6        = 72
+	KeyF7        = 73
+	KeyF8        = 74
+	KeyF9        = 75
+	KeyHome      = 76
+	KeyIc        = 77
+	KeyIl        = 78
+	KeyLeft      = 79
+	KeyLl        = 80
+	KeyNpage     = 81
+	KeyPpage     = 82
+	KeyRight     = 83
+	KeySf        = 84
+	KeySr        = 85
+	KeyStab      = 86
+	KeyUp        = 87
+	KeyA1        = 139
+	KeyA3        = 140
+	KeyB2        = 141
+	KeyC1        = 142
+	KeyC3        = 143
+	KeyBtab      = 148
+	KeyBeg       = 158
+	KeyCancel    = 159
+	KeyClose     = 160
+	KeyCommand   = 161
+	KeyCopy      = 162
+	KeyCreate    = 163
+	KeyEnd       = 164
+	KeyEnter     = 165
+	KeyExit      = 166
+	KeyFind      = 167
+	KeyHelp      = 168
+	KeyMark      = 169
+	KeyMessage   = 170
+	KeyMove      = 171
+	KeyNext      = 172
+	KeyOpen      = 173
+	KeyOptions   = 174
+	KeyPrevious  = 175
+	KeyPrint     = 176
+	KeyRedo      = 177
+	KeyReference = 178
+	KeyRefresh   = 179
+	KeyReplace   = 180
+	KeyRestart   = 181
+	KeyResume    = 182
+	KeySave      = 183
+	KeySuspend   = 184
+	KeyUndo      = 185
+	KeySbeg      = 186
+	KeyScancel   = 187
+	KeyScommand  = 188
+	KeyScopy     = 189
+	KeyScreate   = 190
+	KeySdc       = 191
+	KeySdl       = 192
+	KeySelect    = 193
+	KeySend      = 194
+	KeySeol      = 195
+	KeySexit     = 196
+	KeySfind     = 197
+	KeyShelp     = 198
+	KeyShome     = 199
+	KeySic       = 200
+	KeySleft     = 201
+	KeySmessage  = 202
+	KeySmove     = 203
+	KeySnext     = 204
+	KeySoptions  = 205
+	KeySprevious = 206
+	KeySprint    = 207
+	KeySredo     = 208
+	KeySreplace  = 209
+	KeySright    = 210
+	KeySrsume    = 211
+	KeySsave     = 212
+	KeySsuspend  = 213
+	KeySundo     = 214
+	KeyF11       = 216
+	KeyF12       = 217
+	KeyMouse     = 355
+)
+
+// Terminfo is a compiled terminfo entry
+type Terminfo struct {
+	// Names holds the names of the terminal, the last of which is usually
+	// a description
+	Names   []string
+	flags   []bool
+	numbers []int
+	strings []string
+}
+
+// ErrNotFound is returned by Load when there is no entry for the terminal
+var ErrNotFound = errors.New("terminfo: terminal not found")
+
+// dirs returns the directories searched for entries, in the order ncurses
+// searches them
+func dirs() []string {
+	var d []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		d = append(d, dir)
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		d = append(d, filepath.Join(home, ".terminfo"))
+	}
+	system := []string{"/etc/terminfo", "/lib/terminfo",
+		"/usr/share/terminfo", "/usr/lib/terminfo"}
+	if list, ok := os.LookupEnv("TERMINFO_DIRS"); ok {
+		for _, dir := range strings.Split(list, ":") {
+			if dir == "" {
+				d = append(d, system...)
+			} else {
+				d = append(d, dir)
+			}
+		}
+		return d
+	}
+	return append(d, system...)
+}
+
+// Load finds and parses the entry for the terminal named name
+func Load(name string) (*Terminfo, error) {
+	if name == "" || strings.ContainsAny(name, "/\\") {
+		return nil, ErrNotFound
+	}
+	for _, dir := range dirs() {
+		// entries are filed under their first letter, or its value in hex
+		// on case insensitive file systems
+		for _, sub := range []string{name[:1], hexByte(name[0])} {
+			data, err := ioutil.ReadFile(filepath.Join(dir, sub, name))
+			if err == nil {
+				return Parse(data)
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// hexByte returns b as two hexadecimal digits
+func hexByte(b byte) string {
+	const digits = "0123456789abcdef"
+	return string([]byte{digits[b>>4], digits[b&0xf]})
+}
+
+// Parse parses a compiled terminfo entry. Both the legacy format and the
+// one with 32-bit numbers are understood; extended capabilities are
+// ignored
+func Parse(data []byte) (*Terminfo, error) {
+	errFormat := errors.New("terminfo: invalid compiled entry")
+	if len(data) < 12 {
+		return nil, errFormat
+	}
+	header := make([]int, 6)
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(data[i*2:])))
+	}
+	numSize := 2
+	switch header[0] {
+	case 0432:
+	case 01036:
+		numSize = 4
+	default:
+		return nil, errFormat
+	}
+	nameSize, flagCount, numCount := header[1], header[2], header[3]
+	strCount, tableSize := header[4], header[5]
+	if nameSize < 0 || flagCount < 0 || numCount < 0 || strCount < 0 ||
+		tableSize < 0 {
+		return nil, errFormat
+	}
+
+	pos := 12
+	next := func(n int) ([]byte, bool) {
+		if pos+n > len(data) {
+			return nil, false
+		}
+		b := data[pos : pos+n]
+		pos += n
+		return b, true
+	}
+	names, ok := next(nameSize)
+	if !ok {
+		return nil, errFormat
+	}
+	t := &Terminfo{Names: strings.Split(strings.TrimRight(string(names),
+		"\x00"), "|")}
+
+	flags, ok := next(flagCount)
+	if !ok {
+		return nil, errFormat
+	}
+	t.flags = make([]bool, flagCount)
+	for i, b := range flags {
+		t.flags[i] = b == 1
+	}
+	// numbers start on an even byte
+	if pos%2 == 1 {
+		pos++
+	}
+	nums, ok := next(numCount * numSize)
+	if !ok {
+		return nil, errFormat
+	}
+	t.numbers = make([]int, numCount)
+	for i := range t.numbers {
+		if numSize == 4 {
+			t.numbers[i] = int(int32(binary.LittleEndian.Uint32(nums[i*4:])))
+		} else {
+			t.numbers[i] = int(int16(binary.LittleEndian.Uint16(nums[i*2:])))
+		}
+	}
+	offsets, ok := next(strCount * 2)
+	if !ok {
+		return nil, errFormat
+	}
+	table, ok := next(tableSize)
+	if !ok {
+		return nil, errFormat
+	}
+	t.strings = make([]string, strCount)
+	for i := range t.strings {
+		off := int(int16(binary.LittleEndian.Uint16(offsets[i*2:])))
+		if off < 0 || off >= len(table) {
+			// absent or cancelled
+			continue
+		}
+		end := off
+		for end < len(table) && table[end] != 0 {
+			end++
+		}
+		t.strings[i] = string(table[off:end])
+	}
+	return t, nil
+}
+
+// Flag returns the value of the boolean capability i
+func (t *Terminfo) Flag(i int) bool {
+	return i < len(t.flags) && t.flags[i]
+}
+
+// Number returns the value of the numeric capability i, or -1 if the
+// terminal does not have it
+func (t *Terminfo) Number(i int) int {
+	if i >= len(t.numbers) || t.numbers[i] < 0 {
+		return -1
+	}
+	return t.numbers[i]
+}
+
+// String returns the value of the string capability i, or an empty string
+// if the terminal does not have it
+func (t *Terminfo) String(i int) string {
+	if i >= len(t.strings) {
+		return ""
+	}
+	return t.strings[i]
+}
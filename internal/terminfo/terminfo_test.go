@@ -0,0 +1,61 @@
+package terminfo_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses/internal/terminfo"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		s      string
+		params []int
+		want   string
+	}{
+		{"\x1b[%i%p1%d;%p2%dH", []int{4, 9}, "\x1b[5;10H"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m",
+			[]int{3}, "\x1b[33m"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m",
+			[]int{12}, "\x1b[94m"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m",
+			[]int{200}, "\x1b[38;5;200m"},
+		{"%p1%03d|%p1%:-4d|%p1%x|%p1%X", []int{42}, "042|42  |2a|2A"},
+		{"%p1%Pa%ga%ga%+%d", []int{21}, "42"},
+		{"%'A'%c%p1%c", []int{'z'}, "Az"},
+		{"\x1b[?5h$<100/>\x1b[?5l", nil, "\x1b[?5h\x1b[?5l"},
+		{"%p1%!%d%p1%~%d%%", []int{0}, "1-1%"},
+	}
+	for _, test := range tests {
+		if got := terminfo.Expand(test.s, test.params...); got != test.want {
+			t.Errorf("expected %q from %q%v, got %q", test.want, test.s,
+				test.params, got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ti, err := terminfo.Load("xterm-256color")
+	if err == terminfo.ErrNotFound {
+		t.Skip("no terminfo entry for xterm-256color")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Names[0] != "xterm-256color" {
+		t.Errorf("expected the first name to be xterm-256color, got %q",
+			ti.Names[0])
+	}
+	if n := ti.Number(terminfo.MaxColors); n != 256 {
+		t.Errorf("expected 256 colors, got %d", n)
+	}
+	if s := ti.String(terminfo.KeyUp); s != "\x1bOA" {
+		t.Errorf("expected the up key to be \\eOA, got %q", s)
+	}
+	cup := ti.String(terminfo.CursorAddress)
+	if s := terminfo.Expand(cup, 1, 2); s != "\x1b[2;3H" {
+		t.Errorf("expected cup to expand to \\e[2;3H, got %q", s)
+	}
+	if _, err := terminfo.Load("no-such-terminal"); err != terminfo.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
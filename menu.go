@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build !windows
+// +build !windows,!purego
 
 package goncurses
 
@@ -10,10 +10,27 @@ package goncurses
 #cgo !darwin,!openbsd pkg-config: menu
 #cgo darwin openbsd LDFLAGS: -lmenu
 #include <menu.h>
+#include <stdint.h>
 #include <stdlib.h>
 
 ITEM* menu_item_at(ITEM** ilist, int i) {
 	return ilist[i];
+}
+
+static ITEM** goncurses_new_items(int n) {
+	return calloc(n + 1, sizeof(ITEM *));
+}
+
+static void goncurses_set_item_at(ITEM** ilist, int i, ITEM* item) {
+	ilist[i] = item;
+}
+
+static void goncurses_set_item_handle(ITEM *i, uintptr_t h) {
+	set_item_userptr(i, (void *)h);
+}
+
+static uintptr_t goncurses_item_handle(ITEM *i) {
+	return (uintptr_t)item_userptr(i);
 }*/
 import "C"
 
@@ -23,24 +40,65 @@ import (
 )
 
 type Menu struct {
-	menu *C.MENU
+	menu      *C.MENU
+	items     **C.ITEM // array handed to libmenu, owned by the menu
+	itemEnter func(*MenuItem)
+	itemLeave func(*MenuItem)
+	post      func()
+	unpost    func()
+	activate  func(*MenuItem)
+	repost    bool // the menu was posted when it ran out of items
 }
 
 type MenuItem struct {
 	item *C.ITEM
 }
 
-// NewMenu returns a pointer to a new menu.
-func NewMenu(items []*MenuItem) (*Menu, error) {
-	citems := make([]*C.ITEM, len(items)+1)
+// menuItems maps each C item to the MenuItem created for it so that the
+// same *MenuItem is always returned for a given item. Items are added by
+// NewItem and removed by Free
+var menuItems = map[*C.ITEM]*MenuItem{}
+
+// itemFor returns the MenuItem wrapping ci, or nil if ci is nil
+func itemFor(ci *C.ITEM) *MenuItem {
+	if ci == nil {
+		return nil
+	}
+	mi, ok := menuItems[ci]
+	if !ok {
+		mi = &MenuItem{ci}
+		menuItems[ci] = mi
+	}
+	return mi
+}
+
+// newItemList copies items into a NULL terminated array allocated in C,
+// since libmenu keeps a reference to it for as long as the items are
+// attached. An empty list is returned as nil
+func newItemList(items []*MenuItem) **C.ITEM {
+	if len(items) == 0 {
+		return nil
+	}
+	citems := C.goncurses_new_items(C.int(len(items)))
 	for index, item := range items {
-		citems[index] = item.item
+		C.goncurses_set_item_at(citems, C.int(index), item.item)
 	}
-	citems[len(items)] = nil
+	return citems
+}
+
+// NewMenu returns a pointer to a new menu.
+func NewMenu(items []*MenuItem) (*Menu, error) {
+	citems := newItemList(items)
 	var menu *C.MENU
 	var err error
-	menu, err = C.new_menu((**C.ITEM)(&citems[0]))
-	return &Menu{menu}, ncursesError(err)
+	menu, err = C.new_menu(citems)
+	if menu == nil {
+		C.free(unsafe.Pointer(citems))
+		return nil, ncursesError(err)
+	}
+	m := &Menu{menu: menu, items: citems}
+	menus[menu] = m
+	return m, nil
 }
 
 // RequestName of menu request code
@@ -59,9 +117,9 @@ func RequestByName(request string) (res int, err error) {
 	return
 }
 
-// Background returns the menu's background character setting
-func (m *Menu) Background() int {
-	return int(C.menu_back(m.menu))
+// Background returns the attributes of the un-highlighted items in the menu
+func (m *Menu) Background() Char {
+	return Char(C.menu_back(m.menu))
 }
 
 // Count returns the number of MenuItems in the Menu
@@ -69,10 +127,12 @@ func (m *Menu) Count() int {
 	return int(C.item_count(m.menu))
 }
 
-// Current returns the selected item in the menu
+// Current returns the selected item in the menu when passed nil, otherwise
+// it makes mi the current item. The item returned is the same *MenuItem
+// which was returned by NewItem
 func (m *Menu) Current(mi *MenuItem) *MenuItem {
 	if mi == nil {
-		return &MenuItem{C.current_item(m.menu)}
+		return itemFor(C.current_item(m.menu))
 	}
 	C.set_current_item(m.menu, mi.item)
 	return nil
@@ -86,8 +146,8 @@ func (m *Menu) Driver(daction MenuDriverReq) error {
 }
 
 // Foreground gets the attributes of highlighted items in the menu
-func (m *Menu) Foreground() int {
-	return int(C.menu_fore(m.menu))
+func (m *Menu) Foreground() Char {
+	return Char(C.menu_fore(m.menu))
 }
 
 // Format sets the menu format. See the O_* menu options.
@@ -96,10 +156,23 @@ func (m *Menu) Format(r, c int) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// FormatSize returns the maximum number of rows and columns of items the
+// menu displays at once, as set by Format
+func (m *Menu) FormatSize() (rows, cols int) {
+	var r, c C.int
+	C.menu_format(m.menu, &r, &c)
+	return int(r), int(c)
+}
+
 // Free deallocates memory set aside for the menu. This must be called
 // before exiting.
 func (m *Menu) Free() error {
 	err := C.free_menu(m.menu)
+	if err == C.E_OK {
+		delete(menus, m.menu)
+		C.free(unsafe.Pointer(m.items))
+		m.items = nil
+	}
 	m = nil
 	return ncursesError(syscall.Errno(err))
 }
@@ -109,13 +182,50 @@ func (m *Menu) Grey(ch Char) {
 	C.set_menu_grey(m.menu, C.chtype(ch))
 }
 
-// Items will return the items in the menu.
+// GreyAttr returns the attributes of non-selectable items in the menu, as
+// set by Grey
+func (m *Menu) GreyAttr() Char {
+	return Char(C.menu_grey(m.menu))
+}
+
+// HandleKey translates a key returned by GetChar into a menu request using
+// DriverActions and issues it. When the menu allows more than one item to be
+// selected (O_ONEVALUE is off) the space bar toggles the current item.
+// Backspace removes the last character from the pattern buffer and other
+// printable characters are added to it. KEY_MOUSE is passed on to
+// HandleMouse. Any other key is rejected with an unknown command error,
+// since keys beyond KEY_MAX share their codes with the menu requests.
+func (m *Menu) HandleKey(k Key) error {
+	if k == ' ' && C.menu_opts(m.menu)&C.O_ONEVALUE == 0 {
+		return m.Driver(REQ_TOGGLE)
+	}
+	if req, ok := DriverActions[k]; ok {
+		return m.Driver(req)
+	}
+	switch k {
+	case KEY_BACKSPACE, 127, 8:
+		return m.Driver(REQ_BACK_PATTERN)
+	case KEY_MOUSE:
+		if ev := GetMouse(); ev != nil {
+			return m.HandleMouse(ev)
+		}
+		return nil
+	}
+	if !printable(k) {
+		return errUnknownCommand
+	}
+	err := C.menu_driver(m.menu, C.int(k))
+	return ncursesError(syscall.Errno(err))
+}
+
+// Items will return the items in the menu. These are the same *MenuItem
+// values which were passed to NewMenu or SetItems.
 func (m *Menu) Items() []*MenuItem {
 	citems := C.menu_items(m.menu)
 	count := m.Count()
 	mitems := make([]*MenuItem, count)
 	for index := 0; index < count; index++ {
-		mitems[index] = &MenuItem{C.menu_item_at(citems, C.int(index))}
+		mitems[index] = itemFor(C.menu_item_at(citems, C.int(index)))
 	}
 	return mitems
 }
@@ -129,9 +239,15 @@ func (m *Menu) Mark(mark string) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// MarkString returns the indicator for the currently selected menu item, as
+// set by Mark
+func (m *Menu) MarkString() string {
+	return C.GoString(C.menu_mark(m.menu))
+}
+
 // Option sets the options for the menu. See the O_* definitions for
 // a list of values which can be OR'd together
-func (m *Menu) Option(opts int, on bool) error {
+func (m *Menu) Option(opts MenuOptions, on bool) error {
 	var err C.int
 	if on {
 		err = C.menu_opts_on(m.menu, C.Menu_Options(opts))
@@ -141,6 +257,11 @@ func (m *Menu) Option(opts int, on bool) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// CurrentOptions returns the options currently set on the menu
+func (m *Menu) CurrentOptions() MenuOptions {
+	return MenuOptions(C.menu_opts(m.menu))
+}
+
 // Pad sets the padding character for menu items.
 func (m *Menu) Pad() int {
 	return int(C.menu_pad(m.menu))
@@ -169,6 +290,52 @@ func (m *Menu) Scale() (int, int, error) {
 	return int(y), int(x), ncursesError(syscall.Errno(err))
 }
 
+// Selected returns the items which are toggled on, in menu order. Menus with
+// O_ONEVALUE set have no toggled items; for these the current item is
+// returned instead.
+func (m *Menu) Selected() []*MenuItem {
+	if C.menu_opts(m.menu)&C.O_ONEVALUE != 0 {
+		if cur := m.Current(nil); cur != nil {
+			return []*MenuItem{cur}
+		}
+		return nil
+	}
+	var selected []*MenuItem
+	for _, item := range m.Items() {
+		if item.Value() {
+			selected = append(selected, item)
+		}
+	}
+	return selected
+}
+
+// SelectAll toggles on every selectable item. The menu must have O_ONEVALUE
+// turned off
+func (m *Menu) SelectAll() error {
+	return m.setValues(true)
+}
+
+// ClearSelection toggles off every item. The menu must have O_ONEVALUE
+// turned off
+func (m *Menu) ClearSelection() error {
+	return m.setValues(false)
+}
+
+// setValues toggles every selectable item on or off. libmenu refuses to
+// change the value of an item which is not selectable, so these are skipped
+func (m *Menu) setValues(val bool) error {
+	for _, item := range m.Items() {
+		if C.item_opts(item.item)&C.O_SELECTABLE == 0 {
+			continue
+		}
+		err := C.set_item_value(item.item, C.bool(val))
+		if err != C.E_OK {
+			return ncursesError(syscall.Errno(err))
+		}
+	}
+	return nil
+}
+
 // SetBackground set the attributes of the un-highlighted items in the
 // menu
 func (m *Menu) SetBackground(ch Char) error {
@@ -183,14 +350,25 @@ func (m *Menu) SetForeground(ch Char) error {
 }
 
 // SetItems will either set the items in the menu. When setting
-// items you must make sure the prior menu items will be freed.
+// items you must make sure the prior menu items will be freed. The menu
+// must not be posted. See MenuModel for a way to change the items of a
+// posted menu.
 func (m *Menu) SetItems(items []*MenuItem) error {
-	citems := make([]*C.ITEM, len(items)+1)
-	for index, item := range items {
-		citems[index] = item.item
+	citems := newItemList(items)
+	err := C.set_menu_items(m.menu, citems)
+	if err != C.E_OK {
+		C.free(unsafe.Pointer(citems))
+		return ncursesError(syscall.Errno(err))
 	}
-	citems[len(items)] = nil
-	err := C.set_menu_items(m.menu, (**C.ITEM)(&citems[0]))
+	C.free(unsafe.Pointer(m.items))
+	m.items = citems
+	return nil
+}
+
+// SetOptions replaces all of the menu's options with opts. Options may not
+// be changed while the menu is posted
+func (m *Menu) SetOptions(opts MenuOptions) error {
+	err := C.set_menu_opts(m.menu, C.Menu_Options(opts))
 	return ncursesError(syscall.Errno(err))
 }
 
@@ -220,6 +398,13 @@ func (m *Menu) SetSpacing(desc, row, col int) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// SetTopRow scrolls the menu so that row is the first row displayed. The
+// current item becomes the first item on that row
+func (m *Menu) SetTopRow(row int) error {
+	err := C.set_top_row(m.menu, C.int(row))
+	return ncursesError(syscall.Errno(err))
+}
+
 // SetWindow container for the menu
 func (m *Menu) SetWindow(w *Window) error {
 	err := C.set_menu_win(m.menu, w.win)
@@ -235,12 +420,23 @@ func (m *Menu) Spacing() (int, int, int) {
 	return int(desc), int(row), int(col)
 }
 
+// Sub returns the sub-window the menu items are drawn in. If none was set
+// with SubWindow this is the menu's window
+func (m *Menu) Sub() *Window {
+	return &Window{win: C.menu_sub(m.menu)}
+}
+
 // SubWindow for the menu
 func (m *Menu) SubWindow(sub *Window) error {
 	err := C.set_menu_sub(m.menu, sub.win)
 	return ncursesError(syscall.Errno(err))
 }
 
+// TopRow returns the first row of items currently displayed
+func (m *Menu) TopRow() int {
+	return int(C.top_row(m.menu))
+}
+
 // UnPost the menu, effectively hiding it.
 func (m *Menu) UnPost() error {
 	err := C.unpost_menu(m.menu)
@@ -249,7 +445,7 @@ func (m *Menu) UnPost() error {
 
 // Window container for the menu. Returns nil on failure
 func (m *Menu) Window() *Window {
-	return &Window{C.menu_win(m.menu)}
+	return &Window{win: C.menu_win(m.menu)}
 }
 
 // NewItem creates a new menu item with name and description.
@@ -260,7 +456,12 @@ func NewItem(name, desc string) (*MenuItem, error) {
 	var item *C.ITEM
 	var err error
 	item, err = C.new_item(cname, cdesc)
-	return &MenuItem{item}, ncursesError(err)
+	if item == nil {
+		C.free(unsafe.Pointer(cname))
+		C.free(unsafe.Pointer(cdesc))
+		return nil, ncursesError(err)
+	}
+	return itemFor(item), nil
 }
 
 // Description returns the second value passed to NewItem
@@ -268,10 +469,22 @@ func (mi *MenuItem) Description() string {
 	return C.GoString(C.item_description(mi.item))
 }
 
-// Free must be called on all menu items to avoid memory leaks
-func (mi *MenuItem) Free() {
-	C.free(unsafe.Pointer(C.item_name(mi.item)))
-	C.free_item(mi.item)
+// Free must be called on all menu items to avoid memory leaks. An item
+// which is still connected to a menu can not be freed and is left as it
+// was; free the menu first
+func (mi *MenuItem) Free() error {
+	name, desc := C.item_name(mi.item), C.item_description(mi.item)
+	h := uintptr(C.goncurses_item_handle(mi.item))
+	if err := C.free_item(mi.item); err != C.E_OK {
+		return ncursesError(syscall.Errno(err))
+	}
+	if h != 0 {
+		userData.remove(h)
+	}
+	delete(menuItems, mi.item)
+	C.free(unsafe.Pointer(name))
+	C.free(unsafe.Pointer(desc))
+	return nil
 }
 
 // Index of the menu item in it's parent menu
@@ -284,13 +497,45 @@ func (mi *MenuItem) Name() string {
 	return C.GoString(C.item_name(mi.item))
 }
 
-// Selectable turns on/off whether a menu option is "greyed out"
-func (mi *MenuItem) Selectable(on bool) {
+// Option turns the given item option(s) on or off
+func (mi *MenuItem) Option(opts ItemOptions, on bool) error {
+	var err C.int
 	if on {
-		C.item_opts_on(mi.item, O_SELECTABLE)
+		err = C.item_opts_on(mi.item, C.Item_Options(opts))
 	} else {
-		C.item_opts_off(mi.item, O_SELECTABLE)
+		err = C.item_opts_off(mi.item, C.Item_Options(opts))
+	}
+	return ncursesError(syscall.Errno(err))
+}
+
+// CurrentOptions returns the options currently set on the item
+func (mi *MenuItem) CurrentOptions() ItemOptions {
+	return ItemOptions(C.item_opts(mi.item))
+}
+
+// Selectable turns on/off whether a menu option is "greyed out"
+func (mi *MenuItem) Selectable(on bool) {
+	mi.Option(O_SELECTABLE, on)
+}
+
+// SetOptions replaces all of the item's options with opts
+func (mi *MenuItem) SetOptions(opts ItemOptions) error {
+	err := C.set_item_opts(mi.item, C.Item_Options(opts))
+	return ncursesError(syscall.Errno(err))
+}
+
+// SetUserData associates an arbitrary value, such as the domain object the
+// item represents, with the item. Pass nil to remove it. The value is
+// released when the item is freed
+func (mi *MenuItem) SetUserData(v interface{}) {
+	if h := uintptr(C.goncurses_item_handle(mi.item)); h != 0 {
+		userData.remove(h)
+	}
+	var h uintptr
+	if v != nil {
+		h = userData.add(v)
 	}
+	C.goncurses_set_item_handle(mi.item, C.uintptr_t(h))
 }
 
 // SetValue sets whether an item is active or not
@@ -299,6 +544,11 @@ func (mi *MenuItem) SetValue(val bool) error {
 	return ncursesError(syscall.Errno(err))
 }
 
+// UserData returns the value set by SetUserData, or nil
+func (mi *MenuItem) UserData() interface{} {
+	return userData.get(uintptr(C.goncurses_item_handle(mi.item)))
+}
+
 // Value returns true if menu item is toggled/active, otherwise false
 func (mi *MenuItem) Value() bool {
 	return bool(C.item_value(mi.item))
@@ -0,0 +1,328 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+/*
+#include <menu.h>
+
+static void goncurses_highlight(WINDOW *w, int y, int x, int n, chtype attr) {
+	chtype c = mvwinch(w, y, x);
+	short pair = PAIR_NUMBER(c);
+
+	if (attr & A_COLOR)
+		pair = PAIR_NUMBER(attr);
+	mvwchgat(w, y, x, n, (c | attr) & A_ATTRIBUTES & ~A_COLOR, pair, NULL);
+}
+*/
+import "C"
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyBonusBoundary    = 8
+	fuzzyBonusConsecutive = 4
+	fuzzyPenaltyGap       = 1
+	fuzzyMaxGapPenalty    = 8
+	fuzzyMaxLeadPenalty   = 8
+)
+
+// FuzzyMatch reports whether the runes of pattern appear in order, though
+// not necessarily next to each other, in s. Case is ignored. On a match,
+// score ranks how good the match is, higher being better, and positions
+// holds the index of each matched rune in s. Matches at the start of
+// words and runs of consecutive characters score highest, gaps between
+// matched characters lower the score. An empty pattern matches anything
+// with a score of zero.
+func FuzzyMatch(pattern, s string) (score int, positions []int, ok bool) {
+	p := []rune(pattern)
+	for i := range p {
+		p[i] = unicode.ToLower(p[i])
+	}
+	r := []rune(s)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	// Find the earliest point at which the whole pattern has matched, then
+	// work back from there to find the shortest run of s holding it
+	pi, end := 0, -1
+	for i, c := range r {
+		if unicode.ToLower(c) == p[pi] {
+			pi++
+			if pi == len(p) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for i, pi := end, len(p)-1; pi >= 0; i-- {
+		if unicode.ToLower(r[i]) == p[pi] {
+			start = i
+			pi--
+		}
+	}
+
+	positions = make([]int, 0, len(p))
+	for i, pi := start, 0; pi < len(p); i++ {
+		if unicode.ToLower(r[i]) != p[pi] {
+			continue
+		}
+		score += fuzzyScoreMatch
+		if fuzzyBoundary(r, i) {
+			score += fuzzyBonusBoundary
+		}
+		if n := len(positions); n > 0 {
+			if gap := i - positions[n-1] - 1; gap == 0 {
+				score += fuzzyBonusConsecutive
+			} else if gap*fuzzyPenaltyGap < fuzzyMaxGapPenalty {
+				score -= gap * fuzzyPenaltyGap
+			} else {
+				score -= fuzzyMaxGapPenalty
+			}
+		}
+		positions = append(positions, i)
+		pi++
+	}
+	if start < fuzzyMaxLeadPenalty {
+		score -= start
+	} else {
+		score -= fuzzyMaxLeadPenalty
+	}
+	return score, positions, true
+}
+
+// fuzzyBoundary reports whether r[i] starts a word
+func fuzzyBoundary(r []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, c := r[i-1], r[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return unicode.IsLetter(c) || unicode.IsDigit(c)
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(c)
+}
+
+// FuzzyFilter narrows the items shown in a menu to those which fuzzy match
+// a query as it is typed, best matches first, and highlights the matched
+// characters. It is an alternative to the menu's own pattern buffer, which
+// only moves to the first item with a matching prefix.
+//
+// The filter takes over the menu's items: the full list is kept by the
+// filter and only the matching items are attached to the menu. Items must
+// not be added to or removed from the menu while it is being filtered.
+type FuzzyFilter struct {
+	menu      *Menu
+	all       []*MenuItem
+	query     []rune
+	positions map[*MenuItem][]int
+	attr      Char
+	pending   []byte
+}
+
+// NewFuzzyFilter returns a filter for the items currently in m. The
+// matched characters are underlined until changed by SetHighlight
+func NewFuzzyFilter(m *Menu) *FuzzyFilter {
+	return &FuzzyFilter{
+		menu:      m,
+		all:       m.Items(),
+		positions: map[*MenuItem][]int{},
+		attr:      A_UNDERLINE,
+	}
+}
+
+// Items returns all of the items being filtered, in their original order
+func (f *FuzzyFilter) Items() []*MenuItem {
+	return append([]*MenuItem(nil), f.all...)
+}
+
+// Query returns the text items are currently matched against
+func (f *FuzzyFilter) Query() string {
+	return string(f.query)
+}
+
+// SetHighlight sets the attributes added to matched characters. If attr
+// includes a color pair it replaces the pair the character is drawn with
+func (f *FuzzyFilter) SetHighlight(attr Char) {
+	f.attr = attr
+}
+
+// SetQuery filters the menu's items against query. The best match becomes
+// the current item. An empty query restores the full list of items. If no
+// items match, the menu is left empty and, if it was posted, is posted
+// again once the query matches something
+func (f *FuzzyFilter) SetQuery(query string) error {
+	f.query = []rune(query)
+
+	type match struct {
+		item  *MenuItem
+		score int
+	}
+	matches := make([]match, 0, len(f.all))
+	f.positions = map[*MenuItem][]int{}
+	for _, item := range f.all {
+		score, pos, ok := FuzzyMatch(query, item.Name())
+		if ok {
+			matches = append(matches, match{item, score})
+			f.positions[item] = pos
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	list := make([]*MenuItem, len(matches))
+	for i, m := range matches {
+		list[i] = m.item
+	}
+
+	if err := f.menu.swapItems(list, nil); err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		C.set_current_item(f.menu.menu, list[0].item)
+	}
+	f.Highlight()
+	return nil
+}
+
+// Driver issues a menu request. REQ_BACK_PATTERN and REQ_CLEAR_PATTERN
+// remove the last character of the query and clear it, respectively;
+// other requests are passed to the menu, after which the highlighting is
+// redrawn
+func (f *FuzzyFilter) Driver(req MenuDriverReq) error {
+	switch req {
+	case REQ_BACK_PATTERN:
+		if len(f.query) == 0 {
+			return nil
+		}
+		return f.SetQuery(string(f.query[:len(f.query)-1]))
+	case REQ_CLEAR_PATTERN:
+		return f.SetQuery("")
+	}
+	err := f.menu.Driver(req)
+	f.Highlight()
+	return err
+}
+
+// HandleKey adds printable characters to the query, including UTF-8
+// encoded characters read a byte at a time by GetChar, and removes the last
+// one on backspace. In menus which allow more than one item to be
+// selected the space bar still toggles the current item. Other keys are
+// handled by the menu's HandleKey
+func (f *FuzzyFilter) HandleKey(k Key) error {
+	switch {
+	case k == KEY_BACKSPACE || k == 127 || k == 8:
+		f.pending = f.pending[:0]
+		return f.Driver(REQ_BACK_PATTERN)
+	case k == ' ' && f.menu.CurrentOptions()&O_ONEVALUE == 0:
+	case k >= ' ' && k <= 0xff:
+		// characters arrive a byte at a time
+		f.pending = append(f.pending, byte(k))
+		if !utf8.FullRune(f.pending) {
+			return nil
+		}
+		r, _ := utf8.DecodeRune(f.pending)
+		f.pending = f.pending[:0]
+		if r == utf8.RuneError {
+			return nil
+		}
+		return f.SetQuery(string(append(f.query, r)))
+	}
+	err := f.menu.HandleKey(k)
+	f.Highlight()
+	return err
+}
+
+// Highlight draws the highlighting of matched characters on the visible
+// items of a posted menu. libmenu redraws items when the menu is driven,
+// which removes the highlighting, so this must be called after any request
+// made directly on the menu. The window needs to be refreshed afterwards
+func (f *FuzzyFilter) Highlight() {
+	if len(f.query) == 0 || C.pos_menu_cursor(f.menu.menu) != C.E_OK {
+		return
+	}
+	defer C.pos_menu_cursor(f.menu.menu)
+
+	sub := C.menu_sub(f.menu.menu)
+	layout := f.menu.itemLayout()
+	for _, item := range f.menu.Items() {
+		if !item.Visible() {
+			continue
+		}
+		y, x := layout.pos(item.Index())
+		name := []rune(item.Name())
+		for _, pos := range f.positions[item] {
+			n := runeWidth(name[pos])
+			if n == 0 {
+				continue
+			}
+			col := x + stringWidth(string(name[:pos]))
+			C.goncurses_highlight(sub, C.int(y), C.int(col), C.int(n),
+				C.chtype(f.attr))
+		}
+	}
+}
+
+// menuLayout describes where libmenu draws the items of a menu in its
+// sub-window
+type menuLayout struct {
+	rowMajor   bool
+	rows, cols int // rows and columns of items in the whole menu
+	top        int // first row of items displayed
+	spcRows    int // screen rows taken by each row of items
+	width      int // columns taken by each item, including its mark
+	spcCols    int // blank columns between columns of items
+	mark       int // width of the mark
+}
+
+// itemLayout works out the layout of the menu's items the way libmenu
+// does when they are connected to the menu
+func (m *Menu) itemLayout() menuLayout {
+	var l menuLayout
+	n := m.Count()
+	if n == 0 {
+		return l
+	}
+	l.rowMajor = m.CurrentOptions()&O_ROWMAJOR != 0
+	_, fcols := m.FormatSize()
+	l.rows = (n-1)/fcols + 1
+	if l.rowMajor {
+		l.cols = fcols
+		if n < fcols {
+			l.cols = n
+		}
+	} else {
+		l.cols = (n-1)/l.rows + 1
+	}
+	l.top = m.TopRow()
+	_, l.spcRows, l.spcCols = m.Spacing()
+	l.mark = stringWidth(m.MarkString())
+
+	var height, width C.int
+	C.scale_menu(m.menu, &height, &width)
+	l.width = (int(width) - (l.cols-1)*l.spcCols) / l.cols
+	return l
+}
+
+// pos returns the row and column in the menu's sub-window at which the
+// name of the item at index is drawn
+func (l menuLayout) pos(index int) (y, x int) {
+	row, col := index/l.cols, index%l.cols
+	if !l.rowMajor {
+		row, col = index%l.rows, index/l.rows
+	}
+	return (row - l.top) * l.spcRows, col*(l.width+l.spcCols) + l.mark
+}
@@ -0,0 +1,36 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+#include <menu.h>
+#include "menu_hooks.h"
+#include "_cgo_export.h"
+
+static void goncurses_item_init(MENU *m) {
+	goncursesMenuHook(m, GONCURSES_ITEM_INIT);
+}
+static void goncurses_item_term(MENU *m) {
+	goncursesMenuHook(m, GONCURSES_ITEM_TERM);
+}
+static void goncurses_menu_init(MENU *m) {
+	goncursesMenuHook(m, GONCURSES_MENU_INIT);
+}
+static void goncurses_menu_term(MENU *m) {
+	goncursesMenuHook(m, GONCURSES_MENU_TERM);
+}
+
+int goncurses_set_menu_hook(MENU *m, int hook, int on) {
+	switch (hook) {
+	case GONCURSES_ITEM_INIT:
+		return set_item_init(m, on ? goncurses_item_init : NULL);
+	case GONCURSES_ITEM_TERM:
+		return set_item_term(m, on ? goncurses_item_term : NULL);
+	case GONCURSES_MENU_INIT:
+		return set_menu_init(m, on ? goncurses_menu_init : NULL);
+	case GONCURSES_MENU_TERM:
+		return set_menu_term(m, on ? goncurses_menu_term : NULL);
+	}
+	return E_BAD_ARGUMENT;
+}
@@ -0,0 +1,92 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+// #include <menu.h>
+// #include "menu_hooks.h"
+import "C"
+
+import "syscall"
+
+// Hook identifiers, as defined by menu_hooks.h
+const (
+	hookItemInit = C.GONCURSES_ITEM_INIT
+	hookItemTerm = C.GONCURSES_ITEM_TERM
+	hookMenuInit = C.GONCURSES_MENU_INIT
+	hookMenuTerm = C.GONCURSES_MENU_TERM
+)
+
+// menus maps each C menu to its Go counterpart so that hooks called from
+// libmenu can be dispatched. Menus are added by NewMenu and removed by Free
+var menus = map[*C.MENU]*Menu{}
+
+//export goncursesMenuHook
+func goncursesMenuHook(cm *C.MENU, hook C.int) {
+	m, ok := menus[cm]
+	if !ok {
+		return
+	}
+	switch hook {
+	case hookItemInit:
+		if m.itemEnter != nil {
+			m.itemEnter(m.Current(nil))
+		}
+	case hookItemTerm:
+		if m.itemLeave != nil {
+			m.itemLeave(m.Current(nil))
+		}
+	case hookMenuInit:
+		if m.post != nil {
+			m.post()
+		}
+	case hookMenuTerm:
+		if m.unpost != nil {
+			m.unpost()
+		}
+	}
+}
+
+func (m *Menu) setHook(hook int, on bool) error {
+	var con C.int
+	if on {
+		con = 1
+	}
+	err := C.goncurses_set_menu_hook(m.menu, C.int(hook), con)
+	return ncursesError(syscall.Errno(err))
+}
+
+// OnItemEnter sets a function to be called with the new current item when
+// the menu is posted and just after the current item changes. Pass nil to
+// remove the hook.
+func (m *Menu) OnItemEnter(fn func(*MenuItem)) error {
+	m.itemEnter = fn
+	return m.setHook(hookItemInit, fn != nil)
+}
+
+// OnItemLeave sets a function to be called with the current item just
+// before the current item changes and just before the menu is unposted.
+// Pass nil to remove the hook.
+func (m *Menu) OnItemLeave(fn func(*MenuItem)) error {
+	m.itemLeave = fn
+	return m.setHook(hookItemTerm, fn != nil)
+}
+
+// OnPost sets a function to be called when the menu is posted. Because
+// libmenu uses the same hook when a menu scrolls, it is also called just
+// after the top row of the menu changes. Pass nil to remove the hook.
+func (m *Menu) OnPost(fn func()) error {
+	m.post = fn
+	return m.setHook(hookMenuInit, fn != nil)
+}
+
+// OnUnpost sets a function to be called just before the menu is unposted
+// and, like OnPost, just before the top row of the menu changes. Pass nil
+// to remove the hook.
+func (m *Menu) OnUnpost(fn func()) error {
+	m.unpost = fn
+	return m.setHook(hookMenuTerm, fn != nil)
+}
@@ -0,0 +1,17 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+#ifndef _GONCURSES_MENU_HOOKS_
+#define _GONCURSES_MENU_HOOKS_ 1
+
+// the libmenu hooks which goncursesMenuHook is called for
+enum goncurses_menu_hook {
+	GONCURSES_ITEM_INIT,
+	GONCURSES_ITEM_TERM,
+	GONCURSES_MENU_INIT,
+	GONCURSES_MENU_TERM
+};
+
+int goncurses_set_menu_hook(MENU *m, int hook, int on);
+
+#endif
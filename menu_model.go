@@ -0,0 +1,201 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+// #include <menu.h>
+import "C"
+
+import (
+	"errors"
+	"sort"
+	"syscall"
+)
+
+// MenuModel manages the items of a menu so they can be changed while the
+// menu is in use. The model owns its items: items which are removed or
+// replaced are freed once they are no longer attached to the menu, and the
+// remaining items are freed along with the menu by Free.
+//
+// libmenu does not allow the items of a posted menu to be changed, so a
+// posted menu is briefly unposted and posted again by each change. Any
+// hooks set with OnPost and OnUnpost are called as a result. The window
+// containing the menu needs to be refreshed afterwards. A posted menu
+// which loses all of its items stays unposted until items are added again.
+type MenuModel struct {
+	menu  *Menu
+	items []*MenuItem
+}
+
+// NewMenuModel returns a model managing the items currently in m. The
+// model takes ownership of those items
+func NewMenuModel(m *Menu) *MenuModel {
+	return &MenuModel{menu: m, items: m.Items()}
+}
+
+// Menu returns the menu being managed
+func (mm *MenuModel) Menu() *Menu {
+	return mm.menu
+}
+
+// Items returns a copy of the items in the model, in menu order
+func (mm *MenuModel) Items() []*MenuItem {
+	return append([]*MenuItem(nil), mm.items...)
+}
+
+// Len returns the number of items in the model
+func (mm *MenuModel) Len() int {
+	return len(mm.items)
+}
+
+// Append adds items to the end of the menu
+func (mm *MenuModel) Append(items ...*MenuItem) error {
+	return mm.Insert(len(mm.items), items...)
+}
+
+// Insert adds items to the menu before the item at index. An index equal
+// to Len appends them. Items may not already belong to a menu
+func (mm *MenuModel) Insert(index int, items ...*MenuItem) error {
+	if index < 0 || index > len(mm.items) {
+		return errors.New("Menu item index out of range")
+	}
+	list := make([]*MenuItem, 0, len(mm.items)+len(items))
+	list = append(list, mm.items[:index]...)
+	list = append(list, items...)
+	list = append(list, mm.items[index:]...)
+	return mm.update(list, nil)
+}
+
+// Remove takes the item at index out of the menu and frees it. If it was
+// the current item, the item which takes its place becomes current
+func (mm *MenuModel) Remove(index int) error {
+	if index < 0 || index >= len(mm.items) {
+		return errors.New("Menu item index out of range")
+	}
+	list := make([]*MenuItem, 0, len(mm.items)-1)
+	list = append(list, mm.items[:index]...)
+	list = append(list, mm.items[index+1:]...)
+	return mm.update(list, []*MenuItem{mm.items[index]})
+}
+
+// Replace puts item in place of the item at index, which is freed. If it
+// was the current item, the new item becomes current
+func (mm *MenuModel) Replace(index int, item *MenuItem) error {
+	if index < 0 || index >= len(mm.items) {
+		return errors.New("Menu item index out of range")
+	}
+	old := mm.items[index]
+	if old == item {
+		return nil
+	}
+	list := mm.Items()
+	list[index] = item
+	return mm.update(list, []*MenuItem{old})
+}
+
+// Sort orders the items using less. The sort is stable
+func (mm *MenuModel) Sort(less func(a, b *MenuItem) bool) error {
+	list := mm.Items()
+	sort.SliceStable(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	return mm.update(list, nil)
+}
+
+// Free frees the menu followed by all of the items in the model. The menu
+// must not be posted
+func (mm *MenuModel) Free() error {
+	if err := mm.menu.Free(); err != nil {
+		return err
+	}
+	for _, item := range mm.items {
+		item.Free()
+	}
+	mm.items = nil
+	return nil
+}
+
+// update attaches list to the menu in place of the current items and then
+// frees the items in removed. The current item and top row are kept if
+// possible. On failure the menu is left as it was and nothing is freed
+func (mm *MenuModel) update(list, removed []*MenuItem) error {
+	seen := make(map[*MenuItem]bool, len(mm.items))
+	for _, item := range mm.items {
+		seen[item] = false
+	}
+	for _, item := range list {
+		if inModel, ok := seen[item]; inModel || (!ok &&
+			C.item_index(item.item) != C.ERR) {
+			return errors.New("Menu item is already connected to a menu")
+		}
+		seen[item] = true
+	}
+
+	return mm.menu.swapItems(list, func() {
+		mm.items = list
+		for _, item := range removed {
+			item.Free()
+		}
+	})
+}
+
+// swapItems replaces the items of the menu with list, unposting and
+// reposting the menu if necessary. The current item and top row are kept
+// where possible; if the current item is no longer in the menu the item
+// which takes its place becomes current. A posted menu which is left
+// without items is posted again once it has some. If not nil, attached is
+// called as soon as list has been attached; the items previously in the
+// menu are no longer used at that point and may be freed
+func (m *Menu) swapItems(list []*MenuItem, attached func()) error {
+	top := m.TopRow()
+	cur := m.Current(nil)
+	index := -1
+	if cur != nil {
+		index = cur.Index()
+	}
+
+	posted := C.unpost_menu(m.menu) == C.E_OK || m.repost
+	err := m.SetItems(list)
+	if err == nil && attached != nil {
+		attached()
+	}
+	items := m.Items()
+	if len(items) == 0 {
+		m.repost = posted
+		return err
+	}
+	m.repost = false
+
+	for ; top > 0; top-- {
+		if C.set_top_row(m.menu, C.int(top)) == C.E_OK {
+			break
+		}
+	}
+	if index >= 0 {
+		// cur may have been freed so it is only compared until it is
+		// known to still be in the menu
+		kept := false
+		for _, item := range items {
+			if item == cur {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			if index >= len(items) {
+				index = len(items) - 1
+			}
+			cur = items[index]
+		}
+		C.set_current_item(m.menu, cur.item)
+	}
+	if posted {
+		if perr := C.post_menu(m.menu); err == nil && perr != C.E_OK {
+			err = ncursesError(syscall.Errno(perr))
+		}
+	}
+	return err
+}
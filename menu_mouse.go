@@ -0,0 +1,79 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+/*
+#include <menu.h>
+
+// goncurses_menu_click has libmenu act on a click of the first button at
+// screen row y, column x, as though it had been read by getch
+static int goncurses_menu_click(MENU *m, int y, int x) {
+	MEVENT event = {0};
+	WINDOW *pad;
+	int err;
+
+	event.y = y;
+	event.x = x;
+	event.bstate = BUTTON1_CLICKED;
+	if (ungetmouse(&event) != OK)
+		return E_SYSTEM_ERROR;
+	err = menu_driver(m, KEY_MOUSE);
+
+	// ungetmouse also pushed KEY_MOUSE back on to the input queue. Read it
+	// from a pad since, unlike other windows, reading from a pad does not
+	// refresh it
+	pad = newpad(1, 1);
+	if (pad != NULL) {
+		wgetch(pad);
+		delwin(pad);
+	}
+	return err;
+}
+*/
+import "C"
+
+import "syscall"
+
+// HandleMouse acts on a mouse event returned by GetMouse. Clicking an item
+// makes it the current item and double-clicking an item also calls the
+// function set with OnActivate. Clicking the menu's window above or below
+// its items scrolls the menu, as does the scroll wheel. Other events are
+// ignored. The events are handled by libmenu, so the first button's click
+// events must be enabled with MouseMask.
+func (m *Menu) HandleMouse(ev *MouseEvent) error {
+	switch {
+	case ev.State&M_B4_PRESSED != 0:
+		return m.Driver(REQ_ULINE)
+	case M_B5_PRESSED != 0 && ev.State&M_B5_PRESSED != 0:
+		return m.Driver(REQ_DLINE)
+	}
+
+	dbl := ev.State&M_B1_DBL_CLICKED != 0
+	if ev.State&(M_B1_CLICKED|M_B1_PRESSED) == 0 && !dbl {
+		return nil
+	}
+	switch err := C.goncurses_menu_click(m.menu, C.int(ev.Y), C.int(ev.X)); err {
+	case C.E_OK:
+	case C.E_UNKNOWN_COMMAND, C.E_REQUEST_DENIED:
+		// the click was not on an item
+		return nil
+	default:
+		return ncursesError(syscall.Errno(err))
+	}
+
+	if dbl && m.activate != nil &&
+		bool(C.wenclose(C.menu_sub(m.menu), C.int(ev.Y), C.int(ev.X))) {
+		m.activate(m.Current(nil))
+	}
+	return nil
+}
+
+// OnActivate sets a function to be called when an item is double-clicked.
+// Pass nil to remove it
+func (m *Menu) OnActivate(fn func(*MenuItem)) {
+	m.activate = fn
+}
@@ -0,0 +1,98 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+// MenuState is a snapshot of a menu's settings and position as returned by
+// Menu.State. It may be handed back to Restore, for example after the
+// menu's items have been redrawn or the menu reposted.
+type MenuState struct {
+	Options    MenuOptions
+	Rows, Cols int // format, see Format
+	Mark       string
+	Fore       Char
+	Back       Char
+	Grey       Char
+	Pad        int
+	Spacing    [3]int // description, row and column spacing, see SetSpacing
+	TopRow     int
+	Current    int // index of the current item, -1 if there is none
+}
+
+// State returns the menu's current settings and scroll position
+func (m *Menu) State() MenuState {
+	s := MenuState{
+		Options: m.CurrentOptions(),
+		Mark:    m.MarkString(),
+		Fore:    m.Foreground(),
+		Back:    m.Background(),
+		Grey:    m.GreyAttr(),
+		Pad:     m.Pad(),
+		TopRow:  m.TopRow(),
+		Current: -1,
+	}
+	s.Rows, s.Cols = m.FormatSize()
+	s.Spacing[0], s.Spacing[1], s.Spacing[2] = m.Spacing()
+	if cur := m.Current(nil); cur != nil {
+		s.Current = cur.Index()
+	}
+	return s
+}
+
+// Restore applies a state previously returned by State. Options, format and
+// spacing can not be changed while a menu is posted so they are only set
+// when they differ from the menu's current values. If the menu now holds
+// fewer items the current item and top row are clamped to the last ones.
+func (m *Menu) Restore(s MenuState) error {
+	if m.CurrentOptions() != s.Options {
+		if err := m.SetOptions(s.Options); err != nil {
+			return err
+		}
+	}
+	if rows, cols := m.FormatSize(); rows != s.Rows || cols != s.Cols {
+		if err := m.Format(s.Rows, s.Cols); err != nil {
+			return err
+		}
+	}
+	if d, r, c := m.Spacing(); d != s.Spacing[0] || r != s.Spacing[1] ||
+		c != s.Spacing[2] {
+		if err := m.SetSpacing(s.Spacing[0], s.Spacing[1],
+			s.Spacing[2]); err != nil {
+			return err
+		}
+	}
+	if err := m.Mark(s.Mark); err != nil {
+		return err
+	}
+	if err := m.SetForeground(s.Fore); err != nil {
+		return err
+	}
+	if err := m.SetBackground(s.Back); err != nil {
+		return err
+	}
+	m.Grey(s.Grey)
+	if err := m.SetPad(Char(s.Pad)); err != nil {
+		return err
+	}
+
+	items := m.Items()
+	if len(items) == 0 || s.Current < 0 {
+		return nil
+	}
+	// Setting the top row moves the current item to it, so the top row
+	// must be restored first. Setting the current item afterwards leaves
+	// the top row alone as long as the item is visible
+	for row := s.TopRow; row >= 0; row-- {
+		if m.SetTopRow(row) == nil {
+			break
+		}
+	}
+	if s.Current >= len(items) {
+		s.Current = len(items) - 1
+	}
+	m.Current(items[s.Current])
+	return nil
+}
@@ -0,0 +1,667 @@
+// +build !windows,!purego
+
+package goncurses_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestMenuItemIdentity(t *testing.T) {
+	term := newTerminal(t, 24, 80)
+	defer term.Close()
+
+	type host struct{ name string }
+	var err error
+	items := make([]*goncurses.MenuItem, 3)
+	for i, name := range []string{"alpha", "beta", "gamma"} {
+		items[i], err = goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer items[i].Free()
+		items[i].SetUserData(&host{name})
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+
+	for i, item := range menu.Items() {
+		if item != items[i] {
+			t.Errorf("item %d: got a different *MenuItem", i)
+		}
+		if h, ok := item.UserData().(*host); !ok || h.name != item.Name() {
+			t.Errorf("item %d: unexpected user data %v", i, item.UserData())
+		}
+	}
+	if menu.Current(nil) != items[0] {
+		t.Error("Current returned a different *MenuItem")
+	}
+	items[1].SetUserData(nil)
+	if items[1].UserData() != nil {
+		t.Error("expected user data to be cleared")
+	}
+}
+
+func TestMenuStateRestore(t *testing.T) {
+	term := newTerminal(t, 24, 80)
+	defer term.Close()
+	stdscr := term.StdScr()
+
+	var err error
+	items := make([]*goncurses.MenuItem, 10)
+	for i := range items {
+		items[i], err = goncurses.NewItem(string(rune('a'+i)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer items[i].Free()
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+
+	menu.SetWindow(stdscr)
+	menu.Format(3, 1)
+	menu.Mark("> ")
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+
+	if err := menu.SetTopRow(4); err != nil {
+		t.Fatal(err)
+	}
+	menu.Current(items[5])
+	state := menu.State()
+
+	menu.Driver(goncurses.REQ_FIRST)
+	if menu.TopRow() != 0 {
+		t.Fatalf("expected top row 0, got %d", menu.TopRow())
+	}
+	if err := menu.Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	if menu.TopRow() != 4 {
+		t.Errorf("expected top row 4, got %d", menu.TopRow())
+	}
+	if menu.Current(nil) != items[5] {
+		t.Errorf("expected current item f, got %s", menu.Current(nil).Name())
+	}
+	if rows, cols := menu.FormatSize(); rows != 3 || cols != 1 {
+		t.Errorf("expected format 3x1, got %dx%d", rows, cols)
+	}
+	if menu.MarkString() != "> " {
+		t.Errorf("unexpected mark %q", menu.MarkString())
+	}
+}
+
+func TestMenuModel(t *testing.T) {
+	term := newTerminal(t, 24, 80)
+	defer term.Close()
+	stdscr := term.StdScr()
+
+	newItem := func(name string) *goncurses.MenuItem {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+	names := func(mm *goncurses.MenuModel) (s string) {
+		for _, item := range mm.Menu().Items() {
+			s += item.Name()
+		}
+		return
+	}
+
+	menu, err := goncurses.NewMenu([]*goncurses.MenuItem{newItem("d"),
+		newItem("b"), newItem("e"), newItem("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm := goncurses.NewMenuModel(menu)
+	defer mm.Free()
+	menu.SetWindow(stdscr)
+	menu.Format(2, 1)
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+
+	cur := mm.Items()[2]
+	menu.Current(cur)
+	if err := mm.Append(newItem("c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mm.Sort(func(a, b *goncurses.MenuItem) bool {
+		return a.Name() < b.Name()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if names(mm) != "abcde" {
+		t.Fatalf("expected abcde, got %s", names(mm))
+	}
+	if menu.Current(nil) != cur {
+		t.Errorf("expected current item e, got %s", menu.Current(nil).Name())
+	}
+	if menu.TopRow() != 3 {
+		t.Errorf("expected top row 3, got %d", menu.TopRow())
+	}
+
+	if err := mm.Remove(4); err != nil {
+		t.Fatal(err)
+	}
+	if menu.Current(nil).Name() != "d" {
+		t.Errorf("expected current item d, got %s", menu.Current(nil).Name())
+	}
+	if err := mm.Replace(0, newItem("z")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mm.Insert(1, mm.Items()[2]); err == nil {
+		t.Error("expected an error inserting an item twice")
+	}
+	if names(mm) != "zbcd" {
+		t.Fatalf("expected zbcd, got %s", names(mm))
+	}
+
+	for mm.Len() > 0 {
+		if err := mm.Remove(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mm.Append(newItem("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := menu.Post(); err == nil {
+		t.Error("expected menu to be posted again")
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		positions  []int
+		ok         bool
+	}{
+		{"", "anything", nil, true},
+		{"opf", "Open File", []int{0, 1, 5}, true},
+		{"of", "Open File", []int{0, 5}, true},
+		{"fo", "Open File", nil, false},
+		{"sf", "saveFile", []int{0, 4}, true},
+		{"abc", "xxaxbxabc", []int{6, 7, 8}, true},
+	}
+	for _, test := range tests {
+		_, pos, ok := goncurses.FuzzyMatch(test.pattern, test.s)
+		if ok != test.ok || fmt.Sprint(pos) != fmt.Sprint(test.positions) {
+			t.Errorf("%q in %q: expected %v %v, got %v %v", test.pattern,
+				test.s, test.positions, test.ok, pos, ok)
+		}
+	}
+
+	boundary, _, _ := goncurses.FuzzyMatch("of", "Open File")
+	inner, _, _ := goncurses.FuzzyMatch("of", "Profile")
+	if boundary <= inner {
+		t.Errorf("expected word starts to score higher (%d <= %d)",
+			boundary, inner)
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	term := newTerminal(t, 24, 80)
+	defer term.Close()
+	stdscr := term.StdScr()
+
+	var items []*goncurses.MenuItem
+	for _, name := range []string{"Profile", "Open File", "Quit"} {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer item.Free()
+		items = append(items, item)
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+	menu.SetWindow(stdscr)
+	menu.Mark("")
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+
+	filter := goncurses.NewFuzzyFilter(menu)
+	names := func() (s []string) {
+		for _, item := range menu.Items() {
+			s = append(s, item.Name())
+		}
+		return
+	}
+	filter.HandleKey('o')
+	filter.HandleKey('f')
+	if fmt.Sprint(names()) != "[Open File Profile]" {
+		t.Errorf("unexpected items %v", names())
+	}
+	if menu.Current(nil) != items[1] {
+		t.Error("expected best match to be current")
+	}
+	if stdscr.MoveInChar(0, 5)&goncurses.A_UNDERLINE == 0 {
+		t.Error("expected matched character to be highlighted")
+	}
+	if stdscr.MoveInChar(0, 1)&goncurses.A_UNDERLINE != 0 {
+		t.Error("expected unmatched character not to be highlighted")
+	}
+
+	filter.HandleKey('z')
+	if menu.Count() != 0 {
+		t.Errorf("expected no items, got %v", names())
+	}
+	filter.HandleKey(goncurses.KEY_BACKSPACE)
+	if menu.Count() != 2 {
+		t.Errorf("expected two items, got %v", names())
+	}
+	filter.Driver(goncurses.REQ_CLEAR_PATTERN)
+	if fmt.Sprint(names()) != "[Profile Open File Quit]" {
+		t.Errorf("unexpected items %v", names())
+	}
+}
+
+func TestMenuHandleMouse(t *testing.T) {
+	term := newTerminal(t, 10, 30)
+	defer term.Close()
+	goncurses.MouseMask(goncurses.M_ALL, nil)
+
+	var err error
+	items := make([]*goncurses.MenuItem, 6)
+	for i := range items {
+		items[i], err = goncurses.NewItem(string(rune('a'+i)), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer items[i].Free()
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+
+	win, err := goncurses.NewWindow(5, 10, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Delete()
+	menu.Option(goncurses.O_ONEVALUE, false)
+	menu.SetWindow(win)
+	menu.SubWindow(win.Derived(3, 8, 1, 1))
+	menu.Format(3, 1)
+	if err := menu.Post(); err != nil {
+		t.Fatal(err)
+	}
+	defer menu.UnPost()
+
+	var activated *goncurses.MenuItem
+	menu.OnActivate(func(item *goncurses.MenuItem) { activated = item })
+
+	menu.HandleMouse(&goncurses.MouseEvent{Y: 5, X: 6,
+		State: goncurses.M_B1_CLICKED})
+	if menu.Current(nil) != items[2] {
+		t.Errorf("expected item c, got %s", menu.Current(nil).Name())
+	}
+	if items[2].Value() {
+		t.Error("expected a single click not to toggle the item")
+	}
+	win.Timeout(0)
+	if k := win.GetChar(); k != 0 {
+		t.Errorf("expected no input to be left queued, got %d", k)
+	}
+	menu.HandleMouse(&goncurses.MouseEvent{Y: 0, X: 0,
+		State: goncurses.M_B1_CLICKED})
+	if menu.Current(nil) != items[2] {
+		t.Error("expected a click outside the menu to be ignored")
+	}
+	if goncurses.M_B5_PRESSED != 0 {
+		menu.HandleMouse(&goncurses.MouseEvent{Y: 4, X: 6,
+			State: goncurses.M_B5_PRESSED})
+		if menu.TopRow() != 1 {
+			t.Errorf("expected top row 1, got %d", menu.TopRow())
+		}
+	}
+	menu.HandleMouse(&goncurses.MouseEvent{Y: 4, X: 6,
+		State: goncurses.M_B1_DBL_CLICKED})
+	if activated == nil || activated != menu.Current(nil) {
+		t.Error("expected double click to activate the current item")
+	}
+}
+
+func TestMenuHandleExtendedKey(t *testing.T) {
+	term := newTerminal(t, 10, 30)
+	defer term.Close()
+	stdscr := term.StdScr()
+	stdscr.Keypad(true)
+	stdscr.Timeout(1000)
+
+	items := make([]*goncurses.MenuItem, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer item.Free()
+		items[i] = item
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+	menu.Post()
+	defer menu.UnPost()
+	menu.HandleKey(goncurses.KEY_DOWN)
+
+	// alt+delete and alt+down have codes beyond KEY_MAX, in the range of
+	// menu requests
+	for _, seq := range []string{"\x1b[3;3~", "\x1b[1;3B"} {
+		term.TypeString(seq)
+		k := stdscr.GetChar()
+		if k <= goncurses.KEY_MAX {
+			t.Fatalf("expected an extended key for %q, got %s", seq,
+				goncurses.KeyString(k))
+		}
+		if err := menu.HandleKey(k); err == nil {
+			t.Errorf("expected %q to be rejected", seq)
+		}
+		if cur := menu.Current(nil); cur != items[1] {
+			t.Errorf("expected %q to leave b current, got %s", seq,
+				cur.Name())
+		}
+	}
+}
+
+func TestMenuHooks(t *testing.T) {
+	term := newTerminal(t, 10, 30)
+	defer term.Close()
+
+	items := make([]*goncurses.MenuItem, 3)
+	for i, name := range []string{"alpha", "beta", "gamma"} {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer item.Free()
+		items[i] = item
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+
+	var events []string
+	menu.OnPost(func() { events = append(events, "post") })
+	menu.OnUnpost(func() { events = append(events, "unpost") })
+	menu.OnItemEnter(func(item *goncurses.MenuItem) {
+		events = append(events, "enter "+item.Name())
+	})
+	menu.OnItemLeave(func(item *goncurses.MenuItem) {
+		events = append(events, "leave "+item.Name())
+	})
+
+	menu.Post()
+	menu.Driver(goncurses.REQ_DOWN)
+	menu.UnPost()
+	want := "[post enter alpha leave alpha enter beta leave beta unpost]"
+	if got := fmt.Sprint(events); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	events = nil
+	menu.OnPost(nil)
+	menu.OnItemEnter(nil)
+	menu.Post()
+	menu.UnPost()
+	if got := fmt.Sprint(events); got != "[leave beta unpost]" {
+		t.Errorf("expected only the leave and unpost hooks, got %s", got)
+	}
+}
+
+func TestMenuItemFree(t *testing.T) {
+	term := newTerminal(t, 10, 30)
+	defer term.Close()
+
+	item, err := goncurses.NewItem("alpha", "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu, err := goncurses.NewMenu([]*goncurses.MenuItem{item})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := item.Free(); err == nil {
+		t.Error("expected an error freeing an item connected to a menu")
+	}
+	if item.Name() != "alpha" || item.Description() != "first" {
+		t.Errorf("item changed by failed Free: %q, %q", item.Name(),
+			item.Description())
+	}
+	menu.Free()
+	if err := item.Free(); err != nil {
+		t.Errorf("expected item to be freed once its menu was, got %v", err)
+	}
+}
+
+func TestMenuMultiSelect(t *testing.T) {
+	term := newTerminal(t, 10, 30)
+	defer term.Close()
+
+	items := make([]*goncurses.MenuItem, 4)
+	for i, name := range []string{"alpha", "beta", "gamma", "delta"} {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer item.Free()
+		items[i] = item
+	}
+	items[2].Selectable(false)
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+
+	names := func(items []*goncurses.MenuItem) string {
+		s := make([]string, len(items))
+		for i, item := range items {
+			s[i] = item.Name()
+		}
+		return fmt.Sprint(s)
+	}
+
+	// with O_ONEVALUE on the current item is the selection and the space
+	// bar is passed to the driver as part of a pattern
+	menu.Post()
+	if got := names(menu.Selected()); got != "[alpha]" {
+		t.Errorf("expected [alpha] selected, got %s", got)
+	}
+	menu.HandleKey(' ')
+	if items[0].Value() {
+		t.Error("expected space not to toggle an item with O_ONEVALUE on")
+	}
+	if err := menu.SelectAll(); err == nil {
+		t.Error("expected SelectAll to fail with O_ONEVALUE on")
+	}
+	menu.UnPost()
+
+	if err := menu.Option(goncurses.O_ONEVALUE, false); err != nil {
+		t.Fatal(err)
+	}
+	if menu.CurrentOptions()&goncurses.O_ONEVALUE != 0 {
+		t.Error("expected O_ONEVALUE to be off")
+	}
+	menu.Post()
+	defer menu.UnPost()
+	if got := names(menu.Selected()); got != "[]" {
+		t.Errorf("expected nothing selected, got %s", got)
+	}
+	menu.HandleKey(' ')
+	menu.HandleKey(goncurses.KEY_DOWN)
+	menu.HandleKey(' ')
+	if got := names(menu.Selected()); got != "[alpha beta]" {
+		t.Errorf("expected [alpha beta] selected, got %s", got)
+	}
+	menu.HandleKey(' ')
+	if got := names(menu.Selected()); got != "[alpha]" {
+		t.Errorf("expected space to toggle beta off, got %s", got)
+	}
+
+	if err := menu.SelectAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(menu.Selected()); got != "[alpha beta delta]" {
+		t.Errorf("expected all selectable items selected, got %s", got)
+	}
+	if err := menu.ClearSelection(); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(menu.Selected()); got != "[]" {
+		t.Errorf("expected nothing selected, got %s", got)
+	}
+}
+
+func TestFuzzyFilterLayout(t *testing.T) {
+	term := newTerminal(t, 10, 60)
+	defer term.Close()
+	stdscr := term.StdScr()
+
+	names := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot"}
+	tests := []struct {
+		rowMajor       bool
+		rows, cols     int
+		desc, row, col int
+		mark, query    string
+		scroll         bool
+	}{
+		{true, 3, 2, 1, 1, 1, "-", "o", false},
+		{true, 1, 3, 3, 1, 4, "> ", "a", true},
+		{false, 2, 2, 2, 2, 3, "", "a", false},
+		{false, 1, 2, 1, 1, 1, "* ", "a", true},
+	}
+	for i, test := range tests {
+		var items []*goncurses.MenuItem
+		for j, name := range names {
+			item, err := goncurses.NewItem(name, fmt.Sprint(j))
+			if err != nil {
+				t.Fatal(err)
+			}
+			items = append(items, item)
+		}
+		menu, err := goncurses.NewMenu(items)
+		if err != nil {
+			t.Fatal(err)
+		}
+		menu.Option(goncurses.O_ROWMAJOR, test.rowMajor)
+		menu.SetSpacing(test.desc, test.row, test.col)
+		menu.Mark(test.mark)
+		menu.Format(test.rows, test.cols)
+		menu.SetWindow(stdscr)
+		stdscr.Erase()
+		if err := menu.Post(); err != nil {
+			t.Fatal(err)
+		}
+
+		filter := goncurses.NewFuzzyFilter(menu)
+		filter.SetQuery(test.query)
+		if test.scroll {
+			filter.Driver(goncurses.REQ_DLINE)
+			if menu.TopRow() != 1 {
+				t.Errorf("%d: expected menu to scroll, top row %d", i,
+					menu.TopRow())
+			}
+		}
+
+		// every visible name should have the first occurrence of the
+		// query underlined, and nothing else should be
+		want := map[[2]int]bool{}
+		for y := 0; y < 10; y++ {
+			line := ""
+			for x := 0; x < 60; x++ {
+				line += string(rune(stdscr.MoveInChar(y, x) & 0xff))
+			}
+			for _, item := range menu.Items() {
+				x := strings.Index(line, item.Name()+" ")
+				if x >= 0 {
+					x += strings.Index(item.Name(), test.query)
+					want[[2]int{y, x}] = true
+				}
+			}
+		}
+		if len(want) == 0 {
+			t.Errorf("%d: no items visible", i)
+		}
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 60; x++ {
+				got := stdscr.MoveInChar(y, x)&goncurses.A_UNDERLINE != 0
+				if got != want[[2]int{y, x}] {
+					t.Errorf("%d: expected underline at %d,%d to be %v", i,
+						y, x, !got)
+				}
+			}
+		}
+
+		filter.SetQuery("")
+		menu.UnPost()
+		menu.Free()
+		for _, item := range items {
+			item.Free()
+		}
+	}
+}
+
+func TestFuzzyFilterInput(t *testing.T) {
+	term := newTerminal(t, 10, 30)
+	defer term.Close()
+
+	var items []*goncurses.MenuItem
+	for _, name := range []string{"cafe", "cake"} {
+		item, err := goncurses.NewItem(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer item.Free()
+		items = append(items, item)
+	}
+	menu, err := goncurses.NewMenu(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer menu.Free()
+	menu.Post()
+	defer menu.UnPost()
+
+	filter := goncurses.NewFuzzyFilter(menu)
+	for _, b := range []byte("caé") {
+		filter.HandleKey(goncurses.Key(b))
+	}
+	if filter.Query() != "caé" {
+		t.Errorf("expected query %q, got %q", "caé", filter.Query())
+	}
+	if menu.Count() != 0 {
+		t.Errorf("expected no items to match, got %d", menu.Count())
+	}
+	filter.HandleKey(goncurses.KEY_BACKSPACE)
+	if filter.Query() != "ca" || menu.Count() != 2 {
+		t.Errorf("expected query %q with two items, got %q with %d", "ca",
+			filter.Query(), menu.Count())
+	}
+}
@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #cgo !darwin,!openbsd,!windows pkg-config: ncurses
@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #cgo !darwin,!openbsd,!windows pkg-config: ncurses
@@ -89,13 +91,25 @@ func Cursor(vis byte) error {
 	return nil
 }
 
+// noEcho holds the standard screen window of each screen on which echo
+// has been turned off. ncurses has no way to ask, so it is recorded here
+// for those which turn echo off for a while and then restore it
+var noEcho = make(map[*C.WINDOW]bool)
+
 // Echo turns on/off the printing of typed characters
 func Echo(on bool) {
 	if on {
 		C.echo()
+		delete(noEcho, C.stdscr)
 		return
 	}
 	C.noecho()
+	noEcho[C.stdscr] = true
+}
+
+// echoing returns true if echo is on for the current screen
+func echoing() bool {
+	return !noEcho[C.stdscr]
 }
 
 // Must be called prior to exiting the program in order to make sure the
@@ -182,10 +196,13 @@ func InitPair(pair, fg, bg int16) error {
 // Initialize the ncurses library. You must run this function prior to any
 // other goncurses function in order for the library to work
 func Init() (stdscr *Window, err error) {
-	stdscr = &Window{C.initscr()}
+	lines := ripOffs
+	stdscr = &Window{win: C.initscr()}
 	if unsafe.Pointer(stdscr.win) == nil {
 		err = errors.New("An error occurred initializing ncurses")
 	}
+	claimRipOffs(nil, lines)
+	delete(noEcho, stdscr.win)
 	return
 }
 
@@ -200,6 +217,16 @@ func IsTermResized(nlines, ncols int) bool {
 	return bool(C.is_term_resized(C.int(nlines), C.int(ncols)))
 }
 
+// KeyOk turns the recognition of function key k on or off. Once off, the
+// characters the terminal sends for the key are returned one at a time and
+// HasKey reports that the terminal does not have it
+func KeyOk(k Key, on bool) error {
+	if C.ncurses_keyok(C.int(k), C.bool(on)) == C.ERR {
+		return errors.New("Failed to change key recognition")
+	}
+	return nil
+}
+
 // Returns a string representing the value of input returned by GetChar
 func KeyString(k Key) string {
 	key, ok := keyList[k]
@@ -274,7 +301,7 @@ func StartColor() error {
 // the physical screen. This is the same Window returned by Init and therefore
 // not useful unless using NewTerm and other multi-screen related functions.
 func StdScr() *Window {
-	return &Window{C.stdscr}
+	return &Window{win: C.stdscr}
 }
 
 // UnGetChar places the character back into the input queue
@@ -0,0 +1,573 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// colorMask selects the color pair of a Char
+const colorMask Char = 0xff00
+
+// cell is a character cell of a window or of the virtual screen. attr
+// holds the attributes and color pair of the character and comb any
+// combining characters drawn over it. A wide character takes two cells,
+// the second of which has a ch of 0
+type cell struct {
+	ch   rune
+	attr Char
+	comb string
+}
+
+// colorPair holds the colors of a color pair. Pair 0, and any pair which
+// has not been initialised, is drawn in the terminal's default colors
+type colorPair struct {
+	fg, bg int16
+	set    bool
+}
+
+// virtualScreen is what ncurses calls newscr: the screen as the windows
+// refreshed so far would have it look. Update passes the cells which differ
+// from those last passed to the backend on to it
+type virtualScreen struct {
+	backend    Backend
+	rows, cols int
+	cells      []cell
+	// shown holds the cells last passed to the backend; a cell with a
+	// negative character is unknown
+	shown      []cell
+	cursorY    int
+	cursorX    int
+	visibility int
+	clear      bool
+	ended      bool
+
+	echo      bool
+	newlines  bool
+	halfDelay int
+	pushed    []Key
+	tabSize   int
+
+	colors        int
+	defaultColors bool
+	pairs         [256]colorPair
+}
+
+var (
+	screen *virtualScreen
+	stdscr *Window
+)
+
+// useEnv is set by UseEnvironment
+var useEnv = true
+
+// BaudRate returns the speed of the terminal in bits per second. A pure Go
+// build does not know the speed of the terminal and always returns 38400
+func BaudRate() int {
+	return 38400
+}
+
+// Beep requests the terminal make an audible bell or, if not available,
+// flashes the screen. Note that screen flashing doesn't work on all
+// terminals
+func Beep() {
+	if screen != nil {
+		screen.backend.Beep()
+	}
+}
+
+// Turn on/off buffering; raw user signals are passed to the program for
+// handling. Overrides raw mode. Input is never line buffered in a pure Go
+// build so turning it on only turns raw mode off
+func CBreak(on bool) {
+	if on && screen != nil {
+		screen.backend.SetRaw(false)
+	}
+}
+
+func TypeAhead(fd int) int {
+	return 0
+}
+
+// Test whether colour values can be changed
+func CanChangeColor() bool {
+	return false
+}
+
+// Colors returns the number of colors that the terminal supports
+func Colors() int {
+	if screen == nil {
+		return 0
+	}
+	return screen.colors
+}
+
+// Get RGB values for specified colour. These are the values ncurses gives
+// the colors of a terminal which cannot change them
+func ColorContent(col int16) (int16, int16, int16) {
+	if col < 0 || col >= 16 {
+		return 0, 0, 0
+	}
+	level := int16(680)
+	if col >= 8 {
+		level = 1000
+	}
+	var r, g, b int16
+	if col&1 != 0 {
+		r = level
+	}
+	if col&2 != 0 {
+		g = level
+	}
+	if col&4 != 0 {
+		b = level
+	}
+	return r, g, b
+}
+
+// Return the value of a color pair which can be passed to functions which
+// accept attributes like AddChar, AttrOn/Off and Background.
+func ColorPair(pair int16) Char {
+	return Char(pair) << 8 & colorMask
+}
+
+// ColorPairs returns the maximum number of color pairs that the terminal
+// supports. It is at most 256 in a pure Go build
+func ColorPairs() int {
+	if screen == nil {
+		return 0
+	}
+	if pairs := screen.colors * screen.colors; pairs < len(screen.pairs) {
+		return pairs
+	}
+	return len(screen.pairs)
+}
+
+// CursesVersion returns the version of the ncurses library currently linked
+// to, which for a pure Go build is none
+func CursesVersion() string {
+	return "goncurses (pure Go)"
+}
+
+// Set the cursor visibility. Options are: 0 (invisible/hidden), 1 (normal)
+// and 2 (extra-visible)
+func Cursor(vis byte) error {
+	if screen == nil || vis > 2 {
+		return errors.New("Failed to set cursor visibility")
+	}
+	screen.visibility = int(vis)
+	return nil
+}
+
+// Echo turns on/off the printing of typed characters
+func Echo(on bool) {
+	if screen != nil {
+		screen.echo = on
+	}
+}
+
+// Must be called prior to exiting the program in order to make sure the
+// terminal returns to normal operation
+func End() {
+	if screen == nil || screen.ended {
+		return
+	}
+	screen.backend.End()
+	screen.ended = true
+}
+
+// Flash requests the terminal flashes the screen or, if not available,
+// make an audible bell. Note that screen flashing doesn't work on all
+// terminals
+func Flash() {
+	if screen != nil {
+		screen.backend.Flash()
+	}
+}
+
+// FlushInput flushes all input
+func FlushInput() error {
+	if screen == nil {
+		return errors.New("Flush input failed")
+	}
+	screen.pushed = nil
+	for {
+		k, ok := screen.backend.ReadKey(0)
+		if !ok {
+			return nil
+		}
+		if k == KEY_RESIZE {
+			screen.resize()
+		}
+	}
+}
+
+// Behaves like cbreak() but also adds a timeout for input. If timeout is
+// exceeded after a call to Getch() has been made then GetChar will return
+// with an error.
+func HalfDelay(delay int) error {
+	if screen == nil || delay > 255 {
+		return errors.New("Unable to set delay mode")
+	}
+	if delay > 0 {
+		screen.halfDelay = delay
+	}
+	return nil
+}
+
+// HasColors returns true if terminal can display colors
+func HasColors() bool {
+	return screen != nil && screen.backend.Colors() > 0
+}
+
+// HasInsertChar return true if the terminal has insert and delete
+// character capabilities. A pure Go build does not use them and always
+// returns false
+func HasInsertChar() bool {
+	return false
+}
+
+// HasInsertLine returns true if the terminal has insert and delete line
+// capabilities. A pure Go build does not use them and always returns false
+func HasInsertLine() bool {
+	return false
+}
+
+// HasKey returns true if ch is one of the function keys, such as KEY_UP,
+// which the package recognizes. A backend with a HasKey method of its own
+// decides which of them the terminal has
+func HasKey(ch Key) bool {
+	if screen != nil {
+		if b, ok := screen.backend.(interface{ HasKey(Key) bool }); ok {
+			return b.HasKey(ch)
+		}
+	}
+	return ch >= KEY_DOWN && ch < KEY_MAX
+}
+
+// InitColor is used to set 'color' to the specified RGB values. Colors
+// cannot be changed by a pure Go build, so it always returns an error
+func InitColor(col, r, g, b int16) error {
+	return errors.New("Failed to set new color definition")
+}
+
+// InitPair sets a colour pair designated by 'pair' to fg and bg colors
+func InitPair(pair, fg, bg int16) error {
+	if pair <= 0 || int(pair) >= ColorPairs() {
+		return errors.New("Color pair out of range")
+	}
+	if !screen.validColor(fg) || !screen.validColor(bg) {
+		return errors.New("Failed to init color pair")
+	}
+	screen.pairs[pair] = colorPair{fg, bg, true}
+	screen.invalidate(func(c cell) bool {
+		return (c.attr&colorMask)>>8 == Char(pair)
+	})
+	return nil
+}
+
+// Initialize the ncurses library. You must run this function prior to any
+// other goncurses function in order for the library to work. A pure Go
+// build runs on the terminal named by $TERM using a TerminfoBackend
+func Init() (*Window, error) {
+	b, err := NewTerminfoBackend(os.Getenv("TERM"), os.Stdout, os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return InitBackend(b)
+}
+
+// InitBackend is like Init but runs the package on the terminal provided by
+// b. It is only available in a pure Go build
+func InitBackend(b Backend) (*Window, error) {
+	if err := b.Init(); err != nil {
+		return nil, err
+	}
+	rows, cols := b.Size()
+	if rows <= 0 || cols <= 0 {
+		b.End()
+		return nil, errors.New("An error occurred initializing ncurses")
+	}
+	screen = &virtualScreen{backend: b, visibility: 1, clear: true,
+		echo: true, newlines: true, tabSize: 8}
+	screen.setSize(rows, cols)
+	stdscr = newWindow(rows, cols, 0, 0)
+	panelStack = nil
+	return stdscr, nil
+}
+
+// IsEnd returns true if End() has been called, otherwise false
+func IsEnd() bool {
+	return screen == nil || screen.ended
+}
+
+// IsTermResized returns true if ResizeTerm would modify any current Windows
+// if called with the given parameters
+func IsTermResized(nlines, ncols int) bool {
+	return screen != nil && (nlines != screen.rows || ncols != screen.cols)
+}
+
+// Returns a string representing the value of input returned by GetChar
+func KeyString(k Key) string {
+	key, ok := keyList[k]
+	if !ok {
+		key = fmt.Sprintf("%c", int(k))
+	}
+	return key
+}
+
+// PairContent returns the current foreground and background colours
+// associated with the given pair
+func PairContent(pair int16) (fg int16, bg int16, err error) {
+	if screen == nil || pair < 0 || int(pair) >= ColorPairs() {
+		return -1, -1, errors.New("Invalid color pair")
+	}
+	p := screen.pairs[pair]
+	return p.fg, p.bg, nil
+}
+
+// Nap (sleep; halt execution) for 'ms' milliseconds
+func Nap(ms int) {
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+}
+
+// NewLines turns newline translation on/off.
+func NewLines(on bool) {
+	if screen != nil {
+		screen.newlines = on
+	}
+}
+
+// Raw turns on input buffering; user signals are disabled and the key strokes
+// are passed directly to input. Set to false if you wish to turn this mode
+// off
+func Raw(on bool) {
+	if screen != nil {
+		screen.backend.SetRaw(on)
+	}
+}
+
+// ResizeTerm will attempt to resize the terminal. This only has an effect if
+// the terminal is in an XWindows (GUI) environment.
+func ResizeTerm(nlines, ncols int) error {
+	if screen == nil || nlines <= 0 || ncols <= 0 {
+		return errors.New("Failed to resize terminal")
+	}
+	screen.resizeTo(nlines, ncols)
+	return nil
+}
+
+// Sets the delay from when the escape key is pressed until recognition.
+func SetEscDelay(size int) {
+	if screen == nil {
+		return
+	}
+	if b, ok := screen.backend.(*TerminfoBackend); ok {
+		b.EscDelay = time.Duration(size) * time.Millisecond
+	}
+}
+
+// Enables colors to be displayed. Will return an error if terminal is not
+// capable of displaying colors
+func StartColor() error {
+	if !HasColors() {
+		return errors.New("Terminal does not support colors")
+	}
+	screen.colors = screen.backend.Colors()
+	screen.pairs[0] = colorPair{fg: C_WHITE, bg: C_BLACK}
+	screen.invalidate(func(cell) bool { return true })
+	return nil
+}
+
+// StdScr returns a Window for the underlying stdscr object which represents
+// the physical screen. This is the same Window returned by Init and therefore
+// not useful unless using NewTerm and other multi-screen related functions.
+func StdScr() *Window {
+	return stdscr
+}
+
+// UnGetChar places the character back into the input queue
+func UnGetChar(ch Char) {
+	if screen != nil {
+		screen.pushed = append(screen.pushed, Key(ch))
+	}
+}
+
+// Update the screen, refreshing all windows
+func Update() error {
+	if screen == nil {
+		return errors.New("Failed to update")
+	}
+	return screen.update()
+}
+
+// UseDefaultColors tells the curses library to assign the terminal's default
+// foreground and background colors to color number -1. This will allow you to
+// call InitPair(x, -1, -1) to set both the foreground and background colours
+// of pair x to the terminal's default.
+func UseDefaultColors() error {
+	if screen == nil || screen.colors == 0 {
+		return errors.New("Failed to assume default colours.")
+	}
+	screen.defaultColors = true
+	screen.pairs[0] = colorPair{fg: -1, bg: -1}
+	screen.invalidate(func(cell) bool { return true })
+	return nil
+}
+
+// UseEnvironment specifies whether the LINES and COLUMNS environmental
+// variables should be used or not
+func UseEnvironment(use bool) {
+	useEnv = use
+}
+
+// SetTabSize allows for modification of the tab width. This setting is global
+// and affects all windows.
+func SetTabSize(tabSize int) {
+	if screen != nil && tabSize > 0 {
+		screen.tabSize = tabSize
+	}
+}
+
+// TabSize returns the configured tab width.
+func TabSize() int {
+	if screen == nil {
+		return 8
+	}
+	return screen.tabSize
+}
+
+// setSize sizes the screen to rows by cols, leaving every cell blank and
+// unknown
+func (s *virtualScreen) setSize(rows, cols int) {
+	s.rows, s.cols = rows, cols
+	s.cells = make([]cell, rows*cols)
+	s.shown = make([]cell, rows*cols)
+	for i := range s.cells {
+		s.cells[i] = cell{ch: ' '}
+		s.shown[i] = cell{ch: -1}
+	}
+	s.clear = true
+}
+
+// resize resizes the screen to the size of the terminal
+func (s *virtualScreen) resize() {
+	rows, cols := s.backend.Size()
+	if rows > 0 && cols > 0 {
+		s.resizeTo(rows, cols)
+	}
+}
+
+// resizeTo resizes the screen and stdscr to rows by cols. Like ncurses it
+// does not move or resize other windows
+func (s *virtualScreen) resizeTo(rows, cols int) {
+	if rows == s.rows && cols == s.cols {
+		return
+	}
+	s.setSize(rows, cols)
+	stdscr.Resize(rows, cols)
+	stdscr.Touch()
+}
+
+// invalidate marks the cells passed to the backend for which f returns
+// true as unknown so they are passed again by the next update
+func (s *virtualScreen) invalidate(f func(cell) bool) {
+	for i, c := range s.shown {
+		if c.ch >= 0 && f(c) {
+			s.shown[i].ch = -1
+		}
+	}
+}
+
+// validColor returns true if color may be used in a color pair
+func (s *virtualScreen) validColor(color int16) bool {
+	if color == -1 {
+		return s.defaultColors
+	}
+	return color >= 0 && int(color) < s.colors
+}
+
+// resolve converts c to the Cell passed to the backend
+func (s *virtualScreen) resolve(c cell) Cell {
+	r, attr := c.ch, c.attr
+	if attr&A_ALTCHARSET != 0 {
+		if u, ok := acsRunes[r]; ok {
+			r = u
+		}
+		attr &^= A_ALTCHARSET
+	}
+	out := Cell{Ch: r, Comb: c.comb, Attr: attr &^ colorMask, Fg: -1, Bg: -1}
+	if p := s.pairs[(attr&colorMask)>>8]; s.colors > 0 && p.set {
+		out.Fg, out.Bg = p.fg, p.bg
+	}
+	return out
+}
+
+// update passes the changed cells and the cursor to the backend
+func (s *virtualScreen) update() error {
+	if s.ended {
+		if err := s.backend.Init(); err != nil {
+			return err
+		}
+		s.ended = false
+		s.clear = true
+	}
+	if s.clear {
+		s.backend.Clear()
+		s.invalidate(func(cell) bool { return true })
+		s.clear = false
+	}
+	for i, c := range s.cells {
+		if c != s.shown[i] {
+			s.backend.SetCell(i/s.cols, i%s.cols, s.resolve(c))
+			s.shown[i] = c
+		}
+	}
+	s.backend.SetCursor(s.cursorY, s.cursorX, s.visibility)
+	return s.backend.Flush()
+}
+
+// readKey waits for a key as a window with the given delay does. See
+// Window.Timeout
+func (s *virtualScreen) readKey(delay int) (Key, bool) {
+	if n := len(s.pushed); n > 0 {
+		k := s.pushed[n-1]
+		s.pushed = s.pushed[:n-1]
+		return k, true
+	}
+	timeout := time.Duration(-1)
+	switch {
+	case delay >= 0:
+		timeout = time.Duration(delay) * time.Millisecond
+	case s.halfDelay > 0:
+		timeout = time.Duration(s.halfDelay) * 100 * time.Millisecond
+	}
+	k, ok := s.backend.ReadKey(timeout)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case k == KEY_RESIZE:
+		s.resize()
+	case k == '\r' && s.newlines:
+		k = '\n'
+	}
+	return k, true
+}
+
+// acsRunes maps the characters of the alternate character set to the
+// Unicode characters ncurses uses for them
+var acsRunes = map[rune]rune{
+	'l': '┌', 'm': '└', 'k': '┐', 'j': '┘', 't': '├', 'u': '┤', 'v': '┴',
+	'w': '┬', 'q': '─', 'x': '│', 'n': '┼', 'o': '⎺', 's': '⎽', '`': '◆',
+	'a': '▒', 'f': '°', 'g': '±', '~': '·', ',': '←', '+': '→', '.': '↓',
+	'-': '↑', 'h': '░', 'i': '␋', '0': '█', 'p': '⎻', 'r': '⎼', 'y': '≤',
+	'z': '≥', '{': 'π', '|': '≠', '}': '£',
+}
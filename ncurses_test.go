@@ -1,3 +1,5 @@
+// +build !purego
+
 // ncurses_example.go
 package goncurses_test
 
@@ -3,6 +3,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #include <curses.h>
@@ -24,7 +26,7 @@ func NewPad(h, w int) (*Pad, error) {
 	if p == nil {
 		return nil, errors.New("Failed to create pad")
 	}
-	return &Pad{&Window{p}}, nil
+	return &Pad{&Window{win: p}}, nil
 }
 
 // NoutRefresh indicates that a section of the screen should be redrawn but
@@ -70,6 +72,6 @@ func (p *Pad) Refresh(py, px, sy1, sx1, sy2, sx2 int) error {
 // Sub creates a sub-pad h(eight) by w(idth) in size starting at the location
 // y, x in the parent pad. Changes to a sub-pad will also change it's parent
 func (p *Pad) Sub(y, x, h, w int) *Pad {
-	return &Pad{&Window{C.subpad(p.win, C.int(h), C.int(w), C.int(y),
-		C.int(x))}}
+	return &Pad{&Window{win: C.subpad(p.win, C.int(h), C.int(w), C.int(y),
+		C.int(x)), screen: p.screen}}
 }
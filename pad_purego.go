@@ -0,0 +1,95 @@
+// goncurses - ncurses library for Go.
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+import "errors"
+
+type Pad struct {
+	*Window
+}
+
+// NewPad creates a window which is not restricted by the terminal's
+// dimensions (unlike a Window). Pads accept all functions which can be
+// called on a window. It returns a pointer to a new Pad of h(eight) by
+// w(idth).
+func NewPad(h, w int) (*Pad, error) {
+	if screen == nil || h <= 0 || w <= 0 {
+		return nil, errors.New("Failed to create pad")
+	}
+	p := newWindow(h, w, 0, 0)
+	p.isPad = true
+	return &Pad{p}, nil
+}
+
+// NoutRefresh indicates that a section of the screen should be redrawn but
+// does not update the physical screen until Update() is called. See
+// Pad.Refresh() for details on the arguments and Window.NoutRefresh for
+// more details on the workings of this function
+func (p *Pad) NoutRefresh(py, px, sy, sx, h, w int) error {
+	if screen == nil {
+		return errors.New("Failed to refresh pad")
+	}
+	py, px, sy, sx = max(py, 0), max(px, 0), max(sy, 0), max(sx, 0)
+	// as ncurses does, the area is trimmed to the pad but must fit on the
+	// screen
+	if end := py + h - sy; end >= p.rows() {
+		h -= end - p.rows() + 1
+	}
+	if end := px + w - sx; end >= p.cols() {
+		w -= end - p.cols() + 1
+	}
+	if h >= screen.rows || w >= screen.cols || sy > h || sx > w {
+		return errors.New("Failed to refresh pad")
+	}
+	rows, cols := h-sy+1, w-sx+1
+	p.copyTo(py, px, sy, sx, rows, cols, false)
+	p.padArgs = []int{py, px, sy, sx, h, w}
+	return nil
+}
+
+// Refresh will calculate how to update the physical screen in the most
+// efficient manor and update it. See Window.Refresh for more details.
+// The coordinates py, px specify the location on the pad from which the
+// characters we want to display are located. sy1 and sx1 specify the location
+// on the screen where this data should be displayed, hence the upper left
+// corner of the display area on the screen. sy2 and sx2 specify the location
+// of the lower right corner of the display area on the screen:
+//
+//   (y1,x1) +-------------+
+//           |             |
+//           |             |
+//           |             |
+//           |             |
+//           |             |
+//           |             |
+//           +-------------+ (y2, x2)
+//
+// The coordinates of the rectangle must be contained within both the Pad's
+// and Window's respective areas.
+func (p *Pad) Refresh(py, px, sy1, sx1, sy2, sx2 int) error {
+	if err := p.NoutRefresh(py, px, sy1, sx1, sy2, sx2); err != nil {
+		return err
+	}
+	return Update()
+}
+
+// Sub creates a sub-pad h(eight) by w(idth) in size starting at the location
+// y, x in the parent pad. Changes to a sub-pad will also change it's parent
+func (p *Pad) Sub(y, x, h, w int) *Pad {
+	return &Pad{p.derive(h, w, y, x)}
+}
+
+// Echo prints a single character to the pad immediately. This has the
+// same effect of calling AddChar() + Refresh() but has a significant
+// speed advantage
+func (p *Pad) Echo(ch int) {
+	p.AddChar(Char(ch))
+	if a := p.padArgs; a != nil {
+		p.Refresh(a[0], a[1], a[2], a[3], a[4], a[5])
+	}
+}
@@ -2,12 +2,25 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #cgo !darwin,!openbsd,!windows pkg-config: panel
 // #cgo darwin openbsd LDFLAGS: -lpanel
-// #include <panel.h>
-// #include <curses.h>
+/*
+#include <panel.h>
+#include <curses.h>
+#include <stdint.h>
+
+static void goncurses_set_panel_handle(PANEL *p, uintptr_t h) {
+	set_panel_userptr(p, (void *)h);
+}
+
+static uintptr_t goncurses_panel_handle(PANEL *p) {
+	return (uintptr_t)panel_userptr(p);
+}
+*/
 import "C"
 
 import "errors"
@@ -16,12 +29,65 @@ type Panel struct {
 	pan *C.PANEL
 }
 
+// panels maps each C panel to the Panel created for it so that the same
+// *Panel is always returned for a given panel. Panels are added by NewPanel
+// and removed by Delete
+var panels = map[*C.PANEL]*Panel{}
+
+// panelFor returns the Panel wrapping cp, or nil if cp is nil
+func panelFor(cp *C.PANEL) *Panel {
+	if cp == nil {
+		return nil
+	}
+	p, ok := panels[cp]
+	if !ok {
+		p = &Panel{cp}
+		panels[cp] = p
+	}
+	return p
+}
+
 // Panel creates a new panel derived from the window, adding it to the
 // panel stack. The pointer to the original window can still be used to
 // execute most window functions with the exception of Refresh(). Always
-// use panel's Refresh() function.
+// use panel's Refresh() function. Returns nil if the panel could not be
+// created.
 func NewPanel(w *Window) *Panel {
-	return &Panel{C.new_panel(w.win)}
+	return panelFor(C.new_panel(w.win))
+}
+
+// BottomPanel returns the panel at the bottom of the stack or nil if there
+// are no visible panels
+func BottomPanel() *Panel {
+	return panelFor(C.panel_above(nil))
+}
+
+// PanelAt returns the topmost visible panel whose window contains the
+// screen coordinates y, x, or nil if there is none. It is useful for
+// routing mouse events to the panel which was clicked
+func PanelAt(y, x int) *Panel {
+	for p := TopPanel(); p != nil; p = p.Below() {
+		if !p.Hidden() && p.Window().Enclose(y, x) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Panels returns the visible panels in z-order, from the bottom of the
+// stack to the top. Hidden panels are not part of the stack
+func Panels() []*Panel {
+	var list []*Panel
+	for p := BottomPanel(); p != nil; p = p.Above() {
+		list = append(list, p)
+	}
+	return list
+}
+
+// TopPanel returns the panel at the top of the stack or nil if there are
+// no visible panels
+func TopPanel() *Panel {
+	return panelFor(C.panel_below(nil))
 }
 
 // UpdatePanels refreshes the panel stack. It must be called prior to
@@ -31,16 +97,24 @@ func UpdatePanels() {
 	return
 }
 
-// Returns a pointer to the panel above in the stack or nil. Passing nil will
-// return the top panel in the stack
+// Above returns the panel directly above p in the stack, or nil if p is
+// the top panel or is hidden. See TopPanel for the top of the stack
 func (p *Panel) Above() *Panel {
-	return &Panel{C.panel_above(p.pan)}
+	return panelFor(C.panel_above(p.pan))
+}
+
+// Below returns the panel directly below p in the stack, or nil if p is
+// the bottom panel or is hidden. See BottomPanel for the bottom of the
+// stack
+func (p *Panel) Below() *Panel {
+	return panelFor(C.panel_below(p.pan))
 }
 
-// Returns a pointer to the panel below in the stack or nil. Passing nil will
-// return the bottom panel in the stack
+// Below returns the panel directly below p in the stack or nil.
+//
+// Deprecated: use p.Below instead
 func Below(p *Panel) *Panel {
-	return &Panel{C.panel_above(p.pan)}
+	return p.Below()
 }
 
 // Move the panel to the bottom of the stack.
@@ -53,9 +127,12 @@ func (p *Panel) Bottom() error {
 
 // Delete panel, removing from the stack.
 func (p *Panel) Delete() error {
+	h := uintptr(C.goncurses_panel_handle(p.pan))
 	if C.del_panel(p.pan) == C.ERR {
 		return errors.New("Failed to delete panel")
 	}
+	userData.remove(h)
+	delete(panels, p.pan)
 	p = nil
 	return nil
 }
@@ -91,6 +168,20 @@ func (p *Panel) Replace(w *Window) error {
 	return nil
 }
 
+// SetUserData associates an arbitrary value, such as the dialog or view the
+// panel belongs to, with the panel. Pass nil to remove it. The value is
+// released when the panel is deleted
+func (p *Panel) SetUserData(v interface{}) {
+	if h := uintptr(C.goncurses_panel_handle(p.pan)); h != 0 {
+		userData.remove(h)
+	}
+	var h uintptr
+	if v != nil {
+		h = userData.add(v)
+	}
+	C.goncurses_set_panel_handle(p.pan, C.uintptr_t(h))
+}
+
 // Show the panel, if hidden, and place it on the top of the stack.
 func (p *Panel) Show() error {
 	if C.show_panel(p.pan) == C.ERR {
@@ -107,7 +198,12 @@ func (p *Panel) Top() error {
 	return nil
 }
 
+// UserData returns the value set by SetUserData, or nil
+func (p *Panel) UserData() interface{} {
+	return userData.get(uintptr(C.goncurses_panel_handle(p.pan)))
+}
+
 // Window returns the window governed by panel
 func (p *Panel) Window() *Window {
-	return &Window{C.panel_window(p.pan)}
+	return &Window{win: C.panel_window(p.pan)}
 }
@@ -0,0 +1,217 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+import "errors"
+
+type Panel struct {
+	win    *Window
+	data   interface{}
+	hidden bool
+}
+
+// panelStack holds the visible panels from the bottom of the stack to the
+// top
+var panelStack []*Panel
+
+// index returns the position of p in the stack, or -1 if it is hidden
+func (p *Panel) index() int {
+	for i, q := range panelStack {
+		if q == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove takes p off the stack
+func (p *Panel) remove() {
+	if i := p.index(); i >= 0 {
+		panelStack = append(panelStack[:i], panelStack[i+1:]...)
+	}
+}
+
+// Panel creates a new panel derived from the window, adding it to the
+// panel stack. The pointer to the original window can still be used to
+// execute most window functions with the exception of Refresh(). Always
+// use panel's Refresh() function.
+func NewPanel(w *Window) *Panel {
+	p := &Panel{win: w}
+	panelStack = append(panelStack, p)
+	return p
+}
+
+// BottomPanel returns the panel at the bottom of the stack or nil if there
+// are no visible panels
+func BottomPanel() *Panel {
+	if len(panelStack) == 0 {
+		return nil
+	}
+	return panelStack[0]
+}
+
+// PanelAt returns the topmost visible panel whose window contains the
+// screen coordinates y, x, or nil if there is none. It is useful for
+// routing mouse events to the panel which was clicked
+func PanelAt(y, x int) *Panel {
+	for p := TopPanel(); p != nil; p = p.Below() {
+		if p.Window().Enclose(y, x) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Panels returns the visible panels in z-order, from the bottom of the
+// stack to the top. Hidden panels are not part of the stack
+func Panels() []*Panel {
+	return append([]*Panel(nil), panelStack...)
+}
+
+// TopPanel returns the panel at the top of the stack or nil if there are
+// no visible panels
+func TopPanel() *Panel {
+	if len(panelStack) == 0 {
+		return nil
+	}
+	return panelStack[len(panelStack)-1]
+}
+
+// UpdatePanels refreshes the panel stack. It must be called prior to
+// using ncurses's DoUpdate()
+func UpdatePanels() {
+	if screen == nil {
+		return
+	}
+	// every panel is drawn again, from the bottom up, so those above
+	// cover those below
+	stdscr.Touch()
+	stdscr.NoutRefresh()
+	for _, p := range panelStack {
+		p.win.Touch()
+		p.win.NoutRefresh()
+	}
+}
+
+// Above returns the panel directly above p in the stack, or nil if p is
+// the top panel or is hidden. See TopPanel for the top of the stack
+func (p *Panel) Above() *Panel {
+	if i := p.index(); i >= 0 && i+1 < len(panelStack) {
+		return panelStack[i+1]
+	}
+	return nil
+}
+
+// Below returns the panel directly below p in the stack, or nil if p is
+// the bottom panel or is hidden. See BottomPanel for the bottom of the
+// stack
+func (p *Panel) Below() *Panel {
+	if i := p.index(); i > 0 {
+		return panelStack[i-1]
+	}
+	return nil
+}
+
+// Below returns the panel directly below p in the stack or nil.
+//
+// Deprecated: use p.Below instead
+func Below(p *Panel) *Panel {
+	return p.Below()
+}
+
+// Move the panel to the bottom of the stack.
+func (p *Panel) Bottom() error {
+	if p.win == nil {
+		return errors.New("Failed to move panel to bottom of stack")
+	}
+	p.remove()
+	p.hidden = false
+	panelStack = append([]*Panel{p}, panelStack...)
+	return nil
+}
+
+// Delete panel, removing from the stack.
+func (p *Panel) Delete() error {
+	if p.win == nil {
+		return errors.New("Failed to delete panel")
+	}
+	p.remove()
+	p.win, p.data = nil, nil
+	return nil
+}
+
+// Hidden returns true if panel is visible, false if not
+func (p *Panel) Hidden() bool {
+	return p.hidden
+}
+
+// Hide the panel
+func (p *Panel) Hide() error {
+	if p.win == nil {
+		return errors.New("Failed to hide panel")
+	}
+	p.remove()
+	p.hidden = true
+	return nil
+}
+
+// Move the panel to the specified location. It is important to never use
+// ncurses movement functions on the window governed by panel. Always use
+// this function
+func (p *Panel) Move(y, x int) error {
+	if p.win == nil || screen == nil || y < 0 || x < 0 ||
+		y+p.win.rows() > screen.rows || x+p.win.cols() > screen.cols {
+		return errors.New("Failed to move panel")
+	}
+	p.win.MoveWindow(y, x)
+	return nil
+}
+
+// Replace panel's associated window with a new one.
+func (p *Panel) Replace(w *Window) error {
+	if p.win == nil || w == nil {
+		return errors.New("Failed to replace window")
+	}
+	p.win = w
+	return nil
+}
+
+// SetUserData associates an arbitrary value, such as the dialog or view the
+// panel belongs to, with the panel. Pass nil to remove it. The value is
+// released when the panel is deleted
+func (p *Panel) SetUserData(v interface{}) {
+	p.data = v
+}
+
+// Show the panel, if hidden, and place it on the top of the stack.
+func (p *Panel) Show() error {
+	if p.win == nil {
+		return errors.New("Failed to show panel")
+	}
+	return p.Top()
+}
+
+// Move panel to the top of the stack
+func (p *Panel) Top() error {
+	if p.win == nil {
+		return errors.New("Failed to move panel to top of stack")
+	}
+	p.remove()
+	p.hidden = false
+	panelStack = append(panelStack, p)
+	return nil
+}
+
+// UserData returns the value set by SetUserData, or nil
+func (p *Panel) UserData() interface{} {
+	return p.data
+}
+
+// Window returns the window governed by panel
+func (p *Panel) Window() *Window {
+	return p.win
+}
@@ -0,0 +1,91 @@
+// +build !windows,!purego
+
+package goncurses_test
+
+import (
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+)
+
+func TestPanelStack(t *testing.T) {
+	term := newTerminal(t, 24, 80)
+	defer term.Close()
+
+	if goncurses.TopPanel() != nil || goncurses.BottomPanel() != nil {
+		t.Fatal("expected an empty panel stack")
+	}
+
+	panels := make([]*goncurses.Panel, 3)
+	for i := range panels {
+		win, err := goncurses.NewWindow(3, 10, i, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer win.Delete()
+		panels[i] = goncurses.NewPanel(win)
+		defer panels[i].Delete()
+	}
+
+	if goncurses.BottomPanel() != panels[0] {
+		t.Error("expected first panel at the bottom")
+	}
+	if goncurses.TopPanel() != panels[2] {
+		t.Error("expected last panel at the top")
+	}
+	if panels[1].Above() != panels[2] || panels[1].Below() != panels[0] {
+		t.Error("unexpected neighbours of the middle panel")
+	}
+	if panels[2].Above() != nil || panels[0].Below() != nil {
+		t.Error("expected nil at the ends of the stack")
+	}
+
+	panels[0].Top()
+	panels[1].Hide()
+	stack := goncurses.Panels()
+	if len(stack) != 2 || stack[0] != panels[2] || stack[1] != panels[0] {
+		t.Errorf("unexpected stack %v", stack)
+	}
+}
+
+func TestPanelAt(t *testing.T) {
+	term := newTerminal(t, 24, 80)
+	defer term.Close()
+
+	panels := make([]*goncurses.Panel, 2)
+	for i, name := range []string{"back", "front"} {
+		win, err := goncurses.NewWindow(4, 10, i*2, i*2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer win.Delete()
+		panels[i] = goncurses.NewPanel(win)
+		defer panels[i].Delete()
+		panels[i].SetUserData(name)
+	}
+
+	tests := []struct {
+		y, x int
+		name interface{}
+	}{
+		{0, 0, "back"},
+		{3, 3, "front"},
+		{5, 11, "front"},
+		{10, 20, nil},
+	}
+	for _, test := range tests {
+		var name interface{}
+		if p := goncurses.PanelAt(test.y, test.x); p != nil {
+			name = p.UserData()
+		}
+		if name != test.name {
+			t.Errorf("%d,%d: expected %v, got %v", test.y, test.x,
+				test.name, name)
+		}
+	}
+
+	panels[1].Hide()
+	if p := goncurses.PanelAt(3, 3); p != panels[0] {
+		t.Error("expected hidden panel to be skipped")
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+// initTerminfo runs the package on a TerminfoBackend for xterm-256color
+// drawing on a VT of rows by cols. Keys written to the returned writer
+// are read by the backend
+func initTerminfo(t *testing.T, rows, cols int) (*goncurses.Window,
+	*cursestest.VT, io.Writer) {
+	vt := cursestest.NewVT(rows, cols)
+	r, w := io.Pipe()
+	b, err := goncurses.NewTerminfoBackend("xterm-256color", vt, r)
+	if err != nil {
+		t.Skip("no terminfo entry for xterm-256color:", err)
+	}
+	b.Resize(rows, cols)
+	stdscr, err := goncurses.InitBackend(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// discard the KEY_RESIZE queued by Resize
+	stdscr.Timeout(0)
+	stdscr.GetChar()
+	stdscr.Timeout(-1)
+	return stdscr, vt, w
+}
+
+func TestTerminfoBackend(t *testing.T) {
+	stdscr, vt, keys := initTerminfo(t, 10, 30)
+	defer goncurses.End()
+
+	if err := goncurses.StartColor(); err != nil {
+		t.Fatal(err)
+	}
+	if err := goncurses.InitPair(1, goncurses.C_RED, goncurses.C_BLUE); err != nil {
+		t.Fatal(err)
+	}
+	stdscr.Box(0, 0)
+	stdscr.ColorOn(1)
+	stdscr.AttrOn(goncurses.A_BOLD)
+	stdscr.MovePrint(1, 2, "hello")
+	stdscr.AttrOff(goncurses.A_BOLD)
+	stdscr.ColorOff(1)
+	stdscr.MovePrint(2, 2, "world")
+	stdscr.Refresh()
+
+	if got := vt.Line(0); got != "┌────────────────────────────┐" {
+		t.Errorf("expected a box, got %q", got)
+	}
+	if got := vt.Line(1); got != "│ hello                      │" {
+		t.Errorf("expected hello, got %q", got)
+	}
+	c := vt.Cell(1, 2)
+	if c.Fg != cursestest.Color(goncurses.C_RED) ||
+		c.Bg != cursestest.Color(goncurses.C_BLUE) ||
+		c.Attr&cursestest.AttrBold == 0 {
+		t.Errorf("expected bold red on blue, got %+v", c)
+	}
+	if c := vt.Cell(2, 2); c.Fg != cursestest.DefaultColor ||
+		c.Attr != 0 {
+		t.Errorf("expected default colors, got %+v", c)
+	}
+	if y, x, _ := vt.Cursor(); y != 2 || x != 7 {
+		t.Errorf("expected cursor at 2, 7, got %d, %d", y, x)
+	}
+
+	stdscr.Keypad(true)
+	go keys.Write([]byte("\x1b[Aq"))
+	if k := stdscr.GetChar(); k != goncurses.KEY_UP {
+		t.Errorf("expected KEY_UP, got %v", k)
+	}
+	if k := stdscr.GetChar(); k != 'q' {
+		t.Errorf("expected q, got %v", k)
+	}
+}
+
+func TestPureGoPanels(t *testing.T) {
+	stdscr, vt, _ := initTerminfo(t, 8, 20)
+	defer goncurses.End()
+	stdscr.Refresh()
+
+	w1, _ := goncurses.NewWindow(3, 6, 1, 1)
+	w1.Print("aaaaaaaaaaaaaaaaaa")
+	w2, _ := goncurses.NewWindow(3, 6, 2, 3)
+	w2.Print("bbbbbbbbbbbbbbbbbb")
+	p1 := goncurses.NewPanel(w1)
+	p2 := goncurses.NewPanel(w2)
+	goncurses.UpdatePanels()
+	goncurses.Update()
+	if got := vt.Line(2); got != " aabbbbbb" {
+		t.Errorf("expected the second panel on top, got %q", got)
+	}
+
+	p1.Top()
+	goncurses.UpdatePanels()
+	goncurses.Update()
+	if got := vt.Line(2); got != " aaaaaabb" {
+		t.Errorf("expected the first panel on top, got %q", got)
+	}
+	if p := goncurses.PanelAt(2, 7); p != p2 {
+		t.Errorf("expected the second panel at 2, 7, got %v", p)
+	}
+
+	p1.Hide()
+	goncurses.UpdatePanels()
+	goncurses.Update()
+	if got := vt.Line(1); got != "" {
+		t.Errorf("expected the first panel to be hidden, got %q", got)
+	}
+	if ps := goncurses.Panels(); len(ps) != 1 || ps[0] != p2 {
+		t.Errorf("expected only the second panel in the stack, got %v", ps)
+	}
+}
+
+func TestPureGoWideCharacters(t *testing.T) {
+	var out bytes.Buffer
+	b, err := goncurses.NewTerminfoBackend("xterm-256color", &out,
+		strings.NewReader(""))
+	if err != nil {
+		t.Skip("no terminfo entry for xterm-256color:", err)
+	}
+	b.Resize(4, 10)
+	stdscr, err := goncurses.InitBackend(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer goncurses.End()
+
+	// wide characters take two columns and wrap whole
+	stdscr.MovePrint(0, 0, "日本x")
+	if y, x := stdscr.CursorYX(); y != 0 || x != 5 {
+		t.Errorf("expected the cursor at 0, 5, got %d, %d", y, x)
+	}
+	if c := stdscr.MoveInChar(0, 4) & goncurses.A_CHARTEXT; c != 'x' {
+		t.Errorf("expected x at 0, 4, got %q", rune(c))
+	}
+	stdscr.MovePrint(1, 9, "語")
+	if y, x := stdscr.CursorYX(); y != 2 || x != 2 {
+		t.Errorf("expected the wide character on the next line, got the "+
+			"cursor at %d, %d", y, x)
+	}
+	stdscr.MovePrint(3, 0, "é")
+	if y, x := stdscr.CursorYX(); y != 3 || x != 1 {
+		t.Errorf("expected a combining character to take no room, got "+
+			"%d, %d", y, x)
+	}
+	stdscr.Refresh()
+
+	// only x changes, so it is written at the column it is shown in
+	out.Reset()
+	stdscr.MovePrint(0, 4, "y")
+	stdscr.Refresh()
+	if !strings.Contains(out.String(), "\x1b[1;5Hy") {
+		t.Errorf("expected y to be written at 0, 4, got %q", out.String())
+	}
+
+	// overwriting half of a wide character blanks the other half
+	out.Reset()
+	stdscr.MovePrint(0, 1, "z")
+	stdscr.Refresh()
+	if !strings.Contains(out.String(), "\x1b[1;1H z") {
+		t.Errorf("expected the wide character to be blanked, got %q",
+			out.String())
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder writes a terminal session to a file in the asciicast version 2
+// format used by asciinema: a header line followed by one line for each
+// chunk of output, chunk of input or change of size, stamped with the time
+// since the recording started. Recordings can be played back with Replay or
+// with asciinema itself.
+//
+// A Recorder is normally passed to NewRecordedTerm, which records
+// everything ncurses writes to the terminal and everything typed on it. A
+// Recorder is safe for use by multiple goroutines.
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	started bool
+	err     error
+	pending map[string][]byte // incomplete UTF-8 sequences by event type
+
+	// Env holds environment variables to record in the header. If nil,
+	// TERM and SHELL are taken from the environment
+	Env map[string]string
+	// Title, if not empty, is recorded as the title of the session
+	Title string
+}
+
+// NewRecorder returns a Recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w, pending: map[string][]byte{}}
+}
+
+// Start writes the header of the recording for a terminal of the given
+// size and starts the clock. It is called by NewRecordedTerm and must be
+// called before any events are recorded otherwise
+func (r *Recorder) Start(rows, cols int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return errors.New("Recording already started")
+	}
+	env := r.Env
+	if env == nil {
+		env = map[string]string{}
+		for _, name := range []string{"TERM", "SHELL"} {
+			if v, ok := os.LookupEnv(name); ok {
+				env[name] = v
+			}
+		}
+	}
+	r.start = time.Now()
+	r.started = true
+	header := struct {
+		Version   int               `json:"version"`
+		Width     int               `json:"width"`
+		Height    int               `json:"height"`
+		Timestamp int64             `json:"timestamp"`
+		Title     string            `json:"title,omitempty"`
+		Env       map[string]string `json:"env,omitempty"`
+	}{2, cols, rows, r.start.Unix(), r.Title, env}
+	return r.writeLine(header)
+}
+
+// Output records data written to the terminal
+func (r *Recorder) Output(data []byte) error {
+	return r.event("o", data)
+}
+
+// Input records data typed on the terminal
+func (r *Recorder) Input(data []byte) error {
+	return r.event("i", data)
+}
+
+// Resize records a change in the size of the terminal
+func (r *Recorder) Resize(rows, cols int) error {
+	return r.event("r", []byte(fmt.Sprintf("%dx%d", cols, rows)))
+}
+
+// Err returns the first error encountered writing the recording. Recording
+// stops after an error
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// event records data as an event of type kind. Events hold text, so a
+// UTF-8 sequence split between two chunks of data is held back and
+// recorded with the second
+func (r *Recorder) event(kind string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started {
+		return errors.New("Recording not started")
+	}
+	data = append(r.pending[kind], data...)
+	n := len(data)
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				n = len(data) - i
+			}
+			break
+		}
+	}
+	r.pending[kind] = append([]byte(nil), data[n:]...)
+	if n == 0 {
+		return r.err
+	}
+	t := time.Since(r.start).Seconds()
+	return r.writeLine([]interface{}{t, kind, string(data[:n])})
+}
+
+// writeLine writes v as a line of JSON. An error stops the recording
+func (r *Recorder) writeLine(v interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	enc := json.NewEncoder(r.w)
+	enc.SetEscapeHTML(false)
+	r.err = enc.Encode(v)
+	return r.err
+}
+
+// Replay plays back a recording made by a Recorder, or any asciicast
+// version 2 recording, by writing its output to w. Events are played
+// speed times faster than they were recorded, with a speed of zero playing
+// them without any delay at all. Input events are skipped.
+//
+// If w has a Resize(rows, cols int) method, as the VT emulator in the
+// cursestest package does, it is called with the size of the terminal at
+// the start of the recording and whenever the size changes.
+func Replay(w io.Writer, r io.Reader, speed float64) error {
+	resizer, _ := w.(interface {
+		Resize(rows, cols int)
+	})
+
+	dec := json.NewDecoder(r)
+	var header struct {
+		Version       int
+		Width, Height int
+	}
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("Reading recording header: %v", err)
+	}
+	if header.Version != 2 {
+		return fmt.Errorf("Unsupported recording version %d", header.Version)
+	}
+	if resizer != nil {
+		resizer.Resize(header.Height, header.Width)
+	}
+
+	start := time.Now()
+	for {
+		var ev []interface{}
+		err := dec.Decode(&ev)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Reading recording: %v", err)
+		}
+		if len(ev) != 3 {
+			return errors.New("Malformed recording event")
+		}
+		t, ok1 := ev[0].(float64)
+		kind, ok2 := ev[1].(string)
+		data, ok3 := ev[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return errors.New("Malformed recording event")
+		}
+
+		if speed > 0 {
+			at := start.Add(time.Duration(t / speed * float64(time.Second)))
+			if d := time.Until(at); d > 0 {
+				time.Sleep(d)
+			}
+		}
+		switch kind {
+		case "o":
+			if _, err := io.WriteString(w, data); err != nil {
+				return err
+			}
+		case "r":
+			var rows, cols int
+			if _, err := fmt.Sscanf(data, "%dx%d", &cols, &rows); err != nil {
+				return fmt.Errorf("Malformed resize event %q", data)
+			}
+			if resizer != nil {
+				resizer.Resize(rows, cols)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!purego
+
+package goncurses
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rthornton128/goncurses/internal/pty"
+)
+
+// NewRecordedTerm is like NewTerm but records the session with rec. out
+// and in must be terminals, usually os.Stdout and os.Stdin.
+//
+// ncurses is run on a pseudo-terminal of the same size as out, which is
+// bridged to the real terminal: everything written to the pseudo-terminal
+// is copied to out and recorded as output, and everything read from in is
+// passed on to the pseudo-terminal and recorded as input. in is put into
+// raw mode so that the modes ncurses sets apply to the pseudo-terminal
+// instead. Changes in the size of out are recorded and passed on to the
+// pseudo-terminal, though, as with NewTerm, the program must call
+// ResizeTerm itself.
+//
+// Delete stops the recording and returns in to its original mode. Nothing
+// typed after Delete is read from in.
+func NewRecordedTerm(termType string, out, in *os.File, rec *Recorder) (
+	*Screen, error) {
+	rows, cols, err := pty.Size(out)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 || cols == 0 {
+		// the size is unknown, as on a serial line
+		rows, cols = 24, 80
+	}
+	restore, err := pty.MakeRaw(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := rec.Start(rows, cols); err != nil {
+		restore()
+		return nil, err
+	}
+	b, err := newPtyBridge(out, in, rows, cols, func(p []byte) {
+		rec.Output(p)
+	}, func(p []byte) {
+		rec.Input(p)
+	})
+	if err != nil {
+		restore()
+		return nil, err
+	}
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			if r, c, err := pty.Size(out); err == nil && (r != rows ||
+				c != cols) {
+				rows, cols = r, c
+				b.resize(rows, cols)
+				rec.Resize(rows, cols)
+			}
+		}
+	}()
+
+	release := func() {
+		signal.Stop(winch)
+		close(winch)
+		b.close()
+		restore()
+	}
+	screen, err := NewTerm(termType, b.slave, b.slave)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	screen.release = release
+	return screen, nil
+}
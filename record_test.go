@@ -0,0 +1,54 @@
+package goncurses_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rthornton128/goncurses"
+	"github.com/rthornton128/goncurses/cursestest"
+)
+
+func TestRecorderSplitRunes(t *testing.T) {
+	var cast bytes.Buffer
+	rec := goncurses.NewRecorder(&cast)
+	rec.Env = map[string]string{}
+	if err := rec.Start(2, 10); err != nil {
+		t.Fatal(err)
+	}
+	s := []byte("a\u2500b")
+	rec.Output(s[:2])
+	rec.Output(s[2:3])
+	rec.Output(s[3:])
+	rec.Resize(3, 12)
+
+	lines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+	want := []string{`"o","a"`, `"o","─b"`, `"r","12x3"`}
+	if len(lines) != len(want)+1 {
+		t.Fatalf("expected %d events, got:\n%s", len(want), cast.String())
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(lines[i+1], w+"]") {
+			t.Errorf("expected event %d to end %s], got %s", i, w, lines[i+1])
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	cast := `{"version":2,"width":10,"height":2}
+[0.1,"o","\u001b[1;1Hhello"]
+[0.2,"i","ignored"]
+[0.3,"r","12x3"]
+[0.4,"o","\u001b[3;1Hworld"]
+`
+	vt := cursestest.NewVT(1, 1)
+	if err := goncurses.Replay(vt, strings.NewReader(cast), 0); err != nil {
+		t.Fatal(err)
+	}
+	if rows, cols := vt.Size(); rows != 3 || cols != 12 {
+		t.Errorf("expected a 3x12 screen, got %dx%d", rows, cols)
+	}
+	if want := "hello\n\nworld"; vt.String() != want {
+		t.Errorf("expected %q, got %q", want, vt.String())
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+#include <curses.h>
+#include "_cgo_export.h"
+
+static int goncurses_ripoff0(WINDOW *w, int cols) {
+	goncursesRipOff(0, w, cols);
+	return OK;
+}
+static int goncurses_ripoff1(WINDOW *w, int cols) {
+	goncursesRipOff(1, w, cols);
+	return OK;
+}
+static int goncurses_ripoff2(WINDOW *w, int cols) {
+	goncursesRipOff(2, w, cols);
+	return OK;
+}
+static int goncurses_ripoff3(WINDOW *w, int cols) {
+	goncursesRipOff(3, w, cols);
+	return OK;
+}
+static int goncurses_ripoff4(WINDOW *w, int cols) {
+	goncursesRipOff(4, w, cols);
+	return OK;
+}
+
+int goncurses_ripoffline(int line, int n) {
+	switch (n) {
+	case 0: return ripoffline(line, goncurses_ripoff0);
+	case 1: return ripoffline(line, goncurses_ripoff1);
+	case 2: return ripoffline(line, goncurses_ripoff2);
+	case 3: return ripoffline(line, goncurses_ripoff3);
+	case 4: return ripoffline(line, goncurses_ripoff4);
+	}
+	return ERR;
+}
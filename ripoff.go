@@ -0,0 +1,105 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+package goncurses
+
+// #include <curses.h>
+// int goncurses_ripoffline(int line, int n);
+import "C"
+
+import "errors"
+
+// maxRipOffs is the number of lines ncurses allows to be ripped off, and
+// the number of trampolines in ripoff.c
+const maxRipOffs = 5
+
+// ripOff is a line passed to RipOffLine
+type ripOff struct {
+	init   func(*Window, int)
+	bottom bool
+}
+
+// ripOffs holds the lines passed to RipOffLine since the last screen was
+// made. ncurses calls their functions, in order, while making the next one
+// and then forgets the lines, as does goncursesRipOff
+var (
+	ripOffs      []ripOff
+	ripOffCalled int
+)
+
+// ripEntry is an entry of the table in which ncurses keeps the windows of
+// ripped off lines
+type ripEntry struct {
+	screen *C.SCREEN // nil for the screen made by Init
+	bottom bool
+}
+
+// ripTable follows the table ncurses keeps of ripped off lines. Each screen
+// made after lines are ripped off fills it from the start, leaving the
+// entries past its own lines as they were, and resizing any screen redraws
+// the window of every entry for a bottom line. Screens must not be freed
+// while an entry refers to one of their bottom lines
+var ripTable [maxRipOffs]ripEntry
+
+//export goncursesRipOff
+func goncursesRipOff(n C.int, win *C.WINDOW, cols C.int) {
+	if int(n) >= len(ripOffs) {
+		return
+	}
+	ripOffs[n].init(&Window{win: win}, int(cols))
+	if ripOffCalled++; ripOffCalled == len(ripOffs) {
+		ripOffs, ripOffCalled = nil, 0
+	}
+}
+
+// claimRipOffs records that screen, which has just been made, ripped off
+// lines
+func claimRipOffs(screen *C.SCREEN, lines []ripOff) {
+	for i, r := range lines {
+		ripTable[i] = ripEntry{screen, r.bottom}
+	}
+}
+
+// ripsBottom reports whether the table refers to a bottom line ripped off
+// by a screen made by NewTerm
+func ripsBottom() bool {
+	for _, e := range ripTable {
+		if e.screen != nil && e.bottom {
+			return true
+		}
+	}
+	return false
+}
+
+// RipOffLine reserves a line at the top, or the bottom, of the screen for
+// a status bar or the like. The line is left out of stdscr, which is one
+// line shorter for each line ripped off, so clearing stdscr leaves it
+// alone. It must be called before Init or NewTerm, which call init with the
+// one line window of the ripped off line and its width. The window is
+// drawn on like any other and refreshed with Refresh or NoutRefresh. At
+// most five lines may be ripped off from a screen.
+//
+// ncurses keeps the windows of ripped off lines in a table shared by every
+// screen and redraws those at the bottom whenever a screen is resized. A
+// screen made by NewTerm which ripped off a bottom line therefore stays
+// allocated after Screen.Delete, as does every screen deleted after it,
+// until a later screen has replaced its entries by ripping off at least as
+// many lines, none of them at the bottom, and been deleted in turn. Screens
+// which rip off only top lines are freed as usual
+func RipOffLine(top bool, init func(w *Window, cols int)) error {
+	if len(ripOffs) == maxRipOffs {
+		return errors.New("Too many lines ripped off")
+	}
+	line := C.int(-1)
+	if top {
+		line = 1
+	}
+	if C.goncurses_ripoffline(line, C.int(len(ripOffs))) == C.ERR {
+		return errors.New("Failed to rip off line")
+	}
+	ripOffs = append(ripOffs, ripOff{init, !top})
+	return nil
+}
@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #include <stdio.h>
@@ -10,6 +12,7 @@ package goncurses
 // #endif
 // #include <stdlib.h>
 // #include <curses.h>
+// #include "goncurses.h"
 import "C"
 
 import (
@@ -18,7 +21,37 @@ import (
 	"unsafe"
 )
 
-type Screen struct{ scrPtr *C.SCREEN }
+// ErrScreenClosed is returned when a Screen is used after it has been
+// closed or deleted
+var ErrScreenClosed = errors.New("Screen has been closed")
+
+type Screen struct {
+	scrPtr *C.SCREEN
+	// stdscr is the screen's standard screen window
+	stdscr *C.WINDOW
+	// closed is set once the screen has been deleted
+	closed bool
+	// release, if not nil, is called by Delete
+	release func()
+	// setSize, if not nil, changes the size of the terminal for Resize
+	setSize func(rows, cols int) error
+}
+
+// screens maps the screens created by NewTerm to their Screen so the same
+// Screen is returned by Set
+var screens = make(map[*C.SCREEN]*Screen)
+
+// deleted holds the screens waiting to be freed by Delete
+var deleted []*C.SCREEN
+
+// screenFor returns the Screen for s, whose standard screen window is
+// stdscr, creating one if s was not made by NewTerm
+func screenFor(s *C.SCREEN, stdscr *C.WINDOW) *Screen {
+	if screen, ok := screens[s]; ok {
+		return screen
+	}
+	return &Screen{scrPtr: s, stdscr: stdscr}
+}
 
 // NewTerm returns a new Screen, representing a physical terminal. If using
 // this function to generate a new Screen you should not call Init().
@@ -26,10 +59,17 @@ type Screen struct{ scrPtr *C.SCREEN }
 // will need to be done manually. When finished with a terminal, you must
 // call End() in reverse order that each terminal was created in. After you
 // are finished with the screen you must call Delete to free the memory
-// allocated to it. This function is usually only useful for programs using
-// multiple terminals or test for terminal capabilities. The argument termType
-// is the type of terminal to be used ($TERM is used if value is "" which also
-// has the same effect of using os.Getenv("TERM"))
+// allocated to it, or call Close to do both. This function is usually only
+// useful for programs using multiple terminals or test for terminal
+// capabilities. The argument termType is the type of terminal to be used
+// ($TERM is used if value is "" which also has the same effect of using
+// os.Getenv("TERM")). The capabilities of the terminal can be queried with
+// methods such as TermName and HasColors.
+//
+// The memory of a deleted screen is only freed once every screen made by
+// NewTerm has been deleted, as explained under Delete, so a program which
+// keeps one screen open while opening and deleting others holds on to all
+// of them until the last is deleted.
 func NewTerm(termType string, out, in *os.File) (*Screen, error) {
 	var tt, wr, rd *C.char
 	if termType == "" {
@@ -42,25 +82,89 @@ func NewTerm(termType string, out, in *os.File) (*Screen, error) {
 	defer C.free(unsafe.Pointer(rd))
 
 	cout, cin := C.fdopen(C.int(out.Fd()), wr), C.fdopen(C.int(in.Fd()), rd)
+	lines := ripOffs
 	screen := C.newterm(tt, cout, cin)
 	if screen == nil {
 		return nil, errors.New("Failed to create new screen")
 	}
-	return &Screen{screen}, nil
+	claimRipOffs(screen, lines)
+	// newterm makes the new screen current
+	s := &Screen{scrPtr: screen, stdscr: C.stdscr}
+	screens[screen] = s
+	delete(noEcho, s.stdscr)
+	return s, nil
 }
 
-// Set the screen to be the current, active screen
+// Set the screen to be the current, active screen. The previously active
+// screen is returned
 func (s *Screen) Set() (*Screen, error) {
+	if s.closed {
+		return nil, ErrScreenClosed
+	}
+	stdscr := C.stdscr
 	screen := C.set_term(s.scrPtr)
 	if screen == nil {
 		return nil, errors.New("Failed to set screen")
 	}
-	return &Screen{screen}, nil
+	return screenFor(screen, stdscr), nil
 }
 
-// Delete frees memory allocated to the screen. This function
+// Delete frees memory allocated to the screen. The screen must have been
+// ended with End first; Close does both. Deleting a screen more than once
+// has no effect.
+//
+// ncurses frees the windows of every screen, not only those of the one
+// being deleted, so while other screens made by NewTerm remain in use the
+// screen is only closed and freeing it is put off until the last of them
+// is deleted. Screens which ripped off a bottom line with RipOffLine are
+// kept for longer, as explained there.
 func (s *Screen) Delete() {
-	C.delscreen(s.scrPtr)
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(screens, s.scrPtr)
+	if s.release != nil {
+		s.release()
+		s.release = nil
+	}
+	deleted = append(deleted, s.scrPtr)
+	if len(screens) > 0 || ripsBottom() {
+		return
+	}
+	for _, scr := range deleted {
+		C.delscreen(scr)
+	}
+	deleted = nil
+}
+
+// Size returns the number of rows and columns of the screen, or zero for
+// both once it has been closed
+func (s *Screen) Size() (rows, cols int) {
+	if s.closed {
+		return 0, 0
+	}
+	return int(C.getmaxy(s.stdscr)), int(C.getmaxx(s.stdscr))
+}
+
+// Resize changes the size of the screen to rows by cols, as ResizeTerm
+// does for the current screen, and queues KEY_RESIZE as input to the
+// screen. The pseudo-terminal of a screen made by NewTermIO is resized
+// too. Call it when the terminal at the other end of the screen reports a
+// change of size
+func (s *Screen) Resize(rows, cols int) error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if s.setSize != nil {
+		if err := s.setSize(rows, cols); err != nil {
+			return err
+		}
+	}
+	if C.ncurses_resizeterm_sp(s.scrPtr, C.int(rows), C.int(cols)) == C.ERR {
+		return errors.New("Failed to resize screen")
+	}
+	return nil
 }
 
 // End is just a wrapper for the global End function. This helper function
@@ -69,6 +173,51 @@ func (s *Screen) Delete() {
 // set then called End so it is closed properly. You must make sure that
 // Delete is called once done with the screen/terminal.
 func (s *Screen) End() {
+	if s.closed {
+		return
+	}
 	s.Set()
 	End()
 }
+
+// Close ends the screen, restoring its terminal to normal operation, and
+// then deletes it. Unlike End, it leaves the current screen as it was. Any
+// use of the screen afterwards returns ErrScreenClosed, including closing
+// it again. Screens sharing a terminal should still be closed in the
+// reverse order they were created in
+func (s *Screen) Close() error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if !s.IsEnd() {
+		C.ncurses_endwin_sp(s.scrPtr)
+	}
+	s.Delete()
+	return nil
+}
+
+// IsEnd returns true if the screen has been ended with End or closed
+func (s *Screen) IsEnd() bool {
+	if s.closed {
+		return true
+	}
+	return bool(C.ncurses_isendwin_sp(s.scrPtr))
+}
+
+// TermName returns the name of the screen's terminal type, as passed to
+// NewTerm or taken from $TERM, or an empty string if it has been closed
+func (s *Screen) TermName() string {
+	if s.closed {
+		return ""
+	}
+	return C.GoString(C.ncurses_termname_sp(s.scrPtr))
+}
+
+// HasColors returns true if the screen's terminal is capable of displaying
+// colors, without having to make the screen current
+func (s *Screen) HasColors() bool {
+	if s.closed {
+		return false
+	}
+	return bool(C.ncurses_has_colors_sp(s.scrPtr))
+}
@@ -0,0 +1,200 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego
+
+package goncurses
+
+// #include <curses.h>
+// #include "goncurses.h"
+import "C"
+
+import "errors"
+
+// The methods in this file are the screen-specific counterparts of the
+// package level functions of the same name. Where those act on whichever
+// screen was last made current by Set, these always act on the screen they
+// are called on, through the _sp functions of ncurses, so programs driving
+// several terminals need not call Set at all and the current screen never
+// changes. Windows and pads created by them belong to the screen and their
+// methods act on it whichever screen is current. Once the screen has been
+// closed, those returning an error return ErrScreenClosed and the rest do
+// nothing.
+
+// StdScr returns the Window which represents the whole of the screen, or
+// nil if the screen has been closed
+func (s *Screen) StdScr() *Window {
+	if s.closed {
+		return nil
+	}
+	return &Window{win: s.stdscr, screen: s}
+}
+
+// NewWindow creates a window on the screen of size h(eight) and w(idth) at
+// y, x
+func (s *Screen) NewWindow(h, w, y, x int) (*Window, error) {
+	if s.closed {
+		return nil, ErrScreenClosed
+	}
+	win := C.ncurses_newwin_sp(s.scrPtr, C.int(h), C.int(w), C.int(y),
+		C.int(x))
+	if win == nil {
+		return nil, errors.New("Failed to create a new window")
+	}
+	return &Window{win: win, screen: s}, nil
+}
+
+// NewPad creates a pad on the screen of h(eight) by w(idth). See NewPad
+func (s *Screen) NewPad(h, w int) (*Pad, error) {
+	if s.closed {
+		return nil, ErrScreenClosed
+	}
+	p := C.ncurses_newpad_sp(s.scrPtr, C.int(h), C.int(w))
+	if p == nil {
+		return nil, errors.New("Failed to create pad")
+	}
+	return &Pad{&Window{win: p, screen: s}}, nil
+}
+
+// Update the screen, refreshing all of its windows
+func (s *Screen) Update() error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if C.ncurses_doupdate_sp(s.scrPtr) == C.ERR {
+		return errors.New("Failed to update")
+	}
+	return nil
+}
+
+// Beep sounds the bell of the screen's terminal. See Beep
+func (s *Screen) Beep() {
+	if s.closed {
+		return
+	}
+	C.ncurses_beep_sp(s.scrPtr)
+}
+
+// Flash flashes the screen. See Flash
+func (s *Screen) Flash() {
+	if s.closed {
+		return
+	}
+	C.ncurses_flash_sp(s.scrPtr)
+}
+
+// CBreak turns on/off line buffering of the screen's input. See CBreak
+func (s *Screen) CBreak(on bool) {
+	if s.closed {
+		return
+	}
+	C.ncurses_cbreak_sp(s.scrPtr, C.bool(on))
+}
+
+// Echo turns on/off the printing of characters typed on the screen's
+// terminal
+func (s *Screen) Echo(on bool) {
+	if s.closed {
+		return
+	}
+	C.ncurses_echo_sp(s.scrPtr, C.bool(on))
+	if on {
+		delete(noEcho, s.stdscr)
+		return
+	}
+	noEcho[s.stdscr] = true
+}
+
+// Raw turns on/off raw input on the screen. See Raw
+func (s *Screen) Raw(on bool) {
+	if s.closed {
+		return
+	}
+	C.ncurses_raw_sp(s.scrPtr, C.bool(on))
+}
+
+// Cursor sets the visibility of the screen's cursor. Options are: 0
+// (invisible/hidden), 1 (normal) and 2 (extra-visible)
+func (s *Screen) Cursor(vis byte) error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if C.ncurses_curs_set_sp(s.scrPtr, C.int(vis)) == C.ERR {
+		return errors.New("Failed to set cursor visibility")
+	}
+	return nil
+}
+
+// FlushInput flushes all input waiting to be read from the screen
+func (s *Screen) FlushInput() error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if C.ncurses_flushinp_sp(s.scrPtr) == C.ERR {
+		return errors.New("Flush input failed")
+	}
+	return nil
+}
+
+// StartColor enables colors on the screen. Will return an error if its
+// terminal is not capable of displaying colors
+func (s *Screen) StartColor() error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if !C.ncurses_has_colors_sp(s.scrPtr) {
+		return errors.New("Terminal does not support colors")
+	}
+	if C.ncurses_start_color_sp(s.scrPtr) == C.ERR {
+		return errors.New("Failed to enable color mode")
+	}
+	return nil
+}
+
+// InitPair sets the colour pair designated by 'pair' on the screen to fg
+// and bg colors
+func (s *Screen) InitPair(pair, fg, bg int16) error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if pair <= 0 {
+		return errors.New("Color pair out of range")
+	}
+	if C.ncurses_init_pair_sp(s.scrPtr, C.short(pair), C.short(fg),
+		C.short(bg)) == C.ERR {
+		return errors.New("Failed to init color pair")
+	}
+	return nil
+}
+
+// InitColor sets the RGB values of color number col on the screen. See
+// InitColor
+func (s *Screen) InitColor(col, r, g, b int16) error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if C.ncurses_init_color_sp(s.scrPtr, C.short(col), C.short(r),
+		C.short(g), C.short(b)) == C.ERR {
+		return errors.New("Failed to set new color definition")
+	}
+	return nil
+}
+
+// UseDefaultColors assigns the default colors of the screen's terminal to
+// color number -1. See UseDefaultColors
+func (s *Screen) UseDefaultColors() error {
+	if s.closed {
+		return ErrScreenClosed
+	}
+	if C.ncurses_use_default_colors_sp(s.scrPtr) == C.ERR {
+		return errors.New("Failed to assume default colours.")
+	}
+	return nil
+}
+
+// Screen returns the Screen the window was created on, or nil if it was
+// not created by one of the Screen methods, or derived from such a window
+func (w *Window) Screen() *Screen {
+	return w.screen
+}
@@ -0,0 +1,521 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rthornton128/goncurses/internal/terminfo"
+)
+
+// attrCaps maps attributes to the terminfo capabilities turning them on
+var attrCaps = []struct {
+	attr Char
+	cap  int
+}{
+	{A_STANDOUT, terminfo.EnterStandoutMode},
+	{A_UNDERLINE, terminfo.EnterUnderlineMode},
+	{A_REVERSE, terminfo.EnterReverseMode},
+	{A_BLINK, terminfo.EnterBlinkMode},
+	{A_DIM, terminfo.EnterDimMode},
+	{A_BOLD, terminfo.EnterBoldMode},
+	{A_INVIS, terminfo.EnterSecureMode},
+}
+
+// keyCaps maps the terminfo key capabilities to the keys they send
+var keyCaps = map[int]Key{
+	terminfo.KeyBackspace: KEY_BACKSPACE, terminfo.KeyCatab: KEY_CATAB,
+	terminfo.KeyClear: KEY_CLEAR, terminfo.KeyCtab: KEY_CTAB,
+	terminfo.KeyDc: KEY_DC, terminfo.KeyDl: KEY_DL,
+	terminfo.KeyDown: KEY_DOWN, terminfo.KeyEic: KEY_EIC,
+	terminfo.KeyEol: KEY_EOL, terminfo.KeyEos: KEY_EOS,
+	terminfo.KeyF1: KEY_F1, terminfo.KeyF2: KEY_F2, terminfo.KeyF3: KEY_F3,
+	terminfo.KeyF4: KEY_F4, terminfo.KeyF5: KEY_F5, terminfo.KeyF6: KEY_F6,
+	terminfo.KeyF7: KEY_F7, terminfo.KeyF8: KEY_F8, terminfo.KeyF9: KEY_F9,
+	terminfo.KeyF10: KEY_F10, terminfo.KeyF11: KEY_F11,
+	terminfo.KeyF12: KEY_F12, terminfo.KeyHome: KEY_HOME,
+	terminfo.KeyIc: KEY_IC, terminfo.KeyIl: KEY_IL,
+	terminfo.KeyLeft: KEY_LEFT, terminfo.KeyLl: KEY_LL,
+	terminfo.KeyNpage: KEY_PAGEDOWN, terminfo.KeyPpage: KEY_PAGEUP,
+	terminfo.KeyRight: KEY_RIGHT, terminfo.KeySf: KEY_SF,
+	terminfo.KeySr: KEY_SR, terminfo.KeyStab: KEY_STAB,
+	terminfo.KeyUp: KEY_UP, terminfo.KeyA1: KEY_A1, terminfo.KeyA3: KEY_A3,
+	terminfo.KeyB2: KEY_B2, terminfo.KeyC1: KEY_C1, terminfo.KeyC3: KEY_C3,
+	terminfo.KeyBtab: KEY_BTAB, terminfo.KeyBeg: KEY_BEG,
+	terminfo.KeyCancel: KEY_CANCEL, terminfo.KeyClose: KEY_CLOSE,
+	terminfo.KeyCommand: KEY_COMMAND, terminfo.KeyCopy: KEY_COPY,
+	terminfo.KeyCreate: KEY_CREATE, terminfo.KeyEnd: KEY_END,
+	terminfo.KeyEnter: KEY_ENTER, terminfo.KeyExit: KEY_EXIT,
+	terminfo.KeyFind: KEY_FIND, terminfo.KeyHelp: KEY_HELP,
+	terminfo.KeyMark: KEY_MARK, terminfo.KeyMessage: KEY_MESSAGE,
+	terminfo.KeyMove: KEY_MOVE, terminfo.KeyNext: KEY_NEXT,
+	terminfo.KeyOpen: KEY_OPEN, terminfo.KeyOptions: KEY_OPTIONS,
+	terminfo.KeyPrevious: KEY_PREVIOUS, terminfo.KeyPrint: KEY_PRINT,
+	terminfo.KeyRedo: KEY_REDO, terminfo.KeyReference: KEY_REFERENCE,
+	terminfo.KeyRefresh: KEY_REFRESH, terminfo.KeyReplace: KEY_REPLACE,
+	terminfo.KeyRestart: KEY_RESTART, terminfo.KeyResume: KEY_RESUME,
+	terminfo.KeySave: KEY_SAVE, terminfo.KeySuspend: KEY_SUSPEND,
+	terminfo.KeyUndo: KEY_UNDO, terminfo.KeySbeg: KEY_SBEG,
+	terminfo.KeyScancel: KEY_SCANCEL, terminfo.KeyScommand: KEY_SCOMMAND,
+	terminfo.KeyScopy: KEY_SCOPY, terminfo.KeyScreate: KEY_SCREATE,
+	terminfo.KeySdc: KEY_SDC, terminfo.KeySdl: KEY_SDL,
+	terminfo.KeySelect: KEY_SELECT, terminfo.KeySend: KEY_SEND,
+	terminfo.KeySeol: KEY_SEOL, terminfo.KeySexit: KEY_SEXIT,
+	terminfo.KeySfind: KEY_SFIND, terminfo.KeyShelp: KEY_SHELP,
+	terminfo.KeyShome: KEY_SHOME, terminfo.KeySic: KEY_SIC,
+	terminfo.KeySleft: KEY_SLEFT, terminfo.KeySmessage: KEY_SMESSAGE,
+	terminfo.KeySmove: KEY_SMOVE, terminfo.KeySnext: KEY_SNEXT,
+	terminfo.KeySoptions: KEY_SOPTIONS, terminfo.KeySprevious: KEY_SPREVIOUS,
+	terminfo.KeySprint: KEY_SPRINT, terminfo.KeySredo: KEY_SREDO,
+	terminfo.KeySreplace: KEY_SREPLACE, terminfo.KeySright: KEY_SRIGHT,
+	terminfo.KeySrsume: KEY_SRSUME, terminfo.KeySsave: KEY_SSAVE,
+	terminfo.KeySsuspend: KEY_SSUSPEND, terminfo.KeySundo: KEY_SUNDO,
+}
+
+// ansiKeys are the sequences sent by the cursor and function keys of most
+// terminals in either of their modes, which are recognized even when the
+// terminfo entry does not list them
+var ansiKeys = map[string]Key{
+	"\x1b[A": KEY_UP, "\x1b[B": KEY_DOWN, "\x1b[C": KEY_RIGHT,
+	"\x1b[D": KEY_LEFT, "\x1b[H": KEY_HOME, "\x1b[F": KEY_END,
+	"\x1bOA": KEY_UP, "\x1bOB": KEY_DOWN, "\x1bOC": KEY_RIGHT,
+	"\x1bOD": KEY_LEFT, "\x1bOH": KEY_HOME, "\x1bOF": KEY_END,
+	"\x1bOP": KEY_F1, "\x1bOQ": KEY_F2, "\x1bOR": KEY_F3, "\x1bOS": KEY_F4,
+	"\x1b[1~": KEY_HOME, "\x1b[2~": KEY_IC, "\x1b[3~": KEY_DC,
+	"\x1b[4~": KEY_END, "\x1b[5~": KEY_PAGEUP, "\x1b[6~": KEY_PAGEDOWN,
+	"\x1b[Z": KEY_BTAB,
+}
+
+// TerminfoBackend is a Backend drawing on a terminal described by its
+// terminfo entry, such as the one the program is running in. It is only
+// available in a pure Go build
+type TerminfoBackend struct {
+	// EscDelay is how long to wait for the rest of an escape sequence
+	// after the escape key has been read. See SetEscDelay
+	EscDelay time.Duration
+
+	ti  *terminfo.Terminfo
+	out io.Writer
+	in  io.Reader
+	buf bytes.Buffer
+
+	// mu guards rows and cols, which are set by Resize
+	mu         sync.Mutex
+	rows, cols int
+
+	// front holds the cells shown on the terminal, back those set since.
+	// A cell of front with a negative character is unknown
+	front, back []Cell
+	bufRows     int
+	bufCols     int
+	pen         Cell
+	cursorY     int
+	cursorX     int
+	visibility  int
+	shownVis    int
+	clear       bool
+
+	keys     map[string]Key
+	prefixes map[string]bool
+	input    chan byte
+	pending  []byte
+	resized  chan struct{}
+	started  bool
+
+	tty *ttyState
+	raw bool
+}
+
+// NewTerminfoBackend returns a backend for a terminal of type termType,
+// such as "xterm-256color", which writes to out and reads keys from in. If
+// in is a terminal, it is put in cbreak mode by Init
+func NewTerminfoBackend(termType string, out io.Writer,
+	in io.Reader) (*TerminfoBackend, error) {
+	if termType == "" {
+		return nil, errors.New("TERM is not set")
+	}
+	ti, err := terminfo.Load(termType)
+	if err != nil {
+		return nil, err
+	}
+	t := &TerminfoBackend{EscDelay: time.Second, ti: ti, out: out, in: in,
+		visibility: 1, shownVis: -1, keys: make(map[string]Key),
+		prefixes: make(map[string]bool), input: make(chan byte, 256),
+		resized: make(chan struct{}, 1)}
+	for seq, k := range ansiKeys {
+		t.addKey(seq, k)
+	}
+	for c, k := range keyCaps {
+		if seq := ti.String(c); seq != "" {
+			t.addKey(seq, k)
+		}
+	}
+	if d, err := strconv.Atoi(os.Getenv("ESCDELAY")); err == nil {
+		t.EscDelay = time.Duration(d) * time.Millisecond
+	}
+	return t, nil
+}
+
+// addKey recognizes seq as key k
+func (t *TerminfoBackend) addKey(seq string, k Key) {
+	t.keys[seq] = k
+	for i := 1; i < len(seq); i++ {
+		t.prefixes[seq[:i]] = true
+	}
+}
+
+// put writes the capability c, expanded with params, if the terminal has it
+func (t *TerminfoBackend) put(c int, params ...int) bool {
+	s := t.ti.String(c)
+	if s == "" {
+		return false
+	}
+	t.buf.WriteString(terminfo.Expand(s, params...))
+	return true
+}
+
+// write writes the buffered output to the terminal
+func (t *TerminfoBackend) write() error {
+	_, err := t.out.Write(t.buf.Bytes())
+	t.buf.Reset()
+	return err
+}
+
+// Init prepares the terminal for use
+func (t *TerminfoBackend) Init() error {
+	if f, ok := t.in.(*os.File); ok && t.tty == nil {
+		tty, err := makeCbreak(f)
+		if err != nil {
+			return err
+		}
+		t.tty = tty
+		t.SetRaw(t.raw)
+	}
+	if !t.started {
+		t.started = true
+		go t.read()
+		notifyResize(t.resized)
+	}
+	t.put(terminfo.EnterCAMode)
+	t.put(terminfo.KeypadXmit)
+	t.setSize()
+	t.Clear()
+	return t.write()
+}
+
+// End returns the terminal to normal operation
+func (t *TerminfoBackend) End() error {
+	t.put(terminfo.ExitAttributeMode)
+	t.put(terminfo.CursorNormal)
+	t.put(terminfo.KeypadLocal)
+	t.put(terminfo.ExitCAMode)
+	t.pen = Cell{Fg: -1, Bg: -1}
+	t.shownVis = -1
+	err := t.write()
+	if t.tty != nil {
+		t.tty.restore()
+		t.tty = nil
+	}
+	return err
+}
+
+// read passes the bytes read from the terminal to the input channel
+func (t *TerminfoBackend) read() {
+	buf := make([]byte, 256)
+	for {
+		n, err := t.in.Read(buf)
+		for _, b := range buf[:n] {
+			t.input <- b
+		}
+		if err != nil {
+			close(t.input)
+			return
+		}
+	}
+}
+
+// Size returns the number of rows and columns of the terminal. It is the
+// size given to Resize, if it has been called, or else that of the
+// terminal, of LINES and COLUMNS or of the terminfo entry, whichever is
+// known first
+func (t *TerminfoBackend) Size() (rows, cols int) {
+	t.mu.Lock()
+	rows, cols = t.rows, t.cols
+	t.mu.Unlock()
+	if rows > 0 && cols > 0 {
+		return rows, cols
+	}
+	if f, ok := t.out.(*os.File); ok {
+		if rows, cols = windowSize(f); rows > 0 && cols > 0 {
+			return rows, cols
+		}
+	}
+	if useEnv {
+		rows, _ = strconv.Atoi(os.Getenv("LINES"))
+		cols, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+		if rows > 0 && cols > 0 {
+			return rows, cols
+		}
+	}
+	rows, cols = t.ti.Number(terminfo.Lines), t.ti.Number(terminfo.Columns)
+	if rows > 0 && cols > 0 {
+		return rows, cols
+	}
+	return 24, 80
+}
+
+// Resize sets the size of the terminal to rows by cols and queues
+// KEY_RESIZE. It is for terminals, such as those of a network connection,
+// whose size cannot be found any other way. It may be called from any
+// goroutine
+func (t *TerminfoBackend) Resize(rows, cols int) {
+	t.mu.Lock()
+	t.rows, t.cols = rows, cols
+	t.mu.Unlock()
+	select {
+	case t.resized <- struct{}{}:
+	default:
+	}
+}
+
+// setSize sizes front and back to the size of the terminal
+func (t *TerminfoBackend) setSize() {
+	rows, cols := t.Size()
+	if rows == t.bufRows && cols == t.bufCols {
+		return
+	}
+	t.bufRows, t.bufCols = rows, cols
+	t.front = make([]Cell, rows*cols)
+	t.back = make([]Cell, rows*cols)
+	for i := range t.back {
+		t.back[i] = Cell{Ch: ' ', Fg: -1, Bg: -1}
+	}
+	t.clear = true
+}
+
+// Colors returns the number of colors the terminal can display
+func (t *TerminfoBackend) Colors() int {
+	if n := t.ti.Number(terminfo.MaxColors); n > 0 {
+		return n
+	}
+	return 0
+}
+
+// SetCell sets the cell at y, x to c
+func (t *TerminfoBackend) SetCell(y, x int, c Cell) {
+	t.setSize()
+	if y >= 0 && x >= 0 && y < t.bufRows && x < t.bufCols {
+		t.back[y*t.bufCols+x] = c
+	}
+}
+
+// SetCursor moves the cursor to y, x and sets its visibility
+func (t *TerminfoBackend) SetCursor(y, x, visibility int) {
+	t.cursorY, t.cursorX, t.visibility = y, x, visibility
+}
+
+// Clear clears the terminal
+func (t *TerminfoBackend) Clear() {
+	t.clear = true
+}
+
+// setPen changes the attributes and colors of the characters written next
+// to those of c
+func (t *TerminfoBackend) setPen(c Cell) {
+	if c.Attr == t.pen.Attr && c.Fg == t.pen.Fg && c.Bg == t.pen.Bg {
+		return
+	}
+	t.put(terminfo.ExitAttributeMode)
+	t.put(terminfo.OrigPair)
+	for _, a := range attrCaps {
+		if c.Attr&a.attr != 0 {
+			t.put(a.cap)
+		}
+	}
+	if c.Fg >= 0 && !t.put(terminfo.SetAForeground, int(c.Fg)) {
+		t.put(terminfo.SetForeground, int(c.Fg))
+	}
+	if c.Bg >= 0 && !t.put(terminfo.SetABackground, int(c.Bg)) {
+		t.put(terminfo.SetBackground, int(c.Bg))
+	}
+	t.pen = c
+}
+
+// Flush writes the cells which differ from those on the terminal
+func (t *TerminfoBackend) Flush() error {
+	t.setSize()
+	if len(t.back) == 0 {
+		return t.write()
+	}
+	cols := t.bufCols
+	if t.clear {
+		t.setPen(Cell{Fg: -1, Bg: -1})
+		t.put(terminfo.ClearScreen)
+		for i := range t.front {
+			t.front[i] = Cell{Ch: ' ', Fg: -1, Bg: -1}
+		}
+		t.clear = false
+	}
+	y, x := -1, -1
+	for i := 0; i < len(t.back); i++ {
+		c := t.back[i]
+		if c == t.front[i] {
+			continue
+		}
+		if c.Ch == 0 && i%cols > 0 && runeWidth(t.back[i-1].Ch) == 2 {
+			// the right half of a wide character is written with the left
+			i--
+			c = t.back[i]
+		}
+		if i/cols != y || i%cols != x {
+			y, x = i/cols, i%cols
+			t.put(terminfo.CursorAddress, y, x)
+		}
+		t.setPen(c)
+		n := runeWidth(c.Ch)
+		if n == 2 && (i%cols+1 == cols || t.back[i+1].Ch != 0) {
+			n = 0
+		}
+		switch {
+		case c.Ch < ' ' || c.Ch == utf8.RuneError || n == 0:
+			t.buf.WriteByte(' ')
+			n = 1
+		default:
+			t.buf.WriteRune(c.Ch)
+			t.buf.WriteString(c.Comb)
+		}
+		copy(t.front[i:i+n], t.back[i:i+n])
+		i += n - 1
+		if x += n; x == cols {
+			y, x = -1, -1
+		}
+	}
+	t.setPen(Cell{Fg: -1, Bg: -1})
+	t.put(terminfo.CursorAddress, t.cursorY, t.cursorX)
+	if t.visibility != t.shownVis {
+		switch t.visibility {
+		case 0:
+			t.put(terminfo.CursorInvisible)
+		case 2:
+			t.put(terminfo.CursorNormal)
+			t.put(terminfo.CursorVisible)
+		default:
+			t.put(terminfo.CursorNormal)
+		}
+		t.shownVis = t.visibility
+	}
+	return t.write()
+}
+
+// next returns the next byte read from the terminal, waiting up to timeout
+// for it, or indefinitely if timeout is negative
+func (t *TerminfoBackend) next(timeout time.Duration) (byte, bool) {
+	if len(t.pending) > 0 {
+		b := t.pending[0]
+		t.pending = t.pending[1:]
+		return b, true
+	}
+	select {
+	case b, ok := <-t.input:
+		return b, ok
+	default:
+	}
+	if timeout == 0 {
+		return 0, false
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case b, ok := <-t.input:
+		return b, ok
+	case <-expired:
+		return 0, false
+	}
+}
+
+// ReadKey waits up to timeout for a key to be pressed. Bytes which do not
+// begin an escape sequence are returned one at a time
+func (t *TerminfoBackend) ReadKey(timeout time.Duration) (Key, bool) {
+	select {
+	case <-t.resized:
+		return KEY_RESIZE, true
+	default:
+	}
+	if len(t.pending) == 0 && timeout != 0 {
+		var expired <-chan time.Time
+		if timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			expired = timer.C
+		}
+		select {
+		case b, ok := <-t.input:
+			if !ok {
+				return 0, false
+			}
+			t.pending = append(t.pending, b)
+		case <-t.resized:
+			return KEY_RESIZE, true
+		case <-expired:
+			return 0, false
+		}
+	}
+	b, ok := t.next(0)
+	if !ok {
+		return 0, false
+	}
+	seq := []byte{b}
+	key, n := Key(b), 1
+	if k, ok := t.keys[string(seq)]; ok {
+		key = k
+	}
+	for t.prefixes[string(seq)] {
+		b, ok := t.next(t.EscDelay)
+		if !ok {
+			break
+		}
+		seq = append(seq, b)
+		if k, ok := t.keys[string(seq)]; ok {
+			key, n = k, len(seq)
+		}
+	}
+	t.pending = append(seq[n:], t.pending...)
+	return key, true
+}
+
+// SetRaw turns raw input on or off
+func (t *TerminfoBackend) SetRaw(on bool) {
+	t.raw = on
+	if t.tty != nil {
+		t.tty.setRaw(on)
+	}
+}
+
+// Beep sounds the terminal's bell
+func (t *TerminfoBackend) Beep() {
+	if t.put(terminfo.Bell) || t.put(terminfo.FlashScreen) {
+		t.write()
+	}
+}
+
+// Flash flashes the terminal's screen
+func (t *TerminfoBackend) Flash() {
+	if t.put(terminfo.FlashScreen) || t.put(terminfo.Bell) {
+		t.write()
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego,!linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package goncurses
+
+import "os"
+
+// ttyState is unused on systems whose terminals a pure Go build cannot
+// configure. Input is read as the terminal delivers it
+type ttyState struct{}
+
+func makeCbreak(f *os.File) (*ttyState, error) {
+	return nil, nil
+}
+
+func (s *ttyState) setRaw(on bool) {}
+
+func (s *ttyState) restore() {}
+
+func windowSize(f *os.File) (rows, cols int) {
+	return 0, 0
+}
+
+func notifyResize(c chan struct{}) {}
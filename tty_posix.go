@@ -0,0 +1,93 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package goncurses
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// ttyState is a terminal put in cbreak mode by a TerminfoBackend, with the
+// settings to restore when it is done with
+type ttyState struct {
+	fd    uintptr
+	saved syscall.Termios
+}
+
+func ioctl(fd, req uintptr, arg unsafe.Pointer) bool {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	return errno == 0
+}
+
+// makeCbreak puts f in cbreak mode, without echo or translation of
+// carriage returns. It returns nil if f is not a terminal
+func makeCbreak(f *os.File) (*ttyState, error) {
+	s := &ttyState{fd: f.Fd()}
+	if !ioctl(s.fd, ioctlGetTermios, unsafe.Pointer(&s.saved)) {
+		return nil, nil
+	}
+	t := s.saved
+	t.Iflag &^= syscall.ICRNL | syscall.INLCR | syscall.IGNCR
+	t.Lflag &^= syscall.ICANON | syscall.ECHO
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	if !ioctl(s.fd, ioctlSetTermios, unsafe.Pointer(&t)) {
+		return nil, os.NewSyscallError("ioctl", syscall.EINVAL)
+	}
+	return s, nil
+}
+
+// setRaw turns the generation of signals and flow control off or on
+func (s *ttyState) setRaw(on bool) {
+	var t syscall.Termios
+	if !ioctl(s.fd, ioctlGetTermios, unsafe.Pointer(&t)) {
+		return
+	}
+	if on {
+		t.Lflag &^= syscall.ISIG
+		t.Iflag &^= syscall.IXON
+	} else {
+		t.Lflag |= syscall.ISIG
+		t.Iflag |= s.saved.Iflag & syscall.IXON
+	}
+	ioctl(s.fd, ioctlSetTermios, unsafe.Pointer(&t))
+}
+
+// restore returns the terminal to its settings before makeCbreak
+func (s *ttyState) restore() {
+	ioctl(s.fd, ioctlSetTermios, unsafe.Pointer(&s.saved))
+}
+
+// windowSize returns the size of the terminal f, or zero if f is not a
+// terminal
+func windowSize(f *os.File) (rows, cols int) {
+	var ws struct {
+		Row, Col, X, Y uint16
+	}
+	if !ioctl(f.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)) {
+		return 0, 0
+	}
+	return int(ws.Row), int(ws.Col)
+}
+
+// notifyResize sends on c, without blocking, whenever the terminal changes
+// size
+func notifyResize(c chan struct{}) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	go func() {
+		for range sig {
+			select {
+			case c <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
@@ -0,0 +1,19 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goncurses
+
+import "github.com/rthornton128/goncurses/internal/width"
+
+// runeWidth returns the number of columns r occupies on a terminal: none
+// for combining marks and other characters drawn over the one before, two
+// for wide characters and one for the rest
+func runeWidth(r rune) int {
+	return width.Rune(r)
+}
+
+// stringWidth returns the number of columns s occupies on a terminal
+func stringWidth(s string) int {
+	return width.String(s)
+}
@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build !purego
+
 package goncurses
 
 // #include <stdlib.h>
@@ -17,11 +19,13 @@ import (
 
 type Window struct {
 	win *C.WINDOW
+	// screen is the Screen the window was created on, if known
+	screen *Screen
 }
 
 // NewWindow creates a window of size h(eight) and w(idth) at y, x
 func NewWindow(h, w, y, x int) (window *Window, err error) {
-	window = &Window{C.newwin(C.int(h), C.int(w), C.int(y), C.int(x))}
+	window = &Window{win: C.newwin(C.int(h), C.int(w), C.int(y), C.int(x))}
 	if window.win == nil {
 		err = errors.New("Failed to create a new window")
 	}
@@ -211,13 +215,13 @@ func (w *Window) Delete() error {
 // confining the derived window to the area of original window. See the
 // SubWindow function for additional notes.
 func (w *Window) Derived(height, width, y, x int) *Window {
-	return &Window{C.derwin(w.win, C.int(height), C.int(width), C.int(y),
-		C.int(x))}
+	return &Window{win: C.derwin(w.win, C.int(height), C.int(width),
+		C.int(y), C.int(x)), screen: w.screen}
 }
 
 // Duplicate the window, creating an exact copy.
 func (w *Window) Duplicate() *Window {
-	return &Window{C.dupwin(w.win)}
+	return &Window{win: C.dupwin(w.win), screen: w.screen}
 }
 
 // Test whether the given coordinates are within the window or not
@@ -370,7 +374,7 @@ func (w *Window) Parent() *Window {
 	if p == nil {
 		return nil
 	}
-	return &Window{p}
+	return &Window{win: p, screen: w.screen}
 }
 
 // Print a string to the given window. See the fmt package in the standard
@@ -446,8 +450,8 @@ func (w *Window) ScrollOk(ok bool) {
 // Touch() on this window prior to calling Refresh in order for it to be
 // displayed.
 func (w *Window) Sub(height, width, y, x int) *Window {
-	return &Window{C.subwin(w.win, C.int(height), C.int(width), C.int(y),
-		C.int(x))}
+	return &Window{win: C.subwin(w.win, C.int(height), C.int(width),
+		C.int(y), C.int(x)), screen: w.screen}
 }
 
 // Standend turns off Standout mode, which is equivalent AttrSet(A_NORMAL)
@@ -0,0 +1,1032 @@
+// Copyright 2011 Rob Thornton. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build purego
+
+package goncurses
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+type Window struct {
+	// lines holds the cells of the window. Those of a sub-window are
+	// slices of its parent's
+	lines   [][]cell
+	touched []bool
+	// begY and begX are the position of the window on the screen, parY
+	// and parX its position in its parent
+	begY, begX int
+	parY, parX int
+	parent     *Window
+	cury, curx int
+	attr       Char
+	bkgd       Char
+	delay      int
+	scroll     bool
+	clearOk    bool
+	keypad     bool
+	isPad      bool
+	// partial holds the leading bytes of a UTF-8 character added a byte
+	// at a time by AddChar
+	partial []byte
+	// padArgs are the arguments of the last refresh of a pad, used by
+	// Pad.Echo
+	padArgs []int
+}
+
+// newWindow returns a blank window of h by w at y, x
+func newWindow(h, w, y, x int) *Window {
+	win := &Window{begY: y, begX: x, bkgd: ' ', delay: -1}
+	win.lines = make([][]cell, h)
+	for i := range win.lines {
+		win.lines[i] = make([]cell, w)
+	}
+	win.touched = make([]bool, h)
+	win.Erase()
+	return win
+}
+
+// NewWindow creates a window of size h(eight) and w(idth) at y, x
+func NewWindow(h, w, y, x int) (*Window, error) {
+	if screen == nil {
+		return nil, errors.New("Failed to create a new window")
+	}
+	if h == 0 {
+		h = screen.rows - y
+	}
+	if w == 0 {
+		w = screen.cols - x
+	}
+	if y < 0 || x < 0 || h <= 0 || w <= 0 || y+h > screen.rows ||
+		x+w > screen.cols {
+		return nil, errors.New("Failed to create a new window")
+	}
+	return newWindow(h, w, y, x), nil
+}
+
+func (w *Window) rows() int {
+	return len(w.lines)
+}
+
+func (w *Window) cols() int {
+	return len(w.lines[0])
+}
+
+// blank returns the cell erased parts of the window are filled with
+func (w *Window) blank() cell {
+	r := rune(w.bkgd & A_CHARTEXT)
+	if r == 0 {
+		r = ' '
+	}
+	return cell{ch: r, attr: w.bkgd &^ A_CHARTEXT}
+}
+
+// renderBackground combines r and attr with the window's background, as
+// ncurses does for every character drawn
+func (w *Window) renderBackground(r rune, attr Char) cell {
+	if r == ' ' && attr&^colorMask == 0 {
+		r = w.blank().ch
+	}
+	attr |= w.bkgd &^ (A_CHARTEXT | colorMask)
+	if attr&colorMask == 0 {
+		attr |= w.bkgd & colorMask
+	}
+	return cell{ch: r, attr: attr}
+}
+
+// render combines r and attr with the window's attributes and background
+func (w *Window) render(r rune, attr Char) cell {
+	attr |= w.attr &^ colorMask
+	if attr&colorMask == 0 {
+		attr |= w.attr & colorMask
+	}
+	return w.renderBackground(r, attr)
+}
+
+// set sets the cell at y, x, if it is within the window. A wide character
+// partly overwritten is replaced by a blank
+func (w *Window) set(y, x int, c cell) {
+	if y < 0 || y >= w.rows() || x < 0 || x >= w.cols() {
+		return
+	}
+	line := w.lines[y]
+	if line[x].ch == 0 && c.ch != 0 && x > 0 {
+		line[x-1].ch, line[x-1].comb = ' ', ""
+	}
+	if line[x].ch != 0 && x+1 < len(line) && line[x+1].ch == 0 {
+		line[x+1].ch = ' '
+	}
+	line[x] = c
+	w.touched[y] = true
+}
+
+// move moves the cursor, returning false if y, x is outside the window
+func (w *Window) move(y, x int) bool {
+	if y < 0 || y >= w.rows() || x < 0 || x >= w.cols() {
+		return false
+	}
+	w.cury, w.curx = y, x
+	return true
+}
+
+// newline moves the cursor down a line, scrolling if it is on the last
+// line. It returns false if the window cannot be scrolled
+func (w *Window) newline() bool {
+	if w.cury+1 < w.rows() {
+		w.cury++
+		return true
+	}
+	if !w.scroll {
+		return false
+	}
+	w.scrollLines(1)
+	return true
+}
+
+// put places r at the cursor and advances it, wrapping at the end of the
+// line. A wide character takes two columns and, if only one is left on
+// the line, is put at the start of the next. A combining character is
+// added to the character before the cursor
+func (w *Window) put(r rune, attr Char) bool {
+	switch runeWidth(r) {
+	case 0:
+		w.combine(r)
+		return true
+	case 2:
+		if w.cols() < 2 {
+			r = ' '
+			break
+		}
+		if w.curx+1 == w.cols() && !w.put(' ', attr) {
+			return false
+		}
+		w.set(w.cury, w.curx, w.render(r, attr))
+		w.set(w.cury, w.curx+1, w.render(0, attr))
+		w.curx++
+		return w.advance()
+	}
+	w.set(w.cury, w.curx, w.render(r, attr))
+	return w.advance()
+}
+
+// advance moves the cursor on a column, wrapping at the end of the line
+func (w *Window) advance() bool {
+	if w.curx+1 < w.cols() {
+		w.curx++
+		return true
+	}
+	if !w.newline() {
+		return false
+	}
+	w.curx = 0
+	return true
+}
+
+// combine adds the combining character r to the character before the
+// cursor
+func (w *Window) combine(r rune) {
+	y, x := w.cury, w.curx-1
+	if x < 0 {
+		return
+	}
+	line := w.lines[y]
+	if line[x].ch == 0 && x > 0 {
+		x--
+	}
+	line[x].comb += string(r)
+	w.touched[y] = true
+}
+
+// addChar adds r to the window as waddch does, interpreting control
+// characters
+func (w *Window) addChar(r rune, attr Char) bool {
+	if attr&A_ALTCHARSET != 0 {
+		return w.put(r, attr)
+	}
+	switch r {
+	case '\n':
+		w.ClearToEOL()
+		w.curx = 0
+		return w.newline()
+	case '\r':
+		w.curx = 0
+		return true
+	case '\b':
+		if w.curx > 0 {
+			w.curx--
+		}
+		return true
+	case '\t':
+		for n := TabSize() - w.curx%TabSize(); n > 0; n-- {
+			if !w.put(' ', attr) {
+				return false
+			}
+			if w.curx == 0 {
+				break
+			}
+		}
+		return true
+	}
+	if r < ' ' || r == 0x7f {
+		// shown as ^X, as unctrl does
+		return w.put('^', attr) && w.put(r^0x40, attr)
+	}
+	return w.put(r, attr)
+}
+
+// addString adds s to the window, stopping if it runs out of room
+func (w *Window) addString(s string) {
+	for _, r := range s {
+		if !w.addChar(r, 0) {
+			return
+		}
+	}
+}
+
+// scrollLines scrolls the contents of the window up n lines, or down if n
+// is negative
+func (w *Window) scrollLines(n int) {
+	rows := w.rows()
+	blank := w.blank()
+	for i := 0; i < rows; i++ {
+		src := i + n
+		if n < 0 {
+			// work from the bottom up
+			i, src = rows-1-i, rows-1-i+n
+		}
+		if src >= 0 && src < rows {
+			copy(w.lines[i], w.lines[src])
+		} else {
+			for j := range w.lines[i] {
+				w.lines[i][j] = blank
+			}
+		}
+		w.touched[i] = true
+		if n < 0 {
+			i = rows - 1 - i
+		}
+	}
+}
+
+// AddChar prints a single character to the window. The character can be
+// OR'd together with attributes and colors.
+func (w *Window) AddChar(ach Char) {
+	r, attr := rune(ach&A_CHARTEXT), ach&^A_CHARTEXT
+	if r < utf8.RuneSelf || attr&A_ALTCHARSET != 0 {
+		w.partial = w.partial[:0]
+		w.addChar(r, attr)
+		return
+	}
+	// the bytes of a UTF-8 character are collected until it is complete
+	w.partial = append(w.partial, byte(r))
+	if utf8.FullRune(w.partial) {
+		r, _ = utf8.DecodeRune(w.partial)
+		w.partial = w.partial[:0]
+		w.addChar(r, attr)
+	}
+}
+
+// MoveAddChar prints a single character to the window at the specified
+// y x coordinates. See AddChar for more info.
+func (w *Window) MoveAddChar(y, x int, ach Char) {
+	if w.move(y, x) {
+		w.AddChar(ach)
+	}
+}
+
+// Turn off character attribute.
+func (w *Window) AttrOff(attr Char) (err error) {
+	if attr&colorMask != 0 {
+		w.attr &^= colorMask
+	}
+	w.attr &^= attr &^ colorMask
+	return nil
+}
+
+// Turn on character attribute
+func (w *Window) AttrOn(attr Char) (err error) {
+	if attr&colorMask != 0 {
+		w.attr = w.attr&^colorMask | attr&colorMask
+	}
+	w.attr |= attr &^ colorMask
+	return nil
+}
+
+// AttrSet sets the attributes to the given value
+func (w *Window) AttrSet(attr Char) error {
+	w.attr = attr &^ A_CHARTEXT
+	return nil
+}
+
+// SetBackground fills the background with the supplied attributes and/or
+// characters.
+func (w *Window) SetBackground(attr Char) {
+	old := w.blank()
+	if attr&A_CHARTEXT == 0 {
+		attr |= ' '
+	}
+	w.bkgd = attr
+	blank := w.blank()
+	for i, line := range w.lines {
+		for j, c := range line {
+			if c.ch == old.ch {
+				c.ch = blank.ch
+			}
+			if c.attr&colorMask == old.attr&colorMask {
+				c.attr = c.attr&^colorMask | blank.attr&colorMask
+			}
+			c.attr = c.attr&^(old.attr&^colorMask) | blank.attr&^colorMask
+			line[j] = c
+		}
+		w.touched[i] = true
+	}
+}
+
+// Background returns the current background attributes
+func (w *Window) Background() Char {
+	return w.bkgd
+}
+
+// Border uses the characters supplied to draw a border around the window.
+// t, b, r, l, s correspond to top, bottom, right, left and side respectively.
+func (w *Window) Border(ls, rs, ts, bs, tl, tr, bl, br Char) error {
+	def := func(ch, d Char) cell {
+		if ch&A_CHARTEXT == 0 {
+			ch |= d
+		}
+		return w.renderBackground(rune(ch&A_CHARTEXT), ch&^A_CHARTEXT)
+	}
+	rows, cols := w.rows(), w.cols()
+	for x := 1; x < cols-1; x++ {
+		w.set(0, x, def(ts, ACS_HLINE))
+		w.set(rows-1, x, def(bs, ACS_HLINE))
+	}
+	for y := 1; y < rows-1; y++ {
+		w.set(y, 0, def(ls, ACS_VLINE))
+		w.set(y, cols-1, def(rs, ACS_VLINE))
+	}
+	w.set(0, 0, def(tl, ACS_ULCORNER))
+	w.set(0, cols-1, def(tr, ACS_URCORNER))
+	w.set(rows-1, 0, def(bl, ACS_LLCORNER))
+	w.set(rows-1, cols-1, def(br, ACS_LRCORNER))
+	return nil
+}
+
+// Box draws a border around the given window. For complete control over the
+// characters used to draw the border use Border()
+func (w *Window) Box(vch, hch Char) error {
+	return w.Border(vch, vch, hch, hch, 0, 0, 0, 0)
+}
+
+// Clears the screen and the underlying virtual screen. This forces the entire
+// screen to be rewritten from scratch. This will cause likely cause a
+// noticeable flicker because the screen is completely cleared before
+// redrawing it. This is probably not what you want. Instead, you should
+// probably use the Erase() function. It is the same as called Erase() followed
+// by a call to ClearOk().
+func (w *Window) Clear() error {
+	w.Erase()
+	w.clearOk = true
+	return nil
+}
+
+// ClearOk clears the window completely prior to redrawing it. If called
+// on stdscr then the whole screen is redrawn no matter which window has
+// Refresh() called on it. Defaults to False.
+func (w *Window) ClearOk(ok bool) {
+	w.clearOk = ok
+}
+
+// ClearToBottom clears the window from the current cursor position to the
+// end of the window
+func (w *Window) ClearToBottom() error {
+	w.ClearToEOL()
+	blank := w.blank()
+	for y := w.cury + 1; y < w.rows(); y++ {
+		for x := range w.lines[y] {
+			w.lines[y][x] = blank
+		}
+		w.touched[y] = true
+	}
+	return nil
+}
+
+// ClearToEOL clears the current line from the cursor position to the end
+func (w *Window) ClearToEOL() error {
+	blank := w.blank()
+	line := w.lines[w.cury]
+	for x := w.curx; x < len(line); x++ {
+		line[x] = blank
+	}
+	w.touched[w.cury] = true
+	return nil
+}
+
+// Color sets the color pair for the window
+func (w *Window) Color(pair int16) {
+	w.attr = w.attr&^colorMask | ColorPair(pair)
+}
+
+// ColorOff turns the specified color pair off
+func (w *Window) ColorOff(pair int16) error {
+	w.attr &^= colorMask
+	return nil
+}
+
+// ColorOn turns on the specified color pair
+func (w *Window) ColorOn(pair int16) error {
+	w.Color(pair)
+	return nil
+}
+
+// Copy is similar to Overlay and Overwrite but provides a finer grain of
+// control.
+func (w *Window) Copy(src *Window, sy, sx, dtr, dtc, dbr, dbc int,
+	overlay bool) error {
+	if sy < 0 || sx < 0 || dtr < 0 || dtc < 0 || dbr < dtr || dbc < dtc ||
+		dbr >= w.rows() || dbc >= w.cols() ||
+		sy+dbr-dtr >= src.rows() || sx+dbc-dtc >= src.cols() {
+		return errors.New("Failed to copy window")
+	}
+	for y := dtr; y <= dbr; y++ {
+		for x := dtc; x <= dbc; x++ {
+			c := src.lines[sy+y-dtr][sx+x-dtc]
+			if overlay && c.ch == ' ' {
+				continue
+			}
+			w.lines[y][x] = c
+		}
+		w.touched[y] = true
+	}
+	return nil
+}
+
+// DelChar deletes the character at the current cursor position, moving all
+// characters to the right of that position one space to the left and appends
+// a blank character at the end.
+func (w *Window) DelChar() error {
+	line := w.lines[w.cury]
+	copy(line[w.curx:], line[w.curx+1:])
+	line[len(line)-1] = w.blank()
+	w.touched[w.cury] = true
+	return nil
+}
+
+// MoveDelChar deletes the character at the given cursor coordinates, moving all
+// characters to the right of that position one space to the left and appends
+// a blank character at the end.
+func (w *Window) MoveDelChar(y, x int) error {
+	if !w.move(y, x) {
+		return errors.New("An error occurred when trying to delete " +
+			"character")
+	}
+	return w.DelChar()
+}
+
+// Delete the window. This function must be called to ensure memory is freed
+// to prevent memory leaks once you are done with the window.
+func (w *Window) Delete() error {
+	if w == stdscr {
+		return errors.New("Failed to delete window")
+	}
+	return nil
+}
+
+// derive creates a window of height by width at y, x in w sharing its
+// cells
+func (w *Window) derive(height, width, y, x int) *Window {
+	if height == 0 {
+		height = w.rows() - y
+	}
+	if width == 0 {
+		width = w.cols() - x
+	}
+	if y < 0 || x < 0 || height <= 0 || width <= 0 || y+height > w.rows() ||
+		x+width > w.cols() {
+		return nil
+	}
+	sub := &Window{begY: w.begY + y, begX: w.begX + x, parY: y, parX: x,
+		parent: w, attr: w.attr, bkgd: w.bkgd, delay: -1, isPad: w.isPad}
+	sub.lines = make([][]cell, height)
+	for i := range sub.lines {
+		sub.lines[i] = w.lines[y+i][x : x+width : x+width]
+	}
+	sub.touched = make([]bool, height)
+	sub.Touch()
+	return sub
+}
+
+// Derived creates a new window of height and width at the coordinates
+// y, x.  These coordinates are relative to the original window thereby
+// confining the derived window to the area of original window. See the
+// SubWindow function for additional notes.
+func (w *Window) Derived(height, width, y, x int) *Window {
+	return w.derive(height, width, y, x)
+}
+
+// Duplicate the window, creating an exact copy.
+func (w *Window) Duplicate() *Window {
+	dup := *w
+	dup.parent = nil
+	dup.partial = nil
+	dup.lines = make([][]cell, w.rows())
+	for i, line := range w.lines {
+		dup.lines[i] = append([]cell(nil), line...)
+	}
+	dup.touched = append([]bool(nil), w.touched...)
+	return &dup
+}
+
+// Test whether the given coordinates are within the window or not
+func (w *Window) Enclose(y, x int) bool {
+	return y >= w.begY && y < w.begY+w.rows() && x >= w.begX &&
+		x < w.begX+w.cols()
+}
+
+// Erase the contents of the window, clearing it. This function allows the
+// underlying structures to be updated efficiently and thereby provide smooth
+// updates to the terminal when frequently clearing and re-writing the window
+// or screen.
+func (w *Window) Erase() {
+	blank := w.blank()
+	for i, line := range w.lines {
+		for j := range line {
+			line[j] = blank
+		}
+		w.touched[i] = true
+	}
+	w.cury, w.curx = 0, 0
+}
+
+// readKey refreshes the window if it has changed and waits for a key
+func (w *Window) readKey() (Key, bool) {
+	if screen == nil {
+		return 0, false
+	}
+	if !w.isPad && (w.Touched() || screen.cursorY != w.begY+w.cury ||
+		screen.cursorX != w.begX+w.curx) {
+		w.Refresh()
+	}
+	return screen.readKey(w.delay)
+}
+
+// GetChar retrieves a character from standard input stream and returns it.
+// In the event of an error or if the input timeout has expired (i.e. if
+// Timeout() has been set to zero or a positive value and no characters have
+// been received) the value returned will be zero (0)
+func (w *Window) GetChar() Key {
+	k, ok := w.readKey()
+	if !ok {
+		return 0
+	}
+	if screen.echo && k >= ' ' && k < 0x7f {
+		w.AddChar(Char(k))
+		w.Refresh()
+	}
+	return k
+}
+
+// MoveGetChar moves the cursor to the given position and gets a character
+// from the input stream
+func (w *Window) MoveGetChar(y, x int) Key {
+	w.move(y, x)
+	return w.GetChar()
+}
+
+// GetString reads at most 'n' characters entered by the user from the Window.
+// Attempts to enter greater than 'n' characters will elicit a 'beep'
+func (w *Window) GetString(n int) (string, error) {
+	var s []rune
+	var pending []byte
+	for {
+		k, ok := w.readKey()
+		if !ok {
+			return "", errors.New("Failed to retrieve string from input " +
+				"stream")
+		}
+		switch {
+		case k == '\n' || k == '\r' || k == KEY_ENTER:
+			return string(s), nil
+		case k == KEY_BACKSPACE || k == KEY_LEFT || k == 0x7f || k == '\b':
+			if len(s) == 0 {
+				break
+			}
+			s = s[:len(s)-1]
+			if screen.echo {
+				// step back over the character and rub it out
+				w.addChar('\b', 0)
+				w.put(' ', 0)
+				w.addChar('\b', 0)
+				w.Refresh()
+			}
+		case k < ' ' || k > 0xff:
+		case len(s) >= n:
+			Beep()
+		default:
+			// characters arrive a byte at a time
+			pending = append(pending, byte(k))
+			if !utf8.FullRune(pending) {
+				break
+			}
+			r, _ := utf8.DecodeRune(pending)
+			pending = pending[:0]
+			s = append(s, r)
+			if screen.echo {
+				w.addChar(r, 0)
+				w.Refresh()
+			}
+		}
+	}
+}
+
+// CursorYX returns the current cursor location in the Window. Note that it
+// uses ncurses idiom of returning y then x.
+func (w *Window) CursorYX() (int, int) {
+	return w.cury, w.curx
+}
+
+// HLine draws a horizontal line starting at y, x and ending at width using
+// the specified character
+func (w *Window) HLine(y, x int, ch Char, wid int) {
+	if !w.move(y, x) {
+		return
+	}
+	if ch&A_CHARTEXT == 0 {
+		ch |= ACS_HLINE
+	}
+	c := w.renderBackground(rune(ch&A_CHARTEXT), ch&^A_CHARTEXT)
+	for i := 0; i < wid && x+i < w.cols(); i++ {
+		w.set(y, x+i, c)
+	}
+}
+
+// InChar returns the character at the current position in the curses window
+func (w *Window) InChar() Char {
+	c := w.lines[w.cury][w.curx]
+	return Char(c.ch)&A_CHARTEXT | c.attr
+}
+
+// MoveInChar returns the character at the designated coordates in the curses
+// window
+func (w *Window) MoveInChar(y, x int) Char {
+	if !w.move(y, x) {
+		return 0
+	}
+	return w.InChar()
+}
+
+// IsCleared returns the value set in ClearOk
+func (w *Window) IsCleared() bool {
+	return w.clearOk
+}
+
+// IsKeypad returns the value set in Keypad
+func (w *Window) IsKeypad() bool {
+	return w.keypad
+}
+
+// Keypad turns on/off the keypad characters, including those like the F1-F12
+// keys and the arrow keys. Function keys are always recognized by a pure Go
+// build so this only records the setting
+func (w *Window) Keypad(keypad bool) error {
+	w.keypad = keypad
+	return nil
+}
+
+// LineTouched returns true if the line has been touched; returns false
+// otherwise
+func (w *Window) LineTouched(line int) bool {
+	return line >= 0 && line < w.rows() && w.touched[line]
+}
+
+// Returns the maximum size of the Window. Note that it uses ncurses idiom
+// of returning y then x.
+func (w *Window) MaxYX() (int, int) {
+	return w.rows(), w.cols()
+}
+
+// Move the cursor to the specified coordinates within the window
+func (w *Window) Move(y, x int) {
+	w.move(y, x)
+}
+
+// MoveWindow moves the location of the window to the specified coordinates
+func (w *Window) MoveWindow(y, x int) {
+	if w.isPad || screen == nil || y < 0 || x < 0 ||
+		y+w.rows() > screen.rows || x+w.cols() > screen.cols {
+		return
+	}
+	w.begY, w.begX = y, x
+	w.Touch()
+}
+
+// NoutRefresh, or No Output Refresh, flags the window for redrawing but does
+// not output the changes to the terminal (screen). Essentially, the output is
+// buffered and a call to Update() flushes the buffer to the terminal. This
+// function provides a speed increase over calling Refresh() when multiple
+// windows are involved because only the final output is
+// transmitted to the terminal.
+func (w *Window) NoutRefresh() {
+	if screen == nil || w.isPad {
+		return
+	}
+	w.copyTo(0, 0, w.begY, w.begX, w.rows(), w.cols(), true)
+}
+
+// copyTo copies the rows by cols of the window at y, x to the virtual
+// screen at sy, sx and leaves the cursor there. If touched is true only
+// touched lines are copied
+func (w *Window) copyTo(y, x, sy, sx, rows, cols int, touched bool) {
+	s := screen
+	if w.clearOk {
+		s.clear = true
+		w.clearOk = false
+	}
+	for i := 0; i < rows; i++ {
+		if touched && !w.touched[y+i] {
+			continue
+		}
+		w.touched[y+i] = false
+		if sy+i < 0 || sy+i >= s.rows {
+			continue
+		}
+		for j := 0; j < cols; j++ {
+			if sx+j >= 0 && sx+j < s.cols {
+				s.cells[(sy+i)*s.cols+sx+j] = w.lines[y+i][x+j]
+			}
+		}
+	}
+	if cy, cx := sy+w.cury-y, sx+w.curx-x; cy >= 0 && cy < s.rows &&
+		cx >= 0 && cx < s.cols {
+		s.cursorY, s.cursorX = cy, cx
+	}
+}
+
+// Overlay copies overlapping sections of src window onto the destination
+// window. Non-blank elements are not overwritten.
+func (w *Window) Overlay(src *Window) error {
+	if !w.overlap(src, true) {
+		return errors.New("Failed to overlay window")
+	}
+	return nil
+}
+
+// Overwrite copies overlapping sections of src window onto the destination
+// window. This function is considered "destructive" by copying all
+// elements of src onto the destination window.
+func (w *Window) Overwrite(src *Window) error {
+	if !w.overlap(src, false) {
+		return errors.New("Failed to overwrite window")
+	}
+	return nil
+}
+
+// overlap copies the part of src which overlaps w on the screen to w
+func (w *Window) overlap(src *Window, overlay bool) bool {
+	top, left := max(w.begY, src.begY), max(w.begX, src.begX)
+	bottom := min(w.begY+w.rows(), src.begY+src.rows()) - 1
+	right := min(w.begX+w.cols(), src.begX+src.cols()) - 1
+	if bottom < top || right < left {
+		return false
+	}
+	return w.Copy(src, top-src.begY, left-src.begX, top-w.begY,
+		left-w.begX, bottom-w.begY, right-w.begX, overlay) == nil
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Parent returns a pointer to a Sub-window's parent, or nil if the window
+// has no parent
+func (w *Window) Parent() *Window {
+	return w.parent
+}
+
+// Print a string to the given window. See the fmt package in the standard
+// library for more information. In order to simulate the 'n' version
+// of functions (like addnstr) just slice your string to the maximum
+// length before passing it as an argument.
+// window.Print("My line which should be clamped to 20 characters"[:20])
+func (w *Window) Print(args ...interface{}) {
+	w.addString(fmt.Sprint(args...))
+}
+
+// Printf functions the same as the standard library's fmt package. See Print
+// for more details.
+func (w *Window) Printf(format string, args ...interface{}) {
+	w.addString(fmt.Sprintf(format, args...))
+}
+
+// Println behaves the same as the standard library's fmt package.
+// See Print for more information.
+func (w *Window) Println(args ...interface{}) {
+	w.addString(fmt.Sprintln(args...))
+}
+
+// MovePrint moves the cursor to the specified coordinates and prints the
+// supplied message. See Print for more details.The first two arguments are the
+// coordinates to print to.
+func (w *Window) MovePrint(y, x int, args ...interface{}) {
+	if w.move(y, x) {
+		w.Print(args...)
+	}
+}
+
+// MovePrintf moves the cursor to coordinates and prints the message using
+// the specified format. See Printf and MovePrint for more information.
+func (w *Window) MovePrintf(y, x int, format string, args ...interface{}) {
+	if w.move(y, x) {
+		w.Printf(format, args...)
+	}
+}
+
+// MovePrintln moves the cursor to coordinates and prints the message. See
+// Println and MovePrint for more details.
+func (w *Window) MovePrintln(y, x int, args ...interface{}) {
+	if w.move(y, x) {
+		w.Println(args...)
+	}
+}
+
+// Refresh the window so it's contents will be displayed
+func (w *Window) Refresh() {
+	w.NoutRefresh()
+	Update()
+}
+
+// Resize the window to new height, width
+func (w *Window) Resize(height, width int) {
+	if height <= 0 || width <= 0 {
+		return
+	}
+	blank := w.blank()
+	lines := make([][]cell, height)
+	for i := range lines {
+		if w.parent != nil {
+			// a sub-window can only grow within its parent
+			p := w.parent
+			if w.parY+i >= p.rows() || w.parX+width > p.cols() {
+				return
+			}
+			end := w.parX + width
+			lines[i] = p.lines[w.parY+i][w.parX:end:end]
+			continue
+		}
+		lines[i] = make([]cell, width)
+		for j := range lines[i] {
+			if i < w.rows() && j < w.cols() {
+				lines[i][j] = w.lines[i][j]
+			} else {
+				lines[i][j] = blank
+			}
+		}
+	}
+	w.lines = lines
+	w.touched = make([]bool, height)
+	w.Touch()
+	w.cury, w.curx = min(w.cury, height-1), min(w.curx, width-1)
+}
+
+// Scroll the contents of the window. Use a negative number to scroll up,
+// a positive number to scroll down. ScrollOk Must have been called prior.
+func (w *Window) Scroll(n int) {
+	if w.scroll {
+		w.scrollLines(n)
+	}
+}
+
+// ScrollOk sets whether scrolling will work
+func (w *Window) ScrollOk(ok bool) {
+	w.scroll = ok
+}
+
+// SubWindow creates a new window of height and width at the coordinates
+// y, x.  This window shares memory with the original window so changes
+// made to one window are reflected in the other. It is necessary to call
+// Touch() on this window prior to calling Refresh in order for it to be
+// displayed.
+func (w *Window) Sub(height, width, y, x int) *Window {
+	return w.derive(height, width, y-w.begY, x-w.begX)
+}
+
+// Standend turns off Standout mode, which is equivalent AttrSet(A_NORMAL)
+func (w *Window) Standend() error {
+	return w.AttrSet(A_NORMAL)
+}
+
+// Standout is equivalent to AttrSet(A_STANDOUT)
+func (w *Window) Standout() error {
+	return w.AttrSet(A_STANDOUT)
+}
+
+// Sync updates all parent or child windows which were created via
+// SubWindow() or DerivedWindow(). Argument can be one of: SYNC_DOWN, which
+// syncronizes all parent windows (done by Refresh() by default so should
+// rarely, if ever, need to be called); SYNC_UP, which updates all child
+// windows to match any updates made to the parent; and, SYNC_CURSOR, which
+// updates the cursor position only for all windows to match the parent window
+func (w *Window) Sync(sync int) {
+	switch sync {
+	case SYNC_DOWN:
+		for p, y := w.parent, w.parY; p != nil; y, p = y+p.parY, p.parent {
+			for i := range w.touched {
+				if p.touched[y+i] {
+					w.touched[i] = true
+				}
+			}
+		}
+	case SYNC_CURSOR:
+		for c, p := w, w.parent; p != nil; c, p = p, p.parent {
+			p.cury, p.curx = c.parY+c.cury, c.parX+c.curx
+		}
+	case SYNC_UP:
+		for p, y := w.parent, w.parY; p != nil; y, p = y+p.parY, p.parent {
+			for i := range w.touched {
+				if w.touched[i] {
+					p.touched[y+i] = true
+				}
+			}
+		}
+	}
+}
+
+// Timeout sets the window to blocking or non-blocking read mode. Calls to
+// GetCh will behave in the following manor depending on the value of delay:
+// <= -1 - blocking mode is set (blocks indefinitely)
+// ==  0 - non-blocking; returns zero (0)
+// >=  1 - blocks for delay in milliseconds; returns zero (0)
+func (w *Window) Timeout(delay int) {
+	w.delay = delay
+}
+
+// Touch indicates that the window contains changes which should be updated
+// on the next call to Refresh
+func (w *Window) Touch() error {
+	return w.TouchLine(0, w.rows())
+}
+
+// Touched returns true if window will be updated on the next Refresh
+func (w *Window) Touched() bool {
+	for _, t := range w.touched {
+		if t {
+			return true
+		}
+	}
+	return false
+}
+
+// Touchline behaves like Touch but only effects count number of lines,
+// beginning at start
+func (w *Window) TouchLine(start, count int) error {
+	if start < 0 || start > w.rows() || count < 0 {
+		return errors.New("Error in call to TouchLine")
+	}
+	for i := start; i < start+count && i < w.rows(); i++ {
+		w.touched[i] = true
+	}
+	return nil
+}
+
+// UnTouch indicates the window should not be updated on the next call to
+// Refresh
+func (w *Window) UnTouch() {
+	for i := range w.touched {
+		w.touched[i] = false
+	}
+}
+
+// VLine draws a vertical line starting at y, x and ending at height using
+// the specified character
+func (w *Window) VLine(y, x int, ch Char, wid int) {
+	if !w.move(y, x) {
+		return
+	}
+	if ch&A_CHARTEXT == 0 {
+		ch |= ACS_VLINE
+	}
+	c := w.renderBackground(rune(ch&A_CHARTEXT), ch&^A_CHARTEXT)
+	for i := 0; i < wid && y+i < w.rows(); i++ {
+		w.set(y+i, x, c)
+	}
+}
+
+// YX returns the current coordinates of the Window. Note that it uses
+// ncurses idiom of returning y then x.
+func (w *Window) YX() (int, int) {
+	return w.begY, w.begX
+}